@@ -51,14 +51,17 @@ var (
 	defaultAPIListen          = "127.0.0.1:7777"
 	defaultIndentJSON         = "   "
 	defaultCacheControlMaxAge = 86400
+	defaultMaxTxFee           = 1.0
 
 	defaultMonitorMempool     = true
 	defaultMempoolMinInterval = 2
 	defaultMempoolMaxInterval = 120
 	defaultMPTriggerTickets   = 1
 
-	defaultDBFileName      = "dcrdata.sqlt.db"
-	defaultAgendDBFileName = "agendas.db"
+	defaultDBFileName          = "dcrdata.sqlt.db"
+	defaultAgendDBFileName     = "agendas.db"
+	defaultSyncPrefetchWorkers = 10
+	defaultHashrateWindow      = 120
 
 	defaultPGHost                       = "127.0.0.1:5432"
 	defaultPGUser                       = "dcrdata"
@@ -85,11 +88,12 @@ type config struct {
 	UseGops      bool   `short:"g" long:"gops" description:"Run with gops diagnostics agent listening. See github.com/google/gops for more information." env:"DCRDATA_USE_GOPS"`
 
 	// API
-	APIProto           string `long:"apiproto" description:"Protocol for API (http or https)" env:"DCRDATA_ENABLE_HTTPS"`
-	APIListen          string `long:"apilisten" description:"Listen address for API" env:"DCRDATA_LISTEN_URL"`
-	IndentJSON         string `long:"indentjson" description:"String for JSON indentation (default is \"   \"), when indentation is requested via URL query."`
-	UseRealIP          bool   `long:"userealip" description:"Use the RealIP middleware from the pressly/chi/middleware package to get the client's real IP from the X-Forwarded-For or X-Real-IP headers, in that order." env:"DCRDATA_USE_REAL_IP"`
-	CacheControlMaxAge int    `long:"cachecontrol-maxage" description:"Set CacheControl in the HTTP response header to a value in seconds for clients to cache the response. This applies only to FileServer routes." env:"DCRDATA_MAX_CACHE_AGE"`
+	APIProto           string  `long:"apiproto" description:"Protocol for API (http or https)" env:"DCRDATA_ENABLE_HTTPS"`
+	APIListen          string  `long:"apilisten" description:"Listen address for API" env:"DCRDATA_LISTEN_URL"`
+	IndentJSON         string  `long:"indentjson" description:"String for JSON indentation (default is \"   \"), when indentation is requested via URL query."`
+	UseRealIP          bool    `long:"userealip" description:"Use the RealIP middleware from the pressly/chi/middleware package to get the client's real IP from the X-Forwarded-For or X-Real-IP headers, in that order." env:"DCRDATA_USE_REAL_IP"`
+	CacheControlMaxAge int     `long:"cachecontrol-maxage" description:"Set CacheControl in the HTTP response header to a value in seconds for clients to cache the response. This applies only to FileServer routes." env:"DCRDATA_MAX_CACHE_AGE"`
+	MaxTxFee           float64 `long:"maxtxfee" description:"Maximum fee in DCR of a transaction broadcast with the Insight API, unless the allowHighFees query parameter is set." env:"DCRDATA_MAX_TX_FEE"`
 
 	// Data I/O
 	MonitorMempool     bool   `short:"m" long:"mempool" description:"Monitor mempool for new transactions, and report ticketfee info when new tickets are added." env:"DCRDATA_ENABLE_MEMPOOL_MONITOR"`
@@ -107,10 +111,14 @@ type config struct {
 	PGHost         string        `long:"pghost" description:"PostgreSQL server host:port or UNIX socket (e.g. /run/postgresql)." env:"DCRDATA_POSTGRES_HOST_URL"`
 	PGQueryTimeout time.Duration `short:"T" long:"pgtimeout" description:"Timeout (a time.Duration string) for most PostgreSQL queries used for user initiated queries."`
 
+	HashrateWindow int `long:"hashrate-window" description:"Number of blocks over which the network hashrate chart is averaged. Must be at least 1." env:"DCRDATA_HASHRATE_WINDOW"`
+
 	NoDevPrefetch    bool `long:"no-dev-prefetch" description:"Disable automatic dev fund balance query on new blocks. When true, the query will still be run on demand, but not automatically after new blocks are connected." env:"DCRDATA_DISABLE_DEV_PREFETCH"`
 	SyncAndQuit      bool `long:"sync-and-quit" description:"Sync to the best block and exit. Do not start the explorer or API." env:"DCRDATA_ENABLE_SYNC_N_QUIT"`
 	ImportSideChains bool `long:"import-side-chains" description:"(experimental) Enable startup import of side chains retrieved from dcrd via getchaintips." env:"DCRDATA_IMPORT_SIDE_CHAINS"`
 
+	SyncPrefetchWorkers int `long:"sync-prefetch-workers" description:"Number of blocks fetched concurrently from dcrd ahead of the block being processed when syncing the SQLite DB. Must be at least 1." env:"DCRDATA_SYNC_PREFETCH_WORKERS"`
+
 	SyncStatusLimit int64 `long:"sync-status-limit" description:"Sets the number of blocks behind the current best height past which only the syncing status page can be served on the running web server. Value should be greater than 2 but less than 5000."`
 
 	// WatchAddresses []string `short:"w" long:"watchaddress" description:"Watched address (receiving). One per line."`
@@ -130,28 +138,31 @@ type config struct {
 
 var (
 	defaultConfig = config{
-		HomeDir:            defaultHomeDir,
-		DataDir:            defaultDataDir,
-		LogDir:             defaultLogDir,
-		ConfigFile:         defaultConfigFile,
-		DBFileName:         defaultDBFileName,
-		AgendaDBFileName:   defaultAgendDBFileName,
-		DebugLevel:         defaultLogLevel,
-		HTTPProfPath:       defaultHTTPProfPath,
-		APIProto:           defaultAPIProto,
-		APIListen:          defaultAPIListen,
-		IndentJSON:         defaultIndentJSON,
-		CacheControlMaxAge: defaultCacheControlMaxAge,
-		DcrdCert:           defaultDaemonRPCCertFile,
-		MonitorMempool:     defaultMonitorMempool,
-		MempoolMinInterval: defaultMempoolMinInterval,
-		MempoolMaxInterval: defaultMempoolMaxInterval,
-		MPTriggerTickets:   defaultMPTriggerTickets,
-		PGDBName:           defaultPGDBName,
-		PGUser:             defaultPGUser,
-		PGPass:             defaultPGPass,
-		PGHost:             defaultPGHost,
-		PGQueryTimeout:     defaultPGQueryTimeout,
+		HomeDir:             defaultHomeDir,
+		DataDir:             defaultDataDir,
+		LogDir:              defaultLogDir,
+		ConfigFile:          defaultConfigFile,
+		DBFileName:          defaultDBFileName,
+		AgendaDBFileName:    defaultAgendDBFileName,
+		DebugLevel:          defaultLogLevel,
+		HTTPProfPath:        defaultHTTPProfPath,
+		APIProto:            defaultAPIProto,
+		APIListen:           defaultAPIListen,
+		IndentJSON:          defaultIndentJSON,
+		CacheControlMaxAge:  defaultCacheControlMaxAge,
+		MaxTxFee:            defaultMaxTxFee,
+		DcrdCert:            defaultDaemonRPCCertFile,
+		MonitorMempool:      defaultMonitorMempool,
+		MempoolMinInterval:  defaultMempoolMinInterval,
+		MempoolMaxInterval:  defaultMempoolMaxInterval,
+		MPTriggerTickets:    defaultMPTriggerTickets,
+		PGDBName:            defaultPGDBName,
+		PGUser:              defaultPGUser,
+		PGPass:              defaultPGPass,
+		PGHost:              defaultPGHost,
+		PGQueryTimeout:      defaultPGQueryTimeout,
+		SyncPrefetchWorkers: defaultSyncPrefetchWorkers,
+		HashrateWindow:      defaultHashrateWindow,
 	}
 )
 
@@ -488,6 +499,18 @@ func loadConfig() (*config, error) {
 		log.Warnf("%v. Disabling balance prefetch (--no-dev-prefetch).", err)
 	}
 
+	if cfg.SyncPrefetchWorkers < 1 {
+		return nil, fmt.Errorf("sync-prefetch-workers must be at least 1")
+	}
+
+	if cfg.HashrateWindow < 1 {
+		return nil, fmt.Errorf("hashrate-window must be at least 1")
+	}
+
+	if cfg.MaxTxFee < 0 {
+		return nil, fmt.Errorf("maxtxfee must not be negative")
+	}
+
 	// Check if sync-status-limit value has been set. If its equal to zero then
 	// it hasn't been set.
 	if cfg.SyncStatusLimit != 0 {
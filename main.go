@@ -22,6 +22,7 @@ import (
 	"time"
 
 	"github.com/decred/dcrd/chaincfg/chainhash"
+	"github.com/decred/dcrd/dcrutil"
 	"github.com/decred/dcrd/rpcclient"
 	"github.com/decred/hcData/v4/api"
 	"github.com/decred/hcData/v4/api/insight"
@@ -152,6 +153,7 @@ func _main(ctx context.Context) error {
 	}
 	log.Infof("SQLite DB successfully opened: %s", cfg.DBFileName)
 	defer baseDB.Close()
+	baseDB.SetBlockPrefetchWorkers(cfg.SyncPrefetchWorkers)
 
 	// Auxiliary DB (currently PostgreSQL)
 	var auxDB *dcrpg.ChainDBRPC
@@ -183,6 +185,9 @@ func _main(ctx context.Context) error {
 		if err != nil {
 			return err
 		}
+		if err = chainDB.SetHashrateWindow(cfg.HashrateWindow); err != nil {
+			return err
+		}
 
 		auxDB, err = dcrpg.NewChainDBRPC(chainDB, dcrdClient)
 		if err != nil {
@@ -454,6 +459,7 @@ func _main(ctx context.Context) error {
 	// the sync starts, this implementation cannot be moved to
 	// initiateHandlersAndCollectBlocks function.
 	var insightSocketServer *insight.SocketServer
+	var insightWsHub *insight.WebsocketHub
 	if usePG {
 		insightSocketServer, err = insight.NewSocketServer(notify.NtfnChans.InsightNewTxChan, activeChain)
 		if err == nil {
@@ -461,6 +467,13 @@ func _main(ctx context.Context) error {
 		} else {
 			return fmt.Errorf("Could not create Insight socket.io server: %v", err)
 		}
+
+		// The Insight websocket hub signals new blocks to its clients, and
+		// checks subscribed addresses for new unconfirmed transactions.
+		insightWsHub = insight.NewWebsocketHub(&baseDB,
+			notify.NtfnChans.InsightWsNewTxChan, activeChain)
+		defer insightWsHub.Stop()
+		blockDataSavers = append(blockDataSavers, insightWsHub)
 	}
 
 	// WaitGroup for the monitor goroutines
@@ -486,6 +499,8 @@ func _main(ctx context.Context) error {
 		r.Get("/nexthome", explore.NextHome)
 	})
 	webMux.Get("/ws", explore.RootWebsocket)
+	// The sync status is reported even while the sync status page is active.
+	webMux.Get("/syncstatus", explore.ExplorerSyncStatus)
 	webMux.Get("/favicon.ico", func(w http.ResponseWriter, r *http.Request) {
 		http.ServeFile(w, r, "./public/images/favicon.ico")
 	})
@@ -500,15 +515,26 @@ func _main(ctx context.Context) error {
 	webMux.With(explore.SyncStatusApiResponse).Group(func(r chi.Router) {
 		// Mount the dcrdata's REST API.
 		r.Mount("/api", apiMux.Mux)
+		// Mempool fee rate histogram (JSON).
+		r.Get("/mempool/feerates", explore.MempoolFeeRates)
+		// Mempool transaction count, size and fees (JSON).
+		r.Get("/mempool/counts", explore.MempoolCounts)
 		// Setup and mount the Insight API.
 		if usePG {
-			insightApp := insight.NewInsightContext(dcrdClient, auxDB, activeChain, &baseDB, cfg.IndentJSON)
+			maxTxFee, _ := dcrutil.NewAmount(cfg.MaxTxFee)
+			insightApp := insight.NewInsightContext(dcrdClient, auxDB, activeChain,
+				&baseDB, cfg.IndentJSON, maxTxFee)
+			// Keep the Insight API's cached node status current.
+			blockDataSavers = append(blockDataSavers, insightApp)
 			insightMux := insight.NewInsightApiRouter(insightApp, cfg.UseRealIP)
 			r.Mount("/insight/api", insightMux.Mux)
 
 			if insightSocketServer != nil {
 				r.Get("/insight/socket.io/", insightSocketServer.ServeHTTP)
 			}
+			if insightWsHub != nil {
+				r.Get("/insight/ws", insightWsHub.WebsocketHandler)
+			}
 		}
 	})
 
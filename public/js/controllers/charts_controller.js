@@ -100,6 +100,18 @@ function txPerDayFunc (gData) {
   return map(gData.timestr, (n, i) => { return [new Date(n), gData.count[i]] })
 }
 
+function rollingAvgTicketPriceFunc (gData) {
+  return map(gData.height, (n, i) => { return [n, gData.valuef[i]] })
+}
+
+function feesPerDayFunc (gData) {
+  return map(gData.time, (n, i) => { return [new Date(n), gData.valuef[i]] })
+}
+
+function feeRatePerDayFunc (gData) {
+  return map(gData.time, (n, i) => { return [new Date(n), gData.valuef[i]] })
+}
+
 function poolSizeFunc (gData) {
   return map(gData.time, (n, i) => { return [new Date(n), gData.sizef[i]] })
 }
@@ -226,6 +238,12 @@ export default class extends Controller {
         assign(gOptions, mapDygraphOptions(d, ['Date', 'Ticket Price'], true, 'Price (DCR)', 'Date', undefined, false, false))
         break
 
+      case 'ticket-price-rolling-avg': // rolling average ticket price graph
+        d = rollingAvgTicketPriceFunc(data)
+        assign(gOptions, mapDygraphOptions(d, ['Block Height', 'Average Ticket Price'], true, 'Price (DCR)', 'Block Height',
+          undefined, false, false))
+        break
+
       case 'ticket-pool-size': // pool size graph
         d = poolSizeFunc(data)
         assign(gOptions, mapDygraphOptions(d, ['Date', 'Ticket Pool Size'], false, 'Ticket Pool Size', 'Date',
@@ -260,6 +278,18 @@ export default class extends Controller {
           undefined, true, false))
         break
 
+      case 'fees-per-day': // total fees per day graph
+        d = feesPerDayFunc(data)
+        assign(gOptions, mapDygraphOptions(d, ['Date', 'Total Fees Per Day'], true, 'Total Fees (DCR)', 'Date',
+          undefined, true, false))
+        break
+
+      case 'fee-rate': // mean fee rate per day graph
+        d = feeRatePerDayFunc(data)
+        assign(gOptions, mapDygraphOptions(d, ['Date', 'Mean Fee Rate Per Day'], true, 'Mean Fee Rate (DCR/kB)', 'Date',
+          undefined, true, false))
+        break
+
       case 'pow-difficulty': // difficulty graph
         d = difficultyFunc(data)
         assign(gOptions, mapDygraphOptions(d, ['Date', 'Difficulty'], true, 'Difficulty', 'Date', undefined, true, false))
@@ -11,6 +11,7 @@ import (
 	"io/ioutil"
 	"net/http"
 	"strconv"
+	"strings"
 
 	"github.com/decred/dcrd/chaincfg/chainhash"
 	apitypes "github.com/decred/hcData/v4/api/types"
@@ -32,6 +33,19 @@ const (
 	ctxNoTxList
 	ctxAddrCmd
 	ctxNbBlocks
+	ctxPageNum
+	ctxPageSize
+)
+
+const (
+	// defaultPageSize and maxPageSize are the default and largest number of
+	// transactions per page for paginated transaction lists.
+	defaultPageSize = 10
+	maxPageSize     = 50
+
+	// maxNbBlocks is the largest number of confirmation targets that may be
+	// requested in the nbBlocks list of the estimatefee endpoint.
+	maxNbBlocks = 8
 )
 
 // BlockHashPathAndIndexCtx is a middleware that embeds the value at the url
@@ -310,6 +324,20 @@ func (c *insightApiContext) BlockIndexOrHashPathCtx(next http.Handler) http.Hand
 	})
 }
 
+// BlockHeightPathCtx returns a http.HandlerFunc that embeds the value at the
+// url part {height} into the request context as a block index.
+func (c *insightApiContext) BlockHeightPathCtx(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		height, err := strconv.Atoi(chi.URLParam(r, "height"))
+		if err != nil || height < 0 {
+			writeInsightError(w, "Valid block height not found")
+			return
+		}
+		ctx := context.WithValue(r.Context(), ctxBlockIndex, height)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
 // GetInsightBlockIndexCtx retrieves the ctxBlockIndex data from the request context. If not
 // set, the return value is an 0 and false
 func (c *insightApiContext) GetInsightBlockIndexCtx(r *http.Request) (int, bool) {
@@ -383,23 +411,97 @@ func (c *insightApiContext) BlockDateLimitQueryCtx(next http.Handler) http.Handl
 }
 
 // GetNbBlocksCtx retrieves the ctxNbBlocks data from the request context. If not
-// set, the return value is 0.
-func (c *insightApiContext) GetNbBlocksCtx(r *http.Request) int {
-	nbBlocks, ok := r.Context().Value(ctxNbBlocks).(int)
+// set, the return value is nil.
+func (c *insightApiContext) GetNbBlocksCtx(r *http.Request) []int {
+	nbBlocks, ok := r.Context().Value(ctxNbBlocks).([]int)
 	if !ok {
-		return 0
+		return nil
 	}
 	return nbBlocks
 }
 
-// NbBlocksCtx will parse the query parameters for nbBlocks.
+// NbBlocksCtx will parse the query parameters for nbBlocks, which may be a
+// comma-separated list of block counts. The list is only set if every value is
+// a positive integer. Lists of more than maxNbBlocks values are rejected.
 func (c *insightApiContext) NbBlocksCtx(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		ctx := r.Context()
 		nbBlocks := r.FormValue("nbBlocks")
-		nbBlocksint, err := strconv.Atoi(nbBlocks)
-		if err == nil {
-			ctx = context.WithValue(r.Context(), ctxNbBlocks, nbBlocksint)
+		if nbBlocks != "" {
+			nbBlocksInts, err := parseNbBlocks(nbBlocks)
+			if err != nil {
+				writeInsightError(w, fmt.Sprintf("Invalid nbBlocks: %v", err))
+				return
+			}
+			if len(nbBlocksInts) > 0 {
+				ctx = context.WithValue(r.Context(), ctxNbBlocks, nbBlocksInts)
+			}
+		}
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// parseNbBlocks parses a comma-separated list of block counts, dropping
+// duplicates. An error is returned if the list has more than maxNbBlocks
+// values. nil is returned if any value is not a positive integer.
+func parseNbBlocks(nbBlocks string) ([]int, error) {
+	nbs := strings.Split(nbBlocks, ",")
+	if len(nbs) > maxNbBlocks {
+		return nil, fmt.Errorf("too many nbBlocks values, the maximum is %d", maxNbBlocks)
+	}
+	nbBlocksInts := make([]int, 0, len(nbs))
+	seen := make(map[int]bool, len(nbs))
+	for _, nb := range nbs {
+		nbBlocksInt, err := strconv.Atoi(strings.TrimSpace(nb))
+		if err != nil || nbBlocksInt < 1 {
+			return nil, nil
+		}
+		if seen[nbBlocksInt] {
+			continue
+		}
+		seen[nbBlocksInt] = true
+		nbBlocksInts = append(nbBlocksInts, nbBlocksInt)
+	}
+	return nbBlocksInts, nil
+}
+
+// GetPageNumCtx retrieves the ctxPageNum data ("pageNum") from the request
+// context. If not set, the return value is 0, the first page.
+func (c *insightApiContext) GetPageNumCtx(r *http.Request) int {
+	pageNum, ok := r.Context().Value(ctxPageNum).(int)
+	if !ok {
+		return 0
+	}
+	return pageNum
+}
+
+// GetPageSizeCtx retrieves the ctxPageSize data ("pageSize") from the request
+// context. If not set, the return value is defaultPageSize.
+func (c *insightApiContext) GetPageSizeCtx(r *http.Request) int {
+	pageSize, ok := r.Context().Value(ctxPageSize).(int)
+	if !ok {
+		return defaultPageSize
+	}
+	return pageSize
+}
+
+// PageNumCtx will parse the query parameters for the zero-based page number,
+// pageNum, and the optional page size, pageSize. Negative page numbers and
+// non-positive page sizes are ignored, and page sizes are limited to
+// maxPageSize.
+func (c *insightApiContext) PageNumCtx(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		pageNum, err := strconv.Atoi(r.FormValue("pageNum"))
+		if err == nil && pageNum >= 0 {
+			ctx = context.WithValue(ctx, ctxPageNum, pageNum)
+		}
+		pageSize, err := strconv.Atoi(r.FormValue("pageSize"))
+		if err == nil && pageSize > 0 {
+			if pageSize > maxPageSize {
+				pageSize = maxPageSize
+			}
+			ctx = context.WithValue(ctx, ctxPageSize, pageSize)
 		}
 		next.ServeHTTP(w, r.WithContext(ctx))
 	})
@@ -0,0 +1,34 @@
+package insight
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestParseNbBlocks(t *testing.T) {
+	tests := []struct {
+		nbBlocks string
+		want     []int
+		wantErr  bool
+	}{
+		{"2", []int{2}, false},
+		{"2, 6,12", []int{2, 6, 12}, false},
+		// Duplicates are dropped, keeping the first occurrence.
+		{"6,2,6,2", []int{6, 2}, false},
+		// Any invalid value discards the list.
+		{"2,x", nil, false},
+		{"2,0", nil, false},
+		{"1,2,3,4,5,6,7,8", []int{1, 2, 3, 4, 5, 6, 7, 8}, false},
+		{"1,2,3,4,5,6,7,8,9", nil, true},
+		{"2,2,2,2,2,2,2,2,2", nil, true},
+	}
+	for _, tt := range tests {
+		got, err := parseNbBlocks(tt.nbBlocks)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parseNbBlocks(%q): unexpected error state %v.", tt.nbBlocks, err)
+		}
+		if fmt.Sprint(got) != fmt.Sprint(tt.want) || (got == nil) != (tt.want == nil) {
+			t.Errorf("parseNbBlocks(%q) = %v, wanted %v.", tt.nbBlocks, got, tt.want)
+		}
+	}
+}
@@ -46,15 +46,27 @@ func NewInsightApiRouter(app *insightApiContext, useRealIP bool) ApiMux {
 	// Block endpoints
 	mux.With(app.BlockDateLimitQueryCtx).Get("/blocks", app.getBlockSummaryByTime)
 	mux.With(app.BlockIndexOrHashPathCtx).Get("/block/{idxorhash}", app.getBlockSummary)
+	mux.With(app.BlockIndexOrHashPathCtx).Get("/block/{idxorhash}/txids", app.getBlockTxids)
 	mux.With(app.BlockIndexOrHashPathCtx).Get("/block-index/{idxorhash}", app.getBlockHash)
 	mux.With(app.BlockIndexOrHashPathCtx).Get("/rawblock/{idxorhash}", app.getRawBlock)
+	mux.With(app.BlockIndexOrHashPathCtx).Get("/rawblockheader/{idxorhash}", app.getRawBlockHeader)
+	mux.Get("/tip", app.getTip)
+	mux.With(app.BlockHeightPathCtx).Get("/difficulty/{height}", app.getDifficultyAtHeight)
+
+	// Stake endpoints
+	mux.With(app.BlockHeightPathCtx).Get("/stake/diff/{height}", app.getStakeDiffAtHeight)
+
+	// Integrity check endpoints
+	mux.With(app.BlockHeightPathCtx).Get("/verify/block/{height}", app.verifyBlock)
 
 	// Transaction endpoints
 	mux.With(middleware.AllowContentType("application/json"),
 		app.ValidatePostCtx, app.PostBroadcastTxCtx).Post("/tx/send", app.broadcastTransactionRaw)
+	mux.With(middleware.AllowContentType("application/json"),
+		app.ValidatePostCtx, app.PostBroadcastTxCtx).Post("/tx/decode", app.decodeTransactionRaw)
 	mux.With(m.TransactionHashCtx).Get("/tx/{txid}", app.getTransaction)
 	mux.With(m.TransactionHashCtx).Get("/rawtx/{txid}", app.getTransactionHex)
-	mux.With(m.TransactionsCtx).Get("/txs", app.getTransactions)
+	mux.With(m.TransactionsCtx, app.PageNumCtx).Get("/txs", app.getTransactions)
 
 	// Status and Utility
 	mux.With(app.StatusInfoCtx).Get("/status", app.getStatusInfo)
@@ -81,6 +93,7 @@ func NewInsightApiRouter(app *insightApiContext, useRealIP bool) ApiMux {
 		rd.Use(m.AddressPathCtx)
 		rd.With(app.FromToPaginationCtx, app.NoTxListCtx).Get("/", app.getAddressInfo)
 		rd.Get("/utxo", app.getAddressesTxnOutput)
+		rd.Get("/netflow", app.getAddressNetFlow)
 		rd.Route("/{command}", func(ra chi.Router) {
 			ra.With(app.AddressCommandCtx).Get("/", app.getAddressInfo)
 		})
@@ -6,6 +6,7 @@ package insight
 
 import (
 	"bytes"
+	"database/sql"
 	"encoding/hex"
 	"encoding/json"
 	"fmt"
@@ -14,17 +15,22 @@ import (
 	"sort"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
+	"github.com/decred/dcrd/blockchain/stake"
 	"github.com/decred/dcrd/chaincfg"
 	"github.com/decred/dcrd/chaincfg/chainhash"
 	"github.com/decred/dcrd/dcrjson"
 	"github.com/decred/dcrd/dcrutil"
 	"github.com/decred/dcrd/rpcclient"
+	"github.com/decred/dcrd/wire"
 	apitypes "github.com/decred/hcData/v4/api/types"
+	"github.com/decred/hcData/v4/blockdata"
 	"github.com/decred/hcData/v4/db/dbtypes"
 	"github.com/decred/hcData/v4/db/dcrpg"
 	m "github.com/decred/hcData/v4/middleware"
+	"github.com/decred/hcData/v4/rpcutils"
 	"github.com/decred/hcData/v4/semver"
 	"github.com/decred/hcData/v4/txhelpers"
 )
@@ -33,19 +39,31 @@ import (
 // databases (i.e. SQLite, storm, ffldb)
 type DataSourceLite interface {
 	UnconfirmedTxnsForAddress(address string) (*txhelpers.AddressOutpoints, int64, error)
+	UnconfirmedTxnsForAddresses(addresses []string) (map[string]*txhelpers.AddressOutpoints, error)
 }
 
+// statusMaxAge is how long the cached node height is used by the status
+// endpoints before the node is queried again.
+const statusMaxAge = 30 * time.Second
+
 type insightApiContext struct {
 	nodeClient *rpcclient.Client
 	BlockData  *dcrpg.ChainDBRPC
 	params     *chaincfg.Params
 	MemPool    DataSourceLite
+	statusMtx  sync.RWMutex
 	Status     apitypes.Status
-	JSONIndent string
+	// nodeTip and nodeTipTime cache the node's best block height, which may
+	// be ahead of Status.Height while blocks are still being stored.
+	nodeTip     int64
+	nodeTipTime time.Time
+	JSONIndent  string
+	maxTxFee    dcrutil.Amount
 }
 
-// NewInsightContext Constructor for insightApiContext
-func NewInsightContext(client *rpcclient.Client, blockData *dcrpg.ChainDBRPC, params *chaincfg.Params, memPoolData DataSourceLite, JSONIndent string) *insightApiContext {
+// NewInsightContext Constructor for insightApiContext. Transactions with fees
+// above maxTxFee are not broadcast unless high fees are explicitly allowed.
+func NewInsightContext(client *rpcclient.Client, blockData *dcrpg.ChainDBRPC, params *chaincfg.Params, memPoolData DataSourceLite, JSONIndent string, maxTxFee dcrutil.Amount) *insightApiContext {
 	conns, _ := client.GetConnectionCount()
 	nodeHeight, _ := client.GetBlockCount()
 	version := semver.NewSemver(1, 0, 0)
@@ -62,10 +80,66 @@ func NewInsightContext(client *rpcclient.Client, blockData *dcrpg.ChainDBRPC, pa
 			DcrdataVersion:  version.String(),
 			NetworkName:     params.Name,
 		},
+		nodeTip:     nodeHeight,
+		nodeTipTime: time.Now(),
+		maxTxFee:    maxTxFee,
 	}
 	return &newContext
 }
 
+// Store updates the cached node status with the height of a newly connected
+// block and the node's connection count. Store satisfies
+// blockdata.BlockDataSaver so that the cache is refreshed with each new block.
+func (c *insightApiContext) Store(blockData *blockdata.BlockData, _ *wire.MsgBlock) error {
+	conns := int64(-1)
+	if c.nodeClient != nil {
+		var err error
+		conns, err = c.nodeClient.GetConnectionCount()
+		if err != nil {
+			apiLog.Warnf("Failed to get connection count: %v", err)
+			conns = -1
+		}
+	}
+
+	c.statusMtx.Lock()
+	defer c.statusMtx.Unlock()
+	c.Status.Height = blockData.Header.Height
+	if conns >= 0 {
+		c.Status.NodeConnections = conns
+	}
+	return nil
+}
+
+// statusHeight returns the height of the last block stored.
+func (c *insightApiContext) statusHeight() uint32 {
+	c.statusMtx.RLock()
+	defer c.statusMtx.RUnlock()
+	return c.Status.Height
+}
+
+// nodeHeight returns the node's best block height. The cached node height is
+// used if it was updated within statusMaxAge and is not behind the last block
+// stored, otherwise the node is queried and the cache updated.
+func (c *insightApiContext) nodeHeight() (int64, error) {
+	c.statusMtx.RLock()
+	height, updated := c.nodeTip, c.nodeTipTime
+	stored := int64(c.Status.Height)
+	c.statusMtx.RUnlock()
+	if time.Since(updated) < statusMaxAge && height >= stored {
+		return height, nil
+	}
+
+	blockCount, err := c.nodeClient.GetBlockCount()
+	if err != nil {
+		return 0, err
+	}
+	c.statusMtx.Lock()
+	c.nodeTip = blockCount
+	c.nodeTipTime = time.Now()
+	c.statusMtx.Unlock()
+	return blockCount, nil
+}
+
 func (c *insightApiContext) getIndentQuery(r *http.Request) (indent string) {
 	useIndentation := r.URL.Query().Get("indent")
 	if useIndentation == "1" || useIndentation == "true" {
@@ -187,6 +261,26 @@ func (c *insightApiContext) getBlockSummary(w http.ResponseWriter, r *http.Reque
 		return
 	}
 
+	// The ticket pool value and cumulative transaction count are omitted if
+	// they cannot be retrieved.
+	poolValue, _, err := c.BlockData.ChainDB.TicketPoolAtHeight(blockDcrd.Height)
+	if err != nil {
+		apiLog.Warnf("TicketPoolAtHeight: %v", err)
+	} else {
+		poolValueSat, _ := dcrutil.NewAmount(poolValue)
+		poolValueAtoms := int64(poolValueSat)
+		blockInsight[0].PoolValue = &poolValue
+		blockInsight[0].PoolValueSat = &poolValueAtoms
+	}
+
+	totalTxCount, err := c.BlockData.ChainDB.CumulativeTxCountAtHeight(
+		blockDcrd.Height, blockDcrd.Hash)
+	if err != nil {
+		apiLog.Warnf("CumulativeTxCountAtHeight: %v", err)
+	} else {
+		blockInsight[0].TotalTxCount = &totalTxCount
+	}
+
 	writeJSON(w, blockInsight, c.getIndentQuery(r))
 }
 
@@ -229,50 +323,317 @@ func (c *insightApiContext) getBlockChainHashCtx(r *http.Request) *chainhash.Has
 }
 
 func (c *insightApiContext) getRawBlock(w http.ResponseWriter, r *http.Request) {
+	chainHash, ok := c.blockHashFromPath(w, r)
+	if !ok {
+		return
+	}
+
+	blockMsg, err := c.nodeClient.GetBlock(chainHash)
+	if err != nil {
+		writeInsightNotFound(w, fmt.Sprintf("Failed to retrieve block %s: %v", chainHash.String(), err))
+		return
+	}
+	var blockHex bytes.Buffer
+	if err = blockMsg.Serialize(&blockHex); err != nil {
+		apiLog.Errorf("Failed to serialize block: %v", err)
+		writeInsightError(w, fmt.Sprintf("Failed to serialize block"))
+		return
+	}
+
+	blockJSON := struct {
+		BlockHash string `json:"rawblock"`
+	}{
+		hex.EncodeToString(blockHex.Bytes()),
+	}
+	writeJSON(w, blockJSON, c.getIndentQuery(r))
+}
+
+// getRawBlockHeader responds with the hex-encoded serialized header of the
+// block specified by hash or index in the path.
+func (c *insightApiContext) getRawBlockHeader(w http.ResponseWriter, r *http.Request) {
+	chainHash, ok := c.blockHashFromPath(w, r)
+	if !ok {
+		return
+	}
+
+	header, err := c.nodeClient.GetBlockHeader(chainHash)
+	if err != nil {
+		writeInsightNotFound(w, fmt.Sprintf("Failed to retrieve block header %s: %v", chainHash.String(), err))
+		return
+	}
+	headerHex, err := blockHeaderHex(header)
+	if err != nil {
+		apiLog.Errorf("Failed to serialize block header: %v", err)
+		writeInsightError(w, "Failed to serialize block header")
+		return
+	}
+
+	headerJSON := struct {
+		BlockHeader string `json:"rawblockheader"`
+	}{
+		headerHex,
+	}
+	writeJSON(w, headerJSON, c.getIndentQuery(r))
+}
+
+func (c *insightApiContext) getTip(w http.ResponseWriter, r *http.Request) {
+	block, err := c.BlockData.ChainDB.BestBlockSummary()
+	if dbtypes.IsTimeoutErr(err) {
+		apiLog.Errorf("BestBlockSummary: %v", err)
+		http.Error(w, "Database timeout.", http.StatusServiceUnavailable)
+		return
+	}
+	if err != nil {
+		apiLog.Errorf("BestBlockSummary: %v", err)
+		writeInsightError(w, "Unable to get best block")
+		return
+	}
+
+	writeJSON(w, blockTip(block), c.getIndentQuery(r))
+}
+
+// blockTip converts the summary of the best block to the Insight chain tip
+// result. The tip always has exactly one confirmation.
+func blockTip(block *dbtypes.BlockDataBasic) *apitypes.InsightBlockTip {
+	return &apitypes.InsightBlockTip{
+		Hash:          block.Hash,
+		Height:        int64(block.Height),
+		Time:          block.Time.T.Unix(),
+		Size:          int32(block.Size),
+		TxLength:      int64(block.NumTx),
+		Difficulty:    block.Difficulty,
+		Confirmations: 1,
+	}
+}
+
+// getBlockTxids responds with the hashes of all of the transactions in the
+// block specified by hash or index in the path. Unlike the paged transactions
+// of /txs?block=, the list is not limited in length. Blocks not yet in the
+// database are looked up with the node.
+func (c *insightApiContext) getBlockTxids(w http.ResponseWriter, r *http.Request) {
+	chainHash, ok := c.blockHashFromPath(w, r)
+	if !ok {
+		return
+	}
+	hash := chainHash.String()
+
+	txids, blockInds, trees, err := c.BlockData.ChainDB.BlockTransactions(hash)
+	if dbtypes.IsTimeoutErr(err) {
+		apiLog.Errorf("BlockTransactions: %v", err)
+		http.Error(w, "Database timeout.", http.StatusServiceUnavailable)
+		return
+	}
+	if err != nil {
+		apiLog.Errorf("BlockTransactions: %v", err)
+		writeInsightError(w, fmt.Sprintf("Unable to get block %s transactions", hash))
+		return
+	}
+	txids = orderBlockTxids(txids, blockInds, trees)
+
+	if len(txids) == 0 {
+		blockVerbose, err := c.nodeClient.GetBlockVerbose(chainHash, false)
+		if err != nil {
+			writeInsightNotFound(w, fmt.Sprintf("Failed to retrieve block %s: %v", hash, err))
+			return
+		}
+		txids = make([]string, 0, len(blockVerbose.Tx)+len(blockVerbose.STx))
+		txids = append(txids, blockVerbose.Tx...)
+		txids = append(txids, blockVerbose.STx...)
+	}
+
+	writeJSON(w, apitypes.InsightBlockTxids{
+		Txids: txids,
+		Total: len(txids),
+	}, c.getIndentQuery(r))
+}
+
+// orderBlockTxids sorts the transaction hashes of a block into block order,
+// with the regular tree transactions before the stake tree transactions, given
+// the index in its tree and the tree of each transaction.
+func orderBlockTxids(txids []string, blockInds []uint32, trees []int8) []string {
+	type blockTx struct {
+		txid  string
+		index uint32
+		tree  int8
+	}
+	txs := make([]blockTx, 0, len(txids))
+	for i := range txids {
+		if i >= len(blockInds) || i >= len(trees) {
+			break
+		}
+		txs = append(txs, blockTx{txids[i], blockInds[i], trees[i]})
+	}
+	sort.Slice(txs, func(i, j int) bool {
+		if txs[i].tree != txs[j].tree {
+			return txs[i].tree < txs[j].tree
+		}
+		return txs[i].index < txs[j].index
+	})
+
+	ordered := make([]string, 0, len(txs))
+	for _, tx := range txs {
+		ordered = append(ordered, tx.txid)
+	}
+	return ordered
+}
 
+// blockHashFromPath gets the block hash from the request context, looking up
+// the hash of the mainchain block if an index was given instead. If the hash
+// cannot be determined, an error response is written and the boolean is false.
+func (c *insightApiContext) blockHashFromPath(w http.ResponseWriter, r *http.Request) (*chainhash.Hash, bool) {
 	hash, ok := c.GetInsightBlockHashCtx(r)
 	if !ok {
 		idx, ok := c.GetInsightBlockIndexCtx(r)
 		if !ok {
 			writeInsightError(w, "Must provide an index or block hash")
-			return
+			return nil, false
 		}
 		var err error
 		hash, err = c.BlockData.ChainDB.GetBlockHash(int64(idx))
 		if dbtypes.IsTimeoutErr(err) {
 			apiLog.Errorf("GetBlockHash: %v", err)
 			http.Error(w, "Database timeout.", http.StatusServiceUnavailable)
-			return
+			return nil, false
 		}
 		if err != nil {
 			writeInsightError(w, "Unable to get block hash from index")
-			return
+			return nil, false
 		}
 	}
 	chainHash, err := chainhash.NewHashFromStr(hash)
 	if err != nil {
 		writeInsightError(w, fmt.Sprintf("Failed to parse block hash: %v", err))
+		return nil, false
+	}
+	return chainHash, true
+}
+
+// blockHeaderHex serializes the block header and hex encodes it.
+func blockHeaderHex(header *wire.BlockHeader) (string, error) {
+	var headerBytes bytes.Buffer
+	if err := header.Serialize(&headerBytes); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(headerBytes.Bytes()), nil
+}
+
+// verifyBlock compares the hash of the block at the requested height in the
+// database with that of the node. A mismatch indicates a stale or corrupt
+// database. If the database has no block at the height, its hash is empty.
+func (c *insightApiContext) verifyBlock(w http.ResponseWriter, r *http.Request) {
+	idx, ok := c.GetInsightBlockIndexCtx(r)
+	if !ok {
+		writeInsightError(w, "Must provide a block height")
 		return
 	}
 
-	blockMsg, err := c.nodeClient.GetBlock(chainHash)
+	dbHash, err := c.BlockData.ChainDB.GetBlockHash(int64(idx))
+	if dbtypes.IsTimeoutErr(err) {
+		apiLog.Errorf("GetBlockHash: %v", err)
+		http.Error(w, "Database timeout.", http.StatusServiceUnavailable)
+		return
+	}
+	if err != nil && err != sql.ErrNoRows {
+		apiLog.Errorf("GetBlockHash: %v", err)
+		writeInsightError(w, "Unable to get block hash from database")
+		return
+	}
+
+	nodeHash, err := c.nodeClient.GetBlockHash(int64(idx))
 	if err != nil {
-		writeInsightNotFound(w, fmt.Sprintf("Failed to retrieve block %s: %v", chainHash.String(), err))
+		writeInsightNotFound(w, fmt.Sprintf("Unable to get block hash from node: %v", err))
 		return
 	}
-	var blockHex bytes.Buffer
-	if err = blockMsg.Serialize(&blockHex); err != nil {
-		apiLog.Errorf("Failed to serialize block: %v", err)
-		writeInsightError(w, fmt.Sprintf("Failed to serialize block"))
+
+	writeJSON(w, blockVerification(int64(idx), dbHash, nodeHash.String()), c.getIndentQuery(r))
+}
+
+// blockVerification compares the hashes of a block according to the database
+// and the node.
+func blockVerification(height int64, dbHash, nodeHash string) *apitypes.InsightBlockVerification {
+	return &apitypes.InsightBlockVerification{
+		Height:   height,
+		DBHash:   dbHash,
+		NodeHash: nodeHash,
+		Match:    dbHash != "" && dbHash == nodeHash,
+	}
+}
+
+// getStakeDiffAtHeight responds with the stake difficulty (ticket price) of
+// the mainchain block at the height specified in the path, in both coins and
+// atoms.
+func (c *insightApiContext) getStakeDiffAtHeight(w http.ResponseWriter, r *http.Request) {
+	idx, ok := c.GetInsightBlockIndexCtx(r)
+	if !ok {
+		writeInsightError(w, "Must provide a block height")
+		return
+	}
+	if idx > c.BlockData.ChainDB.GetHeight() {
+		writeInsightNotFound(w, fmt.Sprintf("Block height %d not found", idx))
 		return
 	}
 
-	blockJSON := struct {
-		BlockHash string `json:"rawblock"`
+	sbits, err := c.BlockData.ChainDB.BlockSBits(int64(idx))
+	if dbtypes.IsTimeoutErr(err) {
+		apiLog.Errorf("BlockSBits: %v", err)
+		http.Error(w, "Database timeout.", http.StatusServiceUnavailable)
+		return
+	}
+	if err == sql.ErrNoRows {
+		writeInsightNotFound(w, fmt.Sprintf("Block height %d not found", idx))
+		return
+	}
+	if err != nil {
+		apiLog.Errorf("BlockSBits: %v", err)
+		writeInsightError(w, "Unable to get stake difficulty")
+		return
+	}
+
+	stakeDiff := struct {
+		Height     int     `json:"height"`
+		StakeDiff  float64 `json:"stakeDifficulty"`
+		StakeAtoms int64   `json:"stakeDifficultyAtoms"`
 	}{
-		hex.EncodeToString(blockHex.Bytes()),
+		idx,
+		dcrutil.Amount(sbits).ToCoin(),
+		sbits,
 	}
-	writeJSON(w, blockJSON, c.getIndentQuery(r))
+	writeJSON(w, stakeDiff, c.getIndentQuery(r))
+}
+
+// getDifficultyAtHeight responds with the proof-of-work difficulty of the
+// mainchain block at the height specified in the path.
+func (c *insightApiContext) getDifficultyAtHeight(w http.ResponseWriter, r *http.Request) {
+	idx, ok := c.GetInsightBlockIndexCtx(r)
+	if !ok {
+		writeInsightError(w, "Must provide a block height")
+		return
+	}
+
+	difficulty, err := c.BlockData.ChainDB.BlockDifficulty(int64(idx))
+	if dbtypes.IsTimeoutErr(err) {
+		apiLog.Errorf("BlockDifficulty: %v", err)
+		http.Error(w, "Database timeout.", http.StatusServiceUnavailable)
+		return
+	}
+	if err == sql.ErrNoRows {
+		writeInsightNotFound(w, fmt.Sprintf("Block height %d not found", idx))
+		return
+	}
+	if err != nil {
+		apiLog.Errorf("BlockDifficulty: %v", err)
+		writeInsightError(w, "Unable to get difficulty")
+		return
+	}
+
+	diff := struct {
+		Height     int     `json:"height"`
+		Difficulty float64 `json:"difficulty"`
+	}{
+		idx,
+		difficulty,
+	}
+	writeJSON(w, diff, c.getIndentQuery(r))
 }
 
 func (c *insightApiContext) broadcastTransactionRaw(w http.ResponseWriter, r *http.Request) {
@@ -289,6 +650,26 @@ func (c *insightApiContext) broadcastTransactionRaw(w http.ResponseWriter, r *ht
 		return
 	}
 
+	// Reject transactions with absurdly high fees unless explicitly allowed
+	allowHighFees, _ := strconv.ParseBool(r.URL.Query().Get("allowHighFees"))
+	if !allowHighFees {
+		msgTx, err := txhelpers.MsgTxFromHex(rawHexTx)
+		if err != nil {
+			writeInsightError(w, fmt.Sprintf("Unable to decode transaction: %v", err))
+			return
+		}
+		err = checkTxFee(msgTx, c.prevOutValue, c.maxTxFee)
+		if dbtypes.IsTimeoutErr(err) {
+			apiLog.Errorf("checkTxFee: %v", err)
+			http.Error(w, "Database timeout.", http.StatusServiceUnavailable)
+			return
+		}
+		if err != nil {
+			writeInsightError(w, fmt.Sprintf("Transaction rejected: %v", err))
+			return
+		}
+	}
+
 	// Broadcast
 	txid, err := c.BlockData.SendRawTransaction(rawHexTx)
 	if err != nil {
@@ -306,6 +687,94 @@ func (c *insightApiContext) broadcastTransactionRaw(w http.ResponseWriter, r *ht
 	writeJSON(w, txidJSON, c.getIndentQuery(r))
 }
 
+// prevOutValue looks up the value of a previous outpoint in the database,
+// falling back to the node for outpoints not yet in the database, such as
+// those of mempool transactions.
+func (c *insightApiContext) prevOutValue(prevOut *wire.OutPoint) (int64, error) {
+	_, addresses, value, err := c.BlockData.ChainDB.AddressIDsByOutpoint(
+		prevOut.Hash.String(), prevOut.Index)
+	if dbtypes.IsTimeoutErr(err) {
+		return 0, err
+	}
+	if err == nil && len(addresses) > 0 {
+		return value, nil
+	}
+
+	prevTx, err := c.nodeClient.GetRawTransaction(&prevOut.Hash)
+	if err != nil {
+		return 0, fmt.Errorf("unable to find previous outpoint %v: %v", prevOut, err)
+	}
+	txOuts := prevTx.MsgTx().TxOut
+	if int(prevOut.Index) >= len(txOuts) {
+		return 0, fmt.Errorf("previous outpoint %v does not exist", prevOut)
+	}
+	return txOuts[prevOut.Index].Value, nil
+}
+
+// checkTxFee computes the fee of the transaction from the values of the
+// previous outpoints it spends, given by prevOutValue, and returns an error if
+// the fee exceeds maxFee. The value of a stakebase input is the vote reward
+// claimed by the transaction, as there is no previous outpoint.
+func checkTxFee(msgTx *wire.MsgTx, prevOutValue func(*wire.OutPoint) (int64, error),
+	maxFee dcrutil.Amount) error {
+	var valueIn, valueOut int64
+	for i, txIn := range msgTx.TxIn {
+		if i == 0 && stake.IsSSGen(msgTx) {
+			valueIn += txIn.ValueIn
+			continue
+		}
+		value, err := prevOutValue(&txIn.PreviousOutPoint)
+		if err != nil {
+			return err
+		}
+		valueIn += value
+	}
+	for _, txOut := range msgTx.TxOut {
+		valueOut += txOut.Value
+	}
+
+	fee := dcrutil.Amount(valueIn - valueOut)
+	if fee > maxFee {
+		return fmt.Errorf("fee of %v exceeds the maximum of %v, "+
+			"set allowHighFees=true to broadcast anyway", fee, maxFee)
+	}
+	return nil
+}
+
+// decodeTransactionRaw decodes, but does not broadcast, the rawtx in the
+// request body, responding with the Insight transaction so that it may be
+// previewed. Input addresses and values are found from the database.
+func (c *insightApiContext) decodeTransactionRaw(w http.ResponseWriter, r *http.Request) {
+	// Check for rawtx
+	rawHexTx, ok := c.GetRawHexTx(r)
+	if !ok {
+		// JSON extraction failed or rawtx blank.  Error message already returned.
+		return
+	}
+
+	// Check maximum transaction size
+	if len(rawHexTx)/2 > c.params.MaxTxSize {
+		writeInsightError(w, fmt.Sprintf("Rawtx length exceeds maximum allowable characters (%d bytes received)", len(rawHexTx)/2))
+		return
+	}
+
+	tx, err := decodeRawTx(rawHexTx, c.params)
+	if err != nil {
+		writeInsightError(w, fmt.Sprintf("Unable to decode transaction: %v", err))
+		return
+	}
+
+	// The transaction is not on chain, so it has no spending information.
+	txsNew, err := c.DcrToInsightTxns([]*dcrjson.TxRawResult{tx}, false, false, true)
+	if err != nil {
+		apiLog.Errorf("Error converting transaction: %v", err)
+		writeInsightError(w, fmt.Sprintf("Error converting transaction: %v", err))
+		return
+	}
+
+	writeJSON(w, txsNew[0], c.getIndentQuery(r))
+}
+
 func (c *insightApiContext) getAddressesTxnOutput(w http.ResponseWriter, r *http.Request) {
 	address := m.GetAddressCtx(r) // Required
 	if address == "" {
@@ -313,14 +782,15 @@ func (c *insightApiContext) getAddressesTxnOutput(w http.ResponseWriter, r *http
 		return
 	}
 
-	// Allow Addresses to be single or multiple separated by a comma.
-	addresses := strings.Split(address, ",")
+	// Allow Addresses to be single or multiple separated by a comma, either in
+	// the URL path or in the "addrs" field of a POST body.
+	addresses := validAddresses(address)
 
 	// Initialize Output Structure
 	txnOutputs := make([]apitypes.AddressTxnOutput, 0)
 
 	for _, address := range addresses {
-		confirmedTxnOutputs, err := c.BlockData.ChainDB.AddressUTXO(address)
+		utxos, err := c.addressUTXOs(address)
 		if dbtypes.IsTimeoutErr(err) {
 			apiLog.Errorf("AddressUTXO: %v", err)
 			http.Error(w, "Database timeout.", http.StatusServiceUnavailable)
@@ -330,71 +800,7 @@ func (c *insightApiContext) getAddressesTxnOutput(w http.ResponseWriter, r *http
 			apiLog.Errorf("Error getting UTXOs: %v", err)
 			continue
 		}
-
-		addressOuts, _, err := c.MemPool.UnconfirmedTxnsForAddress(address)
-		if err != nil {
-			apiLog.Errorf("Error getting unconfirmed transactions: %v", err)
-			continue
-		}
-
-		if addressOuts != nil {
-			// If there is any mempool add to the utxo set
-		FUNDING_TX_DUPLICATE_CHECK:
-			for _, f := range addressOuts.Outpoints {
-				fundingTx, ok := addressOuts.TxnsStore[f.Hash]
-				if !ok {
-					apiLog.Errorf("An outpoint's transaction is not available in TxnStore.")
-					continue
-				}
-				if fundingTx.Confirmed() {
-					apiLog.Errorf("An outpoint's transaction is unexpectedly confirmed.")
-					continue
-				}
-				// TODO: Confirmed() not always return true for txs that have
-				// already been confirmed in a block.  The mempool cache update
-				// process should correctly update these.  Until we sort out why we
-				// need to do one more search on utxo and do not add if this is
-				// already in the list as a confirmed tx.
-				for _, utxo := range confirmedTxnOutputs {
-					if utxo.Vout == f.Index && utxo.TxnID == f.Hash.String() {
-						continue FUNDING_TX_DUPLICATE_CHECK
-					}
-				}
-
-				txnOutput := apitypes.AddressTxnOutput{
-					Address:       address,
-					TxnID:         fundingTx.Hash().String(),
-					Vout:          f.Index,
-					ScriptPubKey:  hex.EncodeToString(fundingTx.Tx.TxOut[f.Index].PkScript),
-					Amount:        dcrutil.Amount(fundingTx.Tx.TxOut[f.Index].Value).ToCoin(),
-					Satoshis:      fundingTx.Tx.TxOut[f.Index].Value,
-					Confirmations: 0,
-					BlockTime:     fundingTx.MemPoolTime,
-				}
-				txnOutputs = append(txnOutputs, txnOutput)
-			}
-		}
-		txnOutputs = append(txnOutputs, confirmedTxnOutputs...)
-
-		// Search for items in mempool that spend utxo (matching hash and index)
-		// and remove those from the set
-		for _, f := range addressOuts.PrevOuts {
-			spendingTx, ok := addressOuts.TxnsStore[f.TxSpending]
-			if !ok {
-				apiLog.Errorf("An outpoint's transaction is not available in TxnStore.")
-				continue
-			}
-			if spendingTx.Confirmed() {
-				apiLog.Errorf("A transaction spending the outpoint of an unconfirmed transaction is unexpectedly confirmed.")
-				continue
-			}
-			for g, utxo := range txnOutputs {
-				if utxo.Vout == f.PreviousOutpoint.Index && utxo.TxnID == f.PreviousOutpoint.Hash.String() {
-					// Found a utxo that is unconfirmed spent.  Remove from slice
-					txnOutputs = append(txnOutputs[:g], txnOutputs[g+1:]...)
-				}
-			}
-		}
+		txnOutputs = append(txnOutputs, utxos...)
 	}
 	// Final sort by timestamp desc if unconfirmed and by confirmations
 	// ascending if confirmed
@@ -408,6 +814,100 @@ func (c *insightApiContext) getAddressesTxnOutput(w http.ResponseWriter, r *http
 	writeJSON(w, txnOutputs, c.getIndentQuery(r))
 }
 
+// validAddresses splits a comma-separated list of addresses, skipping and
+// logging those that cannot be decoded.
+func validAddresses(addrList string) []string {
+	var addresses []string
+	for _, address := range strings.Split(addrList, ",") {
+		address = strings.TrimSpace(address)
+		if _, err := dcrutil.DecodeAddress(address); err != nil {
+			apiLog.Warnf("Skipping invalid address %q: %v", address, err)
+			continue
+		}
+		addresses = append(addresses, address)
+	}
+	return addresses
+}
+
+// addressUTXOs gets the confirmed UTXOs of the address from the database,
+// adds the unconfirmed outputs paying to the address, and removes the outputs
+// spent by mempool transactions.
+func (c *insightApiContext) addressUTXOs(address string) ([]apitypes.AddressTxnOutput, error) {
+	confirmedTxnOutputs, err := c.BlockData.ChainDB.AddressUTXO(address)
+	if err != nil {
+		return nil, err
+	}
+
+	addressOuts, _, err := c.MemPool.UnconfirmedTxnsForAddress(address)
+	if err != nil {
+		return nil, fmt.Errorf("error getting unconfirmed transactions: %v", err)
+	}
+	if addressOuts == nil {
+		return confirmedTxnOutputs, nil
+	}
+
+	var txnOutputs []apitypes.AddressTxnOutput
+
+	// If there is any mempool add to the utxo set
+FUNDING_TX_DUPLICATE_CHECK:
+	for _, f := range addressOuts.Outpoints {
+		fundingTx, ok := addressOuts.TxnsStore[f.Hash]
+		if !ok {
+			apiLog.Errorf("An outpoint's transaction is not available in TxnStore.")
+			continue
+		}
+		if fundingTx.Confirmed() {
+			apiLog.Errorf("An outpoint's transaction is unexpectedly confirmed.")
+			continue
+		}
+		// TODO: Confirmed() not always return true for txs that have
+		// already been confirmed in a block.  The mempool cache update
+		// process should correctly update these.  Until we sort out why we
+		// need to do one more search on utxo and do not add if this is
+		// already in the list as a confirmed tx.
+		for _, utxo := range confirmedTxnOutputs {
+			if utxo.Vout == f.Index && utxo.TxnID == f.Hash.String() {
+				continue FUNDING_TX_DUPLICATE_CHECK
+			}
+		}
+
+		txnOutput := apitypes.AddressTxnOutput{
+			Address:       address,
+			TxnID:         fundingTx.Hash().String(),
+			Vout:          f.Index,
+			ScriptPubKey:  hex.EncodeToString(fundingTx.Tx.TxOut[f.Index].PkScript),
+			Amount:        dcrutil.Amount(fundingTx.Tx.TxOut[f.Index].Value).ToCoin(),
+			Satoshis:      fundingTx.Tx.TxOut[f.Index].Value,
+			Confirmations: 0,
+			BlockTime:     fundingTx.MemPoolTime,
+		}
+		txnOutputs = append(txnOutputs, txnOutput)
+	}
+	txnOutputs = append(txnOutputs, confirmedTxnOutputs...)
+
+	// Search for items in mempool that spend utxo (matching hash and index)
+	// and remove those from the set
+	for _, f := range addressOuts.PrevOuts {
+		spendingTx, ok := addressOuts.TxnsStore[f.TxSpending]
+		if !ok {
+			apiLog.Errorf("An outpoint's transaction is not available in TxnStore.")
+			continue
+		}
+		if spendingTx.Confirmed() {
+			apiLog.Errorf("A transaction spending the outpoint of an unconfirmed transaction is unexpectedly confirmed.")
+			continue
+		}
+		for g, utxo := range txnOutputs {
+			if utxo.Vout == f.PreviousOutpoint.Index && utxo.TxnID == f.PreviousOutpoint.Hash.String() {
+				// Found a utxo that is unconfirmed spent.  Remove from slice
+				txnOutputs = append(txnOutputs[:g], txnOutputs[g+1:]...)
+				break
+			}
+		}
+	}
+	return txnOutputs, nil
+}
+
 func (c *insightApiContext) getTransactions(w http.ResponseWriter, r *http.Request) {
 	hash := m.GetBlockHashCtx(r)
 	address := m.GetAddressCtx(r)
@@ -415,6 +915,7 @@ func (c *insightApiContext) getTransactions(w http.ResponseWriter, r *http.Reque
 		writeInsightError(w, "Required query parameters (address or block) not present.")
 		return
 	}
+	pageNum, pageSize := c.GetPageNumCtx(r), c.GetPageSizeCtx(r)
 
 	if hash != "" {
 		blkTrans := c.BlockData.GetBlockVerboseByHash(hash, true)
@@ -424,26 +925,17 @@ func (c *insightApiContext) getTransactions(w http.ResponseWriter, r *http.Reque
 			return
 		}
 
-		txsOld := []*dcrjson.TxRawResult{}
-		txcount := len(blkTrans.RawTx) + len(blkTrans.RawSTx)
-		// Merge tx and stx together and limit result to 10 max
-		count := 0
+		// Merge tx and stx together and select the requested page.
+		txsAll := make([]*dcrjson.TxRawResult, 0,
+			len(blkTrans.RawTx)+len(blkTrans.RawSTx))
 		for i := range blkTrans.RawTx {
-			txsOld = append(txsOld, &blkTrans.RawTx[i])
-			count++
-			if count > 10 {
-				break
-			}
+			txsAll = append(txsAll, &blkTrans.RawTx[i])
 		}
-		if count < 10 {
-			for i := range blkTrans.RawSTx {
-				txsOld = append(txsOld, &blkTrans.RawSTx[i])
-				count++
-				if count > 10 {
-					break
-				}
-			}
+		for i := range blkTrans.RawSTx {
+			txsAll = append(txsAll, &blkTrans.RawSTx[i])
 		}
+		start, end, pagesTotal := pageBounds(len(txsAll), pageNum, pageSize)
+		txsOld := txsAll[start:end]
 
 		// Convert to Insight struct
 		txsNew, err := c.TxConverter(txsOld)
@@ -453,8 +945,13 @@ func (c *insightApiContext) getTransactions(w http.ResponseWriter, r *http.Reque
 			return
 		}
 
+		// Out of range pages have an empty, not null, list of transactions.
+		if txsNew == nil {
+			txsNew = []apitypes.InsightTx{}
+		}
+
 		blockTransactions := apitypes.InsightBlockAddrTxSummary{
-			PagesTotal: int64(txcount),
+			PagesTotal: int64(pagesTotal),
 			Txs:        txsNew,
 		}
 		writeJSON(w, blockTransactions, c.getIndentQuery(r))
@@ -470,7 +967,7 @@ func (c *insightApiContext) getTransactions(w http.ResponseWriter, r *http.Reque
 		}
 		addresses := []string{address}
 		rawTxs, recentTxs, err :=
-			c.BlockData.ChainDB.InsightAddressTransactions(addresses, int64(c.Status.Height-2))
+			c.BlockData.ChainDB.InsightAddressTransactions(addresses, int64(c.statusHeight()-2))
 		if dbtypes.IsTimeoutErr(err) {
 			apiLog.Errorf("InsightAddressTransactions: %v", err)
 			http.Error(w, "Database timeout.", http.StatusServiceUnavailable)
@@ -516,21 +1013,14 @@ func (c *insightApiContext) getTransactions(w http.ResponseWriter, r *http.Reque
 		// Merge unconfirmed with confirmed transactions
 		rawTxs = append(UnconfirmedTxs, rawTxs...)
 
-		txcount := len(rawTxs)
-
-		if txcount > 10 {
-			rawTxs = rawTxs[0:10]
-		}
+		start, end, pagesTotal := pageBounds(len(rawTxs), pageNum, pageSize)
+		rawTxs = rawTxs[start:end]
 
-		txsOld := []*dcrjson.TxRawResult{}
-		for _, rawTx := range rawTxs {
-			txOld, err1 := c.BlockData.GetRawTransaction(rawTx)
-			if err1 != nil {
-				apiLog.Errorf("Unable to get transaction %s", rawTx)
-				writeInsightError(w, fmt.Sprintf("Error gathering transaction details (%s)", err1))
-				return
-			}
-			txsOld = append(txsOld, txOld)
+		txsOld, err := c.BlockData.GetRawTransactions(rawTxs)
+		if err != nil {
+			apiLog.Errorf("Unable to get transactions: %v", err)
+			writeInsightError(w, fmt.Sprintf("Error gathering transaction details (%s)", err))
+			return
 		}
 
 		// Convert to Insight struct
@@ -541,14 +1031,35 @@ func (c *insightApiContext) getTransactions(w http.ResponseWriter, r *http.Reque
 			return
 		}
 
+		// Out of range pages have an empty, not null, list of transactions.
+		if txsNew == nil {
+			txsNew = []apitypes.InsightTx{}
+		}
+
 		addrTransactions := apitypes.InsightBlockAddrTxSummary{
-			PagesTotal: int64(txcount),
+			PagesTotal: int64(pagesTotal),
 			Txs:        txsNew,
 		}
 		writeJSON(w, addrTransactions, c.getIndentQuery(r))
 	}
 }
 
+// pageBounds computes the slice bounds [start, end) of the zero-based page
+// pageNum of a list of numItems items divided into pages of pageSize items,
+// and the total number of pages. Pages beyond the last give an empty range.
+func pageBounds(numItems, pageNum, pageSize int) (start, end, pagesTotal int) {
+	pagesTotal = (numItems + pageSize - 1) / pageSize
+	if pageNum >= pagesTotal {
+		return numItems, numItems, pagesTotal
+	}
+	start = pageNum * pageSize
+	end = start + pageSize
+	if end > numItems {
+		end = numItems
+	}
+	return
+}
+
 func (c *insightApiContext) getAddressesTxn(w http.ResponseWriter, r *http.Request) {
 	address := m.GetAddressCtx(r) // Required
 	if address == "" {
@@ -573,7 +1084,7 @@ func (c *insightApiContext) getAddressesTxn(w http.ResponseWriter, r *http.Reque
 	UnconfirmedTxs := []string{}
 
 	rawTxs, recentTxs, err :=
-		c.BlockData.ChainDB.InsightAddressTransactions(addresses, int64(c.Status.Height-2))
+		c.BlockData.ChainDB.InsightAddressTransactions(addresses, int64(c.statusHeight()-2))
 	if dbtypes.IsTimeoutErr(err) {
 		apiLog.Errorf("InsightAddressTransactions: %v", err)
 		http.Error(w, "Database timeout.", http.StatusServiceUnavailable)
@@ -586,19 +1097,29 @@ func (c *insightApiContext) getAddressesTxn(w http.ResponseWriter, r *http.Reque
 		return
 	}
 
-	// Confirm all addresses are valid and pull unconfirmed transactions for all addresses
+	// Confirm all addresses are valid
+	validAddrs := make([]string, 0, len(addresses))
 	for _, addr := range addresses {
 		address, err := dcrutil.DecodeAddress(addr)
 		if err != nil {
 			writeInsightError(w, fmt.Sprintf("Address is invalid (%s)", addr))
 			return
 		}
-		addressOuts, _, err := c.MemPool.UnconfirmedTxnsForAddress(address.String())
-		if err != nil {
-			writeInsightError(w, fmt.Sprintf("Error gathering mempool transactions (%s)", err))
-			return
-		}
+		validAddrs = append(validAddrs, address.String())
+	}
 
+	// Pull unconfirmed transactions for all addresses from a single scan of
+	// mempool
+	allAddressOuts, err := c.MemPool.UnconfirmedTxnsForAddresses(validAddrs)
+	if err != nil {
+		writeInsightError(w, fmt.Sprintf("Error gathering mempool transactions (%s)", err))
+		return
+	}
+	for _, addr := range validAddrs {
+		addressOuts := allAddressOuts[addr]
+		if addressOuts == nil {
+			continue
+		}
 	FUNDING_TX_DUPLICATE_CHECK:
 		for _, f := range addressOuts.Outpoints {
 			// Confirm its not already in our recent transactions
@@ -654,15 +1175,11 @@ func (c *insightApiContext) getAddressesTxn(w http.ResponseWriter, r *http.Reque
 	addressOutput.From = int(from)
 	addressOutput.To = int(to)
 
-	txsOld := []*dcrjson.TxRawResult{}
-	for _, rawTx := range rawTxs {
-		txOld, err := c.BlockData.GetRawTransaction(rawTx)
-		if err != nil {
-			apiLog.Errorf("Unable to get transaction %s", rawTx)
-			writeInsightError(w, fmt.Sprintf("Error gathering transaction details (%s)", err))
-			return
-		}
-		txsOld = append(txsOld, txOld)
+	txsOld, err := c.BlockData.GetRawTransactions(rawTxs)
+	if err != nil {
+		apiLog.Errorf("Unable to get transactions: %v", err)
+		writeInsightError(w, fmt.Sprintf("Error gathering transaction details (%s)", err))
+		return
 	}
 
 	// Convert to Insight API struct
@@ -701,9 +1218,71 @@ func (c *insightApiContext) getAddressBalance(w http.ResponseWriter, r *http.Req
 	writeJSON(w, addressInfo.TotalUnspent, c.getIndentQuery(r))
 }
 
+// getAddressNetFlow responds with the coins received and sent by an address,
+// and the net change in its balance, in the time window given by the "start"
+// and "end" URL query parameters (UNIX timestamps).
+func (c *insightApiContext) getAddressNetFlow(w http.ResponseWriter, r *http.Request) {
+	address := m.GetAddressCtx(r)
+	if _, err := dcrutil.DecodeAddress(address); err != nil {
+		writeInsightError(w, "Invalid Address")
+		return
+	}
+
+	start, err := strconv.ParseInt(r.URL.Query().Get("start"), 10, 64)
+	if err != nil {
+		writeInsightError(w, "Invalid start time")
+		return
+	}
+	end, err := strconv.ParseInt(r.URL.Query().Get("end"), 10, 64)
+	if err != nil || end < start {
+		writeInsightError(w, "Invalid end time")
+		return
+	}
+
+	rows, err := c.BlockData.ChainDB.AddressTxnsInTimeRange(address,
+		time.Unix(start, 0), time.Unix(end, 0))
+	if dbtypes.IsTimeoutErr(err) {
+		apiLog.Errorf("AddressTxnsInTimeRange: %v", err)
+		http.Error(w, "Database timeout.", http.StatusServiceUnavailable)
+		return
+	}
+	if err != nil {
+		apiLog.Errorf("AddressTxnsInTimeRange: %v", err)
+		http.Error(w, "Unexpected error retrieving address transactions.",
+			http.StatusInternalServerError)
+		return
+	}
+
+	received, sent := addressNetFlow(rows)
+	writeJSON(w, apitypes.InsightAddressNetFlow{
+		Address:     address,
+		Start:       start,
+		End:         end,
+		Received:    dcrutil.Amount(received).ToCoin(),
+		ReceivedSat: received,
+		Sent:        dcrutil.Amount(sent).ToCoin(),
+		SentSat:     sent,
+		Net:         dcrutil.Amount(received - sent).ToCoin(),
+		NetSat:      received - sent,
+	}, c.getIndentQuery(r))
+}
+
+// addressNetFlow sums the values, in atoms, of the funding (received) and
+// spending (sent) address rows.
+func addressNetFlow(rows []*dbtypes.AddressRow) (received, sent int64) {
+	for _, row := range rows {
+		if row.IsFunding {
+			received += int64(row.Value)
+		} else {
+			sent += int64(row.Value)
+		}
+	}
+	return
+}
+
 func (c *insightApiContext) getSyncInfo(w http.ResponseWriter, r *http.Request) {
 
-	blockChainHeight, err := c.nodeClient.GetBlockCount()
+	blockChainHeight, err := c.nodeHeight()
 
 	// To insure JSON encodes an error properly as a string or no error as null
 	// its easiest to use a pointer to a string.
@@ -749,11 +1328,16 @@ func (c *insightApiContext) getStatusInfo(w http.ResponseWriter, r *http.Request
 	// this value or the other best blocks as done below.  Which one is best?
 	// idx := m.GetBlockIndexCtx(r)
 
-	infoResult, err := c.nodeClient.GetInfo()
-	if err != nil {
-		apiLog.Error("Error getting status")
-		writeInsightError(w, fmt.Sprintf("Error getting status (%s)", err))
-		return
+	// The block hash queries need only the node height, which is cached.
+	var infoResult *dcrjson.InfoWalletResult
+	if statusInfo != "getBestBlockHash" && statusInfo != "getLastBlockHash" {
+		var err error
+		infoResult, err = c.nodeClient.GetInfo()
+		if err != nil {
+			apiLog.Error("Error getting status")
+			writeInsightError(w, fmt.Sprintf("Error getting status (%s)", err))
+			return
+		}
 	}
 
 	switch statusInfo {
@@ -765,10 +1349,16 @@ func (c *insightApiContext) getStatusInfo(w http.ResponseWriter, r *http.Request
 		}
 		writeJSON(w, info, c.getIndentQuery(r))
 	case "getBestBlockHash":
-		blockhash, err := c.nodeClient.GetBlockHash(int64(infoResult.Blocks))
+		height, err := c.nodeHeight()
 		if err != nil {
-			apiLog.Errorf("Error getting block hash %d (%s)", infoResult.Blocks, err)
-			writeInsightError(w, fmt.Sprintf("Error getting block hash %d (%s)", infoResult.Blocks, err))
+			apiLog.Error("Error getting status")
+			writeInsightError(w, fmt.Sprintf("Error getting status (%s)", err))
+			return
+		}
+		blockhash, err := c.nodeClient.GetBlockHash(height)
+		if err != nil {
+			apiLog.Errorf("Error getting block hash %d (%s)", height, err)
+			writeInsightError(w, fmt.Sprintf("Error getting block hash %d (%s)", height, err))
 			return
 		}
 
@@ -779,16 +1369,23 @@ func (c *insightApiContext) getStatusInfo(w http.ResponseWriter, r *http.Request
 		}
 		writeJSON(w, info, c.getIndentQuery(r))
 	case "getLastBlockHash":
-		blockhashtip, err := c.nodeClient.GetBlockHash(int64(infoResult.Blocks))
+		height, err := c.nodeHeight()
+		if err != nil {
+			apiLog.Error("Error getting status")
+			writeInsightError(w, fmt.Sprintf("Error getting status (%s)", err))
+			return
+		}
+		blockhashtip, err := c.nodeClient.GetBlockHash(height)
 		if err != nil {
-			apiLog.Errorf("Error getting block hash %d (%s)", infoResult.Blocks, err)
-			writeInsightError(w, fmt.Sprintf("Error getting block hash %d (%s)", infoResult.Blocks, err))
+			apiLog.Errorf("Error getting block hash %d (%s)", height, err)
+			writeInsightError(w, fmt.Sprintf("Error getting block hash %d (%s)", height, err))
 			return
 		}
-		lastblockhash, err := c.nodeClient.GetBlockHash(int64(c.Status.Height))
+		lastHeight := c.statusHeight()
+		lastblockhash, err := c.nodeClient.GetBlockHash(int64(lastHeight))
 		if err != nil {
-			apiLog.Errorf("Error getting block hash %d (%s)", c.Status.Height, err)
-			writeInsightError(w, fmt.Sprintf("Error getting block hash %d (%s)", c.Status.Height, err))
+			apiLog.Errorf("Error getting block hash %d (%s)", lastHeight, err)
+			writeInsightError(w, fmt.Sprintf("Error getting block hash %d (%s)", lastHeight, err))
 			return
 		}
 
@@ -950,11 +1547,35 @@ func (c *insightApiContext) getAddressInfo(w http.ResponseWriter, r *http.Reques
 		}
 	}
 
+	// Get the portion of the confirmed balance that is immature coinbase or
+	// stakebase, and thus not yet spendable. Only the address summary and the
+	// immatureBalance command need it.
+	var immatureBalanceSat int64
+	if !isCmd || command == "immatureBalance" {
+		immatureBalanceSat, err = c.BlockData.ChainDB.AddressImmatureBalance(address,
+			int64(c.statusHeight()))
+		if dbtypes.IsTimeoutErr(err) {
+			apiLog.Errorf("AddressImmatureBalance: %v", err)
+			http.Error(w, "Database timeout.", http.StatusServiceUnavailable)
+			return
+		}
+		if err != nil {
+			apiLog.Errorf("AddressImmatureBalance: %v", err)
+			http.Error(w, "Unexpected error retrieving address info.", http.StatusInternalServerError)
+			return
+		}
+
+		if isCmd {
+			writeJSON(w, immatureBalanceSat, c.getIndentQuery(r))
+			return
+		}
+	}
+
 	addresses := []string{address}
 
 	// Get confirmed transactions.
 	rawTxs, recentTxs, err :=
-		c.BlockData.ChainDB.InsightAddressTransactions(addresses, int64(c.Status.Height-2))
+		c.BlockData.ChainDB.InsightAddressTransactions(addresses, int64(c.statusHeight()-2))
 	if dbtypes.IsTimeoutErr(err) {
 		apiLog.Errorf("InsightAddressTransactions: %v", err)
 		http.Error(w, "Database timeout.", http.StatusServiceUnavailable)
@@ -1074,6 +1695,8 @@ func (c *insightApiContext) getAddressInfo(w http.ResponseWriter, r *http.Reques
 		UnconfirmedBalance:       dcrutil.Amount(unconfirmedBalanceSat).ToCoin(),
 		UnconfirmedBalanceSat:    unconfirmedBalanceSat,
 		UnconfirmedTxAppearances: int64(len(unconfirmedTxs)),
+		ImmatureBalance:          dcrutil.Amount(immatureBalanceSat).ToCoin(),
+		ImmatureBalanceSat:       immatureBalanceSat,
 	}
 
 	if noTxList == 0 {
@@ -1085,20 +1708,31 @@ func (c *insightApiContext) getAddressInfo(w http.ResponseWriter, r *http.Reques
 
 func (c *insightApiContext) getEstimateFee(w http.ResponseWriter, r *http.Request) {
 	nbBlocks := c.GetNbBlocksCtx(r)
-	if nbBlocks == 0 {
-		nbBlocks = 2
+	if len(nbBlocks) == 0 {
+		nbBlocks = []int{2}
 	}
-	estimateFee := make(map[string]float64)
+	estimateFee := make(map[string]float64, len(nbBlocks))
 
-	// A better solution would be a call to the DCRD RPC "estimatefee" endpoint
-	// but that does not appear to be exposed currently.
-	infoResult, err := c.nodeClient.GetInfo()
-	if err != nil {
-		apiLog.Error("Error getting status")
-		writeInsightError(w, fmt.Sprintf("Error getting status (%s)", err))
-		return
+	// The relay fee is only requested if the node is unable to provide an
+	// estimate.
+	var relayFee *float64
+	for _, nb := range nbBlocks {
+		feeRate, err := rpcutils.EstimateSmartFee(c.nodeClient, int64(nb))
+		if err != nil {
+			if relayFee == nil {
+				apiLog.Warnf("EstimateSmartFee failed, using the relay fee: %v", err)
+				infoResult, err := c.nodeClient.GetInfo()
+				if err != nil {
+					apiLog.Error("Error getting status")
+					writeInsightError(w, fmt.Sprintf("Error getting status (%s)", err))
+					return
+				}
+				relayFee = &infoResult.RelayFee
+			}
+			feeRate = *relayFee
+		}
+		estimateFee[strconv.Itoa(nb)] = feeRate
 	}
-	estimateFee[strconv.Itoa(nbBlocks)] = infoResult.RelayFee
 
 	writeJSON(w, estimateFee, c.getIndentQuery(r))
 }
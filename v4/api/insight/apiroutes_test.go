@@ -0,0 +1,242 @@
+package insight
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/decred/dcrd/chaincfg"
+	"github.com/decred/dcrd/chaincfg/chainhash"
+	"github.com/decred/dcrd/dcrjson"
+	"github.com/decred/dcrd/dcrutil"
+	"github.com/decred/dcrd/wire"
+	apitypes "github.com/decred/hcData/v4/api/types"
+	"github.com/decred/hcData/v4/blockdata"
+	"github.com/decred/hcData/v4/db/dbtypes"
+)
+
+func TestPageBounds(t *testing.T) {
+	tests := []struct {
+		numItems, pageNum, pageSize int
+		start, end, pagesTotal      int
+	}{
+		{25, 0, 10, 0, 10, 3},
+		{25, 1, 10, 10, 20, 3},
+		{25, 2, 10, 20, 25, 3},
+		{25, 3, 10, 25, 25, 3},
+		{20, 1, 10, 10, 20, 2},
+		{0, 0, 10, 0, 0, 0},
+		{60, 1, 50, 50, 60, 2},
+	}
+	for _, tt := range tests {
+		start, end, pagesTotal := pageBounds(tt.numItems, tt.pageNum, tt.pageSize)
+		if start != tt.start || end != tt.end || pagesTotal != tt.pagesTotal {
+			t.Errorf("pageBounds(%d, %d, %d) = (%d, %d, %d), wanted (%d, %d, %d).",
+				tt.numItems, tt.pageNum, tt.pageSize, start, end, pagesTotal,
+				tt.start, tt.end, tt.pagesTotal)
+		}
+	}
+}
+
+func TestValidAddresses(t *testing.T) {
+	var addrs []string
+	for i := byte(1); i <= 2; i++ {
+		pkHash := make([]byte, 20)
+		pkHash[0] = i
+		addr, err := dcrutil.NewAddressPubKeyHash(pkHash, &chaincfg.MainNetParams, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		addrs = append(addrs, addr.EncodeAddress())
+	}
+
+	valid := validAddresses(addrs[0] + ",notanaddress, " + addrs[1] + ",")
+	if len(valid) != 2 || valid[0] != addrs[0] || valid[1] != addrs[1] {
+		t.Errorf("Incorrect valid addresses. Got %v, wanted %v.", valid, addrs)
+	}
+}
+
+func TestAddressNetFlow(t *testing.T) {
+	rows := []*dbtypes.AddressRow{
+		{IsFunding: true, Value: 5e8},
+		{IsFunding: false, Value: 2e8},
+		{IsFunding: true, Value: 1e8},
+	}
+	received, sent := addressNetFlow(rows)
+	if received != 6e8 || sent != 2e8 {
+		t.Errorf("Incorrect flow. Got %d received and %d sent, wanted %d and %d.",
+			received, sent, int64(6e8), int64(2e8))
+	}
+
+	if received, sent = addressNetFlow(nil); received != 0 || sent != 0 {
+		t.Errorf("Expected no flow without rows, got %d received and %d sent.",
+			received, sent)
+	}
+}
+
+func TestStoreUpdatesStatus(t *testing.T) {
+	c := &insightApiContext{
+		Status: apitypes.Status{Height: 1000},
+		// The node is ahead of the stored blocks.
+		nodeTip:     1005,
+		nodeTipTime: time.Now(),
+	}
+
+	// Simulate a new block.
+	err := c.Store(&blockdata.BlockData{
+		Header: dcrjson.GetBlockHeaderVerboseResult{Height: 1001},
+	}, nil)
+	if err != nil {
+		t.Fatalf("Store: %v", err)
+	}
+	if c.statusHeight() != 1001 {
+		t.Errorf("Stored height not updated. Got %d, wanted 1001.", c.statusHeight())
+	}
+
+	// The fresh node height is used without querying the (absent) node, and
+	// is not replaced by the stored height.
+	height, err := c.nodeHeight()
+	if err != nil {
+		t.Fatalf("nodeHeight: %v", err)
+	}
+	if height != 1005 {
+		t.Errorf("Incorrect node height. Got %d, wanted 1005.", height)
+	}
+}
+
+func TestBlockHeaderHex(t *testing.T) {
+	header := wire.BlockHeader{
+		Version:      5,
+		PrevBlock:    chainhash.HashH([]byte("prev")),
+		MerkleRoot:   chainhash.HashH([]byte("merkle")),
+		StakeRoot:    chainhash.HashH([]byte("stake")),
+		VoteBits:     1,
+		Voters:       5,
+		FreshStake:   3,
+		Revocations:  1,
+		PoolSize:     40960,
+		Bits:         0x1a0a9ddf,
+		SBits:        9876543210,
+		Height:       300000,
+		Size:         12345,
+		Timestamp:    time.Unix(1540000000, 0),
+		Nonce:        42,
+		StakeVersion: 5,
+	}
+	headerHex, err := blockHeaderHex(&header)
+	if err != nil {
+		t.Fatalf("blockHeaderHex: %v", err)
+	}
+
+	headerBytes, err := hex.DecodeString(headerHex)
+	if err != nil {
+		t.Fatalf("Invalid hex %s: %v", headerHex, err)
+	}
+	if len(headerBytes) != wire.MaxBlockHeaderPayload {
+		t.Errorf("Incorrect header length %d, wanted %d.", len(headerBytes),
+			wire.MaxBlockHeaderPayload)
+	}
+	var decoded wire.BlockHeader
+	if err = decoded.Deserialize(bytes.NewReader(headerBytes)); err != nil {
+		t.Fatalf("Failed to deserialize header: %v", err)
+	}
+	if decoded.BlockHash() != header.BlockHash() {
+		t.Errorf("Round trip changed the header. Got %+v, wanted %+v.", decoded, header)
+	}
+}
+
+func TestCheckTxFee(t *testing.T) {
+	prevOuts := map[wire.OutPoint]int64{
+		{Hash: chainhash.HashH([]byte("prev0"))}:           6e8,
+		{Hash: chainhash.HashH([]byte("prev1")), Index: 2}: 4e8,
+	}
+	prevOutValue := func(op *wire.OutPoint) (int64, error) {
+		value, ok := prevOuts[*op]
+		if !ok {
+			return 0, fmt.Errorf("unknown outpoint %v", op)
+		}
+		return value, nil
+	}
+
+	msgTx := wire.NewMsgTx()
+	for op := range prevOuts {
+		op := op
+		msgTx.AddTxIn(wire.NewTxIn(&op, 0, nil))
+	}
+	msgTx.AddTxOut(wire.NewTxOut(9.99e8, nil))
+
+	// A fee of 0.01 DCR is accepted.
+	maxFee := dcrutil.Amount(1e8)
+	if err := checkTxFee(msgTx, prevOutValue, maxFee); err != nil {
+		t.Errorf("Reasonable fee rejected: %v", err)
+	}
+
+	// Dropping most of the change leaves an excessive fee of 8 DCR.
+	msgTx.TxOut[0].Value = 2e8
+	err := checkTxFee(msgTx, prevOutValue, maxFee)
+	if err == nil || !strings.Contains(err.Error(), "allowHighFees") {
+		t.Errorf("Excessive fee not rejected: %v", err)
+	}
+
+	// Unknown previous outpoints are errors.
+	msgTx.AddTxIn(wire.NewTxIn(wire.NewOutPoint(&chainhash.Hash{}, 7, wire.TxTreeRegular), 0, nil))
+	if err = checkTxFee(msgTx, prevOutValue, maxFee); err == nil {
+		t.Error("Expected an error for an unknown previous outpoint.")
+	}
+}
+
+func TestBlockVerification(t *testing.T) {
+	const hash = "00000000000000001b4ecd7b76a41e2a6b2ea4e14d4b6b0a6e5b6cb8a1e6a7e1"
+	const otherHash = "0000000000000000147c8e1b46e8e54e3a4d4a6d8c0f9d0b1f4e3c6a1b2d3e4f"
+	tests := []struct {
+		name             string
+		dbHash, nodeHash string
+		match            bool
+	}{
+		{"matching", hash, hash, true},
+		{"mismatching", otherHash, hash, false},
+		{"missing in DB", "", hash, false},
+	}
+	for _, test := range tests {
+		v := blockVerification(300000, test.dbHash, test.nodeHash)
+		if v.Match != test.match || v.Height != 300000 ||
+			v.DBHash != test.dbHash || v.NodeHash != test.nodeHash {
+			t.Errorf("%s: incorrect verification %+v", test.name, v)
+		}
+	}
+}
+
+func TestOrderBlockTxids(t *testing.T) {
+	// A block with 12 regular and 3 stake transactions, listed out of order.
+	var txids, want []string
+	var blockInds []uint32
+	var trees []int8
+	for i := 0; i < 12; i++ {
+		want = append(want, fmt.Sprintf("regular%02d", i))
+	}
+	for i := 0; i < 3; i++ {
+		want = append(want, fmt.Sprintf("stake%02d", i))
+	}
+	for i := len(want) - 1; i >= 0; i-- {
+		txids = append(txids, want[i])
+		if i < 12 {
+			blockInds = append(blockInds, uint32(i))
+			trees = append(trees, wire.TxTreeRegular)
+		} else {
+			blockInds = append(blockInds, uint32(i-12))
+			trees = append(trees, wire.TxTreeStake)
+		}
+	}
+
+	got := orderBlockTxids(txids, blockInds, trees)
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("Incorrect transaction order.\nGot:  %v\nWant: %v", got, want)
+	}
+
+	if got = orderBlockTxids(nil, nil, nil); len(got) != 0 {
+		t.Errorf("Expected no transactions, got %v.", got)
+	}
+}
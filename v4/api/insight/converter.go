@@ -5,10 +5,16 @@
 package insight
 
 import (
+	"encoding/hex"
+
 	"github.com/decred/dcrd/blockchain"
+	"github.com/decred/dcrd/blockchain/stake"
+	"github.com/decred/dcrd/chaincfg"
 	"github.com/decred/dcrd/dcrjson"
 	"github.com/decred/dcrd/dcrutil"
+	"github.com/decred/dcrd/txscript"
 	apitypes "github.com/decred/hcData/v4/api/types"
+	"github.com/decred/hcData/v4/txhelpers"
 )
 
 // TxConverter converts dcrd-tx to insight tx
@@ -80,6 +86,9 @@ func (c *insightApiContext) DcrToInsightTxns(txs []*dcrjson.TxRawResult, noAsm,
 
 		}
 
+		// Coinbase and stakebase outputs are not spendable until maturity.
+		spendableHeight, isBase := spendableAtHeight(tx, int64(c.params.CoinbaseMaturity))
+
 		// Vout fill
 		var vOutSum float64
 		for _, v := range tx.Vout {
@@ -95,6 +104,10 @@ func (c *insightApiContext) DcrToInsightTxns(txs []*dcrjson.TxRawResult, noAsm,
 			if !noAsm {
 				InsightVout.ScriptPubKey.Asm = v.ScriptPubKey.Asm
 			}
+			if isBase {
+				height := spendableHeight
+				InsightVout.SpendableAtHeight = &height
+			}
 
 			txNew.Vouts = append(txNew.Vouts, InsightVout)
 			vOutSum += v.Value
@@ -119,6 +132,7 @@ func (c *insightApiContext) DcrToInsightTxns(txs []*dcrjson.TxRawResult, noAsm,
 				v.ValueSat = 0
 			}
 		}
+		txNew.FeeRate = feeRatePerKB(txNew.Fees, txNew.Size)
 
 		if !noSpent {
 			// Populate the spending status of all vouts. Note: this only
@@ -139,6 +153,105 @@ func (c *insightApiContext) DcrToInsightTxns(txs []*dcrjson.TxRawResult, noAsm,
 	return newTxs, nil
 }
 
+// feeRatePerKB computes the fee rate, in coins per kB, of a transaction with the
+// given fees (in coins) and size (in bytes). The rate is zero if there is no
+// fee or the size is unknown.
+func feeRatePerKB(fees float64, size uint32) float64 {
+	if fees <= 0 || size == 0 {
+		return 0
+	}
+	rate, _ := dcrutil.NewAmount(fees * 1000 / float64(size))
+	return rate.ToCoin()
+}
+
+// spendableAtHeight returns the height at which the outputs of a mined
+// coinbase or stakebase (vote) transaction become spendable, which is the
+// transaction's block height plus the coinbase maturity. The boolean is false
+// for other transactions, and for unconfirmed transactions.
+func spendableAtHeight(tx *dcrjson.TxRawResult, coinbaseMaturity int64) (int64, bool) {
+	if tx.Confirmations < 1 || len(tx.Vin) == 0 {
+		return 0, false
+	}
+	for i := range tx.Vin {
+		if tx.Vin[i].IsCoinBase() || tx.Vin[i].IsStakeBase() {
+			return tx.BlockHeight + coinbaseMaturity, true
+		}
+	}
+	return 0, false
+}
+
+// decodeRawTx decodes a hex-encoded serialized transaction into a
+// dcrjson.TxRawResult like that of the node's decoderawtransaction, so that it
+// may be converted with DcrToInsightTxns. The decoded transaction is
+// unconfirmed, and the output scripts are decoded for the given network.
+func decodeRawTx(rawHexTx string, params *chaincfg.Params) (*dcrjson.TxRawResult, error) {
+	msgTx, err := txhelpers.MsgTxFromHex(rawHexTx)
+	if err != nil {
+		return nil, err
+	}
+
+	tx := &dcrjson.TxRawResult{
+		Hex:      rawHexTx,
+		Txid:     msgTx.TxHash().String(),
+		Version:  int32(msgTx.Version),
+		LockTime: msgTx.LockTime,
+		Expiry:   msgTx.Expiry,
+		Vin:      make([]dcrjson.Vin, 0, len(msgTx.TxIn)),
+		Vout:     make([]dcrjson.Vout, 0, len(msgTx.TxOut)),
+	}
+
+	isCoinbase := blockchain.IsCoinBaseTx(msgTx)
+	isVote := stake.IsSSGen(msgTx)
+	for i, txIn := range msgTx.TxIn {
+		vin := dcrjson.Vin{
+			Sequence:    txIn.Sequence,
+			AmountIn:    dcrutil.Amount(txIn.ValueIn).ToCoin(),
+			BlockHeight: txIn.BlockHeight,
+			BlockIndex:  txIn.BlockIndex,
+		}
+		switch {
+		case isCoinbase:
+			vin.Coinbase = hex.EncodeToString(txIn.SignatureScript)
+		case isVote && i == 0:
+			vin.Stakebase = hex.EncodeToString(txIn.SignatureScript)
+		default:
+			vin.Txid = txIn.PreviousOutPoint.Hash.String()
+			vin.Vout = txIn.PreviousOutPoint.Index
+			vin.Tree = txIn.PreviousOutPoint.Tree
+			asm, _ := txscript.DisasmString(txIn.SignatureScript)
+			vin.ScriptSig = &dcrjson.ScriptSig{
+				Asm: asm,
+				Hex: hex.EncodeToString(txIn.SignatureScript),
+			}
+		}
+		tx.Vin = append(tx.Vin, vin)
+	}
+
+	for i, txOut := range msgTx.TxOut {
+		class, addrs, reqSigs, _ := txscript.ExtractPkScriptAddrs(txOut.Version,
+			txOut.PkScript, params)
+		addresses := make([]string, 0, len(addrs))
+		for _, addr := range addrs {
+			addresses = append(addresses, addr.EncodeAddress())
+		}
+		asm, _ := txscript.DisasmString(txOut.PkScript)
+		tx.Vout = append(tx.Vout, dcrjson.Vout{
+			Value:   dcrutil.Amount(txOut.Value).ToCoin(),
+			N:       uint32(i),
+			Version: txOut.Version,
+			ScriptPubKey: dcrjson.ScriptPubKeyResult{
+				Asm:       asm,
+				Hex:       hex.EncodeToString(txOut.PkScript),
+				ReqSigs:   int32(reqSigs),
+				Type:      class.String(),
+				Addresses: addresses,
+			},
+		})
+	}
+
+	return tx, nil
+}
+
 // DcrToInsightBlock converts a dcrjson.GetBlockVerboseResult to Insight block.
 func (c *insightApiContext) DcrToInsightBlock(inBlocks []*dcrjson.GetBlockVerboseResult) ([]*apitypes.InsightBlockResult, error) {
 	RewardAtBlock := func(blocknum int64, voters uint16) float64 {
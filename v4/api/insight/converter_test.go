@@ -0,0 +1,102 @@
+package insight
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/decred/dcrd/chaincfg"
+	"github.com/decred/dcrd/dcrjson"
+)
+
+func TestSpendableAtHeight(t *testing.T) {
+	maturity := int64(chaincfg.MainNetParams.CoinbaseMaturity)
+
+	coinbase := &dcrjson.TxRawResult{
+		BlockHeight:   1000,
+		Confirmations: 3,
+		Vin: []dcrjson.Vin{
+			{Coinbase: "0000000000000000"},
+		},
+	}
+	height, ok := spendableAtHeight(coinbase, maturity)
+	if !ok {
+		t.Fatal("Coinbase transaction not identified.")
+	}
+	if height != 1000+maturity {
+		t.Errorf("Incorrect spendable height. Got %d, expected %d.", height, 1000+maturity)
+	}
+
+	regular := &dcrjson.TxRawResult{
+		BlockHeight:   1000,
+		Confirmations: 3,
+		Vin: []dcrjson.Vin{
+			{Txid: "f4a44e6916f9ee5a2e41558e0662c1d26206780078dc0a426b3607fd43e34145"},
+		},
+	}
+	if _, ok = spendableAtHeight(regular, maturity); ok {
+		t.Error("Regular transaction should not have a spendable height.")
+	}
+}
+
+func TestFeeRatePerKB(t *testing.T) {
+	tests := []struct {
+		fees float64
+		size uint32
+		rate float64
+	}{
+		// A 253 byte transaction paying 0.0000253 pays 0.0001 per kB.
+		{0.0000253, 253, 0.0001},
+		{0.01, 1000, 0.01},
+		{0.01, 2000, 0.005},
+		// Coinbase transactions report no fee.
+		{0, 183, 0},
+		{0.01, 0, 0},
+	}
+	for _, tt := range tests {
+		if rate := feeRatePerKB(tt.fees, tt.size); rate != tt.rate {
+			t.Errorf("feeRatePerKB(%v, %d) = %v, wanted %v.", tt.fees, tt.size,
+				rate, tt.rate)
+		}
+	}
+}
+
+func TestDecodeRawTx(t *testing.T) {
+	// A transaction spending one regular tree output to a P2PKH output and a
+	// nulldata output.
+	const rawHexTx = "0100000001daeda1a74f458ecff28ac8f510ddb9a7a20140e4879bf44b" +
+		"30c211271ea2d36f0100000000ffffffff0200e1f5050000000000001976a9140101" +
+		"01010101010101010101010101010101010188ac00000000000000000000086a0668" +
+		"636461746100000000000000000180d1f0080000000000000000ffffffff0151"
+
+	tx, err := decodeRawTx(rawHexTx, &chaincfg.MainNetParams)
+	if err != nil {
+		t.Fatalf("decodeRawTx: %v", err)
+	}
+	if tx.Txid != "ed2e593add0356a83cb5ed0047c4f1726480df7c270d79db6e7d679594ac469c" {
+		t.Errorf("Incorrect txid %s.", tx.Txid)
+	}
+	if len(tx.Vin) != 1 || tx.Vin[0].Vout != 1 || tx.Vin[0].AmountIn != 1.5 ||
+		tx.Vin[0].ScriptSig == nil {
+		t.Errorf("Incorrect inputs: %+v", tx.Vin)
+	}
+
+	if len(tx.Vout) != 2 {
+		t.Fatalf("Expected 2 outputs, got %d.", len(tx.Vout))
+	}
+	p2pkh := tx.Vout[0]
+	if p2pkh.Value != 1 || p2pkh.ScriptPubKey.Type != "pubkeyhash" ||
+		!reflect.DeepEqual(p2pkh.ScriptPubKey.Addresses,
+			[]string{"HsBjeWvT85gM7WAZZhVquCABT2zFRfQg4Xu"}) {
+		t.Errorf("Incorrect P2PKH output: %+v", p2pkh)
+	}
+	nulldata := tx.Vout[1]
+	if nulldata.N != 1 || nulldata.Value != 0 ||
+		nulldata.ScriptPubKey.Type != "nulldata" ||
+		len(nulldata.ScriptPubKey.Addresses) != 0 {
+		t.Errorf("Incorrect nulldata output: %+v", nulldata)
+	}
+
+	if _, err = decodeRawTx("zz", &chaincfg.MainNetParams); err == nil {
+		t.Error("Invalid hex should fail to decode.")
+	}
+}
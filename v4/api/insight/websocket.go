@@ -0,0 +1,545 @@
+// Copyright (c) 2018, The Decred developers
+// See LICENSE for details.
+
+package insight
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/decred/dcrd/chaincfg"
+	"github.com/decred/dcrd/dcrutil"
+	"github.com/decred/dcrd/wire"
+	"github.com/decred/hcData/v4/blockdata"
+	"github.com/decred/hcData/v4/explorer"
+	"github.com/decred/hcData/v4/txhelpers"
+	"golang.org/x/net/websocket"
+)
+
+const (
+	wsWriteTimeout  = 10 * time.Second
+	wsMaxPayload    = 1 << 12
+	clientQueueSize = 16
+
+	// maxClientAddresses is the maximum number of addresses to which a single
+	// websocket client may subscribe.
+	maxClientAddresses = 64
+
+	// addressCheckInterval is how often the unconfirmed transactions of the
+	// subscribed addresses are checked, if the mempool changed since the last
+	// check.
+	addressCheckInterval = 5 * time.Second
+)
+
+// WebSocketMessage is the JSON object pushed to Insight websocket clients.
+type WebSocketMessage struct {
+	Event   string      `json:"event"`
+	Message interface{} `json:"message"`
+}
+
+// WebSocketRequest is the JSON object received from Insight websocket clients,
+// where Message is the address for "subscribe" and "unsubscribe" events.
+type WebSocketRequest struct {
+	Event   string `json:"event"`
+	Message string `json:"message"`
+}
+
+// WebSocketBlock is the message of a "block" event.
+type WebSocketBlock struct {
+	Height int64  `json:"height"`
+	Hash   string `json:"hash"`
+}
+
+// WebSocketAddressTxns is the message of an "address" event, listing the
+// unconfirmed transactions involving the address.
+type WebSocketAddressTxns struct {
+	Address string   `json:"address"`
+	Txns    []string `json:"txids"`
+}
+
+// UnconfirmedTxnsSource provides the mempool transactions for a set of
+// addresses.
+type UnconfirmedTxnsSource interface {
+	UnconfirmedTxnsForAddresses(addresses []string) (map[string]*txhelpers.AddressOutpoints, error)
+}
+
+// wsClient is a websocket connection's outgoing message queue and address
+// subscriptions. The subscriptions are guarded by the wsSubscriptions mutex.
+type wsClient struct {
+	send  chan *WebSocketMessage
+	addrs map[string]struct{}
+}
+
+// wsSubscriptions tracks the connected websocket clients, the addresses to
+// which they are subscribed, and the last seen unconfirmed transactions of
+// each subscribed address.
+type wsSubscriptions struct {
+	mtx         sync.RWMutex
+	clients     map[*wsClient]struct{}
+	addrs       map[string]map[*wsClient]struct{}
+	unconfirmed map[string][]string
+}
+
+func newWsSubscriptions() *wsSubscriptions {
+	return &wsSubscriptions{
+		clients:     make(map[*wsClient]struct{}),
+		addrs:       make(map[string]map[*wsClient]struct{}),
+		unconfirmed: make(map[string][]string),
+	}
+}
+
+// register creates a new client.
+func (s *wsSubscriptions) register() *wsClient {
+	cl := &wsClient{
+		send:  make(chan *WebSocketMessage, clientQueueSize),
+		addrs: make(map[string]struct{}),
+	}
+	s.mtx.Lock()
+	s.clients[cl] = struct{}{}
+	s.mtx.Unlock()
+	return cl
+}
+
+// unregister removes the client and its address subscriptions, and closes its
+// message queue. Unregistering a client more than once is a no-op.
+func (s *wsSubscriptions) unregister(cl *wsClient) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	if _, ok := s.clients[cl]; !ok {
+		return
+	}
+	for addr := range cl.addrs {
+		s.removeAddr(cl, addr)
+	}
+	delete(s.clients, cl)
+	close(cl.send)
+}
+
+// unregisterAll unregisters every client.
+func (s *wsSubscriptions) unregisterAll() {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	for cl := range s.clients {
+		close(cl.send)
+	}
+	s.clients = make(map[*wsClient]struct{})
+	s.addrs = make(map[string]map[*wsClient]struct{})
+	s.unconfirmed = make(map[string][]string)
+}
+
+// removeAddr deletes the client's subscription to addr, forgetting the address
+// when it has no more subscribers. The write lock must be held.
+func (s *wsSubscriptions) removeAddr(cl *wsClient, addr string) {
+	delete(cl.addrs, addr)
+	subs, ok := s.addrs[addr]
+	if !ok {
+		return
+	}
+	delete(subs, cl)
+	if len(subs) == 0 {
+		delete(s.addrs, addr)
+		delete(s.unconfirmed, addr)
+	}
+}
+
+// subscribe adds a subscription to addr for the client. The returned bool
+// indicates if the address had no subscribers.
+func (s *wsSubscriptions) subscribe(cl *wsClient, addr string) (bool, error) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	if _, ok := s.clients[cl]; !ok {
+		return false, fmt.Errorf("client not registered")
+	}
+	if _, ok := cl.addrs[addr]; ok {
+		return false, nil
+	}
+	if len(cl.addrs) >= maxClientAddresses {
+		return false, fmt.Errorf("too many subscriptions (max %d)", maxClientAddresses)
+	}
+	cl.addrs[addr] = struct{}{}
+	subs, ok := s.addrs[addr]
+	if !ok {
+		subs = make(map[*wsClient]struct{})
+		s.addrs[addr] = subs
+	}
+	subs[cl] = struct{}{}
+	return !ok, nil
+}
+
+// unsubscribe removes the client's subscription to addr.
+func (s *wsSubscriptions) unsubscribe(cl *wsClient, addr string) {
+	s.mtx.Lock()
+	s.removeAddr(cl, addr)
+	s.mtx.Unlock()
+}
+
+// numClients returns the number of registered clients.
+func (s *wsSubscriptions) numClients() int {
+	s.mtx.RLock()
+	defer s.mtx.RUnlock()
+	return len(s.clients)
+}
+
+// addresses returns all addresses with at least one subscriber.
+func (s *wsSubscriptions) addresses() []string {
+	s.mtx.RLock()
+	defer s.mtx.RUnlock()
+	addrs := make([]string, 0, len(s.addrs))
+	for addr := range s.addrs {
+		addrs = append(addrs, addr)
+	}
+	return addrs
+}
+
+// initUnconfirmed records the unconfirmed transactions of a newly subscribed
+// address, unless they are already known.
+func (s *wsSubscriptions) initUnconfirmed(addr string, txns []string) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	if _, ok := s.addrs[addr]; !ok {
+		return
+	}
+	if _, ok := s.unconfirmed[addr]; !ok {
+		s.unconfirmed[addr] = txns
+	}
+}
+
+// updateUnconfirmed records the unconfirmed transactions of an address, and
+// notifies the address's subscribers if they differ from the last seen
+// transactions. The number of notified clients is returned.
+func (s *wsSubscriptions) updateUnconfirmed(addr string, txns []string) int {
+	s.mtx.Lock()
+	subs, ok := s.addrs[addr]
+	if !ok {
+		s.mtx.Unlock()
+		return 0
+	}
+	prev, known := s.unconfirmed[addr]
+	s.unconfirmed[addr] = txns
+	if !known || equalStrings(prev, txns) {
+		s.mtx.Unlock()
+		return 0
+	}
+	msg := &WebSocketMessage{
+		Event: "address",
+		Message: WebSocketAddressTxns{
+			Address: addr,
+			Txns:    txns,
+		},
+	}
+	clients := make([]*wsClient, 0, len(subs))
+	for cl := range subs {
+		clients = append(clients, cl)
+	}
+	s.mtx.Unlock()
+
+	return s.send(clients, msg)
+}
+
+// broadcast queues the message for all clients, returning the number of
+// clients to which it was queued.
+func (s *wsSubscriptions) broadcast(msg *WebSocketMessage) int {
+	s.mtx.RLock()
+	clients := make([]*wsClient, 0, len(s.clients))
+	for cl := range s.clients {
+		clients = append(clients, cl)
+	}
+	s.mtx.RUnlock()
+
+	return s.send(clients, msg)
+}
+
+// send queues the message for each of the clients. Clients that have been
+// unregistered are skipped, and clients with a full queue are unregistered.
+func (s *wsSubscriptions) send(clients []*wsClient, msg *WebSocketMessage) int {
+	var sent int
+	var stalled []*wsClient
+	s.mtx.RLock()
+	for _, cl := range clients {
+		if _, ok := s.clients[cl]; !ok {
+			continue
+		}
+		select {
+		case cl.send <- msg:
+			sent++
+		default:
+			stalled = append(stalled, cl)
+		}
+	}
+	s.mtx.RUnlock()
+
+	for _, cl := range stalled {
+		apiLog.Debugf("Dropping websocket client with full message queue.")
+		s.unregister(cl)
+	}
+	return sent
+}
+
+// WebsocketHub pushes new block notifications to all Insight websocket
+// clients, and notifies clients subscribed to an address when the address's
+// unconfirmed transactions change.
+type WebsocketHub struct {
+	HubRelay      chan explorer.HubSignal
+	subs          *wsSubscriptions
+	mempool       UnconfirmedTxnsSource
+	params        *chaincfg.Params
+	checkInterval time.Duration
+	blockMtx      sync.RWMutex
+	lastBlock     WebSocketBlock
+	quit          chan struct{}
+	wg            sync.WaitGroup
+}
+
+// NewWebsocketHub creates a new WebsocketHub and starts its run loop. Each
+// transaction received on newTxChan triggers a check of the subscribed
+// addresses. Stop must be called to end the run loop.
+func NewWebsocketHub(mempool UnconfirmedTxnsSource, newTxChan <-chan *NewTx,
+	params *chaincfg.Params) *WebsocketHub {
+	wsh := &WebsocketHub{
+		HubRelay:      make(chan explorer.HubSignal),
+		subs:          newWsSubscriptions(),
+		mempool:       mempool,
+		params:        params,
+		checkInterval: addressCheckInterval,
+		quit:          make(chan struct{}),
+	}
+	wsh.wg.Add(1)
+	go wsh.run()
+	if newTxChan != nil {
+		go wsh.relayNewTxns(newTxChan)
+	}
+	return wsh
+}
+
+// Stop ends the run loop and disconnects all clients.
+func (wsh *WebsocketHub) Stop() {
+	close(wsh.quit)
+	wsh.wg.Wait()
+}
+
+// Store signals a new block to the websocket clients. Store satisfies
+// blockdata.BlockDataSaver.
+func (wsh *WebsocketHub) Store(blockData *blockdata.BlockData, _ *wire.MsgBlock) error {
+	wsh.blockMtx.Lock()
+	wsh.lastBlock = WebSocketBlock{
+		Height: int64(blockData.Header.Height),
+		Hash:   blockData.Header.Hash,
+	}
+	wsh.blockMtx.Unlock()
+
+	// Signal the run loop, but do not block Store().
+	go func() {
+		select {
+		case wsh.HubRelay <- explorer.SigNewBlock:
+		case <-wsh.quit:
+		case <-time.After(time.Second * 10):
+			apiLog.Errorf("SigNewBlock send failed: Timeout waiting for WebsocketHub.")
+		}
+	}()
+	return nil
+}
+
+// relayNewTxns signals a mempool update for each new transaction until
+// newTxChan is closed.
+func (wsh *WebsocketHub) relayNewTxns(newTxChan <-chan *NewTx) {
+	for range newTxChan {
+		select {
+		case wsh.HubRelay <- explorer.SigMempoolUpdate:
+		case <-wsh.quit:
+			return
+		}
+	}
+}
+
+func (wsh *WebsocketHub) run() {
+	defer wsh.wg.Done()
+	ticker := time.NewTicker(wsh.checkInterval)
+	defer ticker.Stop()
+
+	// Transactions leave the mempool when mined, so both new blocks and new
+	// transactions require the subscribed addresses to be checked.
+	var mempoolChanged bool
+	for {
+		select {
+		case sig := <-wsh.HubRelay:
+			switch sig {
+			case explorer.SigNewBlock:
+				wsh.blockMtx.RLock()
+				block := wsh.lastBlock
+				wsh.blockMtx.RUnlock()
+				n := wsh.subs.broadcast(&WebSocketMessage{
+					Event:   "block",
+					Message: block,
+				})
+				apiLog.Debugf("Signaled new block %d to %d Insight websocket clients.",
+					block.Height, n)
+				mempoolChanged = true
+			case explorer.SigMempoolUpdate:
+				mempoolChanged = true
+			default:
+				apiLog.Errorf("Unknown hub signal: %v", sig)
+			}
+		case <-ticker.C:
+			if mempoolChanged {
+				mempoolChanged = false
+				wsh.checkAddresses()
+			}
+		case <-wsh.quit:
+			wsh.subs.unregisterAll()
+			return
+		}
+	}
+}
+
+// checkAddresses notifies the subscribers of each address with changed
+// unconfirmed transactions. The mempool is scanned once for all of the
+// subscribed addresses.
+func (wsh *WebsocketHub) checkAddresses() {
+	addrs := wsh.subs.addresses()
+	if len(addrs) == 0 {
+		return
+	}
+	addrOuts, err := wsh.mempool.UnconfirmedTxnsForAddresses(addrs)
+	if err != nil {
+		apiLog.Warnf("UnconfirmedTxnsForAddresses: %v", err)
+		return
+	}
+	for _, addr := range addrs {
+		wsh.subs.updateUnconfirmed(addr, unconfirmedTxns(addrOuts[addr]))
+	}
+}
+
+// unconfirmedTxns returns the sorted hashes of the mempool transactions
+// paying to or spending from an address, given the address's outpoints.
+func unconfirmedTxns(outpoints *txhelpers.AddressOutpoints) []string {
+	if outpoints == nil {
+		return []string{}
+	}
+	seen := make(map[string]struct{})
+	for _, op := range outpoints.Outpoints {
+		seen[op.Hash.String()] = struct{}{}
+	}
+	for _, prev := range outpoints.PrevOuts {
+		seen[prev.TxSpending.String()] = struct{}{}
+	}
+	txns := make([]string, 0, len(seen))
+	for txid := range seen {
+		txns = append(txns, txid)
+	}
+	sort.Strings(txns)
+	return txns
+}
+
+// validAddress checks that the string is an address for the hub's network.
+func (wsh *WebsocketHub) validAddress(addr string) bool {
+	if len(addr) > 64 || !isAlphaNumeric(addr) {
+		return false
+	}
+	a, err := dcrutil.DecodeAddress(addr)
+	return err == nil && a.IsForNet(wsh.params)
+}
+
+// subscribe subscribes the client to the address, recording the address's
+// current unconfirmed transactions if it had no other subscribers.
+func (wsh *WebsocketHub) subscribe(cl *wsClient, addr string) error {
+	if !wsh.validAddress(addr) {
+		return fmt.Errorf("invalid address")
+	}
+	newAddr, err := wsh.subs.subscribe(cl, addr)
+	if err != nil || !newAddr {
+		return err
+	}
+	addrOuts, err := wsh.mempool.UnconfirmedTxnsForAddresses([]string{addr})
+	if err != nil {
+		apiLog.Warnf("UnconfirmedTxnsForAddresses(%s): %v", addr, err)
+		return nil
+	}
+	wsh.subs.initUnconfirmed(addr, unconfirmedTxns(addrOuts[addr]))
+	return nil
+}
+
+// WebsocketHandler is the http.HandlerFunc for Insight websocket connections.
+func (wsh *WebsocketHub) WebsocketHandler(w http.ResponseWriter, r *http.Request) {
+	websocket.Handler(wsh.serveClient).ServeHTTP(w, r)
+}
+
+func (wsh *WebsocketHub) serveClient(ws *websocket.Conn) {
+	cl := wsh.subs.register()
+	// Unregistering removes the address subscriptions and closes the
+	// client's message queue, which ends the send loop below.
+	defer wsh.subs.unregister(cl)
+	apiLog.Debugf("New Insight websocket client (%d).", wsh.subs.numClients())
+
+	closeWS := func() {
+		err := ws.Close()
+		// Do not log error if connection is just closed
+		if err != nil && !strings.Contains(err.Error(), explorer.ErrWsClosed) {
+			apiLog.Errorf("Failed to close websocket: %v", err)
+		}
+	}
+	defer closeWS()
+
+	ws.MaxPayloadBytes = wsMaxPayload
+
+	// Receive loop for subscription requests.
+	go func() {
+		defer wsh.subs.unregister(cl)
+		for {
+			var req WebSocketRequest
+			if err := websocket.JSON.Receive(ws, &req); err != nil {
+				if err.Error() != "EOF" && !strings.Contains(err.Error(), explorer.ErrWsClosed) {
+					apiLog.Debugf("Insight websocket client receive error: %v", err)
+				}
+				return
+			}
+
+			resp := &WebSocketMessage{
+				Event:   req.Event + "Resp",
+				Message: req.Message,
+			}
+			switch req.Event {
+			case "subscribe":
+				if err := wsh.subscribe(cl, req.Message); err != nil {
+					resp.Message = "Error: " + err.Error()
+				}
+			case "unsubscribe":
+				wsh.subs.unsubscribe(cl, req.Message)
+			case "ping":
+				continue
+			default:
+				apiLog.Debugf("Unrecognized Insight websocket event: %.40s", req.Event)
+				continue
+			}
+			wsh.subs.send([]*wsClient{cl}, resp)
+		}
+	}()
+
+	// Send loop for block and address notifications, and request responses.
+	for msg := range cl.send {
+		ws.SetWriteDeadline(time.Now().Add(wsWriteTimeout))
+		if err := websocket.JSON.Send(ws, msg); err != nil {
+			// Do not log error if connection is just closed
+			if !strings.Contains(err.Error(), explorer.ErrWsClosed) {
+				apiLog.Debugf("Failed to send Insight websocket %s message: %v",
+					msg.Event, err)
+			}
+			return
+		}
+	}
+}
+
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
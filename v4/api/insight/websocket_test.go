@@ -0,0 +1,180 @@
+package insight
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/decred/dcrd/chaincfg"
+	"github.com/decred/dcrd/chaincfg/chainhash"
+	"github.com/decred/dcrd/dcrjson"
+	"github.com/decred/dcrd/dcrutil"
+	"github.com/decred/dcrd/wire"
+	"github.com/decred/hcData/v4/blockdata"
+	"github.com/decred/hcData/v4/txhelpers"
+	"golang.org/x/net/websocket"
+)
+
+// fakeMempool is an UnconfirmedTxnsSource with a settable list of transactions
+// paying to every address. It counts the mempool scans.
+type fakeMempool struct {
+	sync.Mutex
+	txns  []chainhash.Hash
+	scans int
+}
+
+func (m *fakeMempool) setTxns(txns ...chainhash.Hash) {
+	m.Lock()
+	m.txns = txns
+	m.Unlock()
+}
+
+func (m *fakeMempool) numScans() int {
+	m.Lock()
+	defer m.Unlock()
+	return m.scans
+}
+
+func (m *fakeMempool) UnconfirmedTxnsForAddresses(addresses []string) (map[string]*txhelpers.AddressOutpoints, error) {
+	m.Lock()
+	defer m.Unlock()
+	m.scans++
+	addrOuts := make(map[string]*txhelpers.AddressOutpoints, len(addresses))
+	for _, address := range addresses {
+		ops := txhelpers.NewAddressOutpoints(address)
+		for i := range m.txns {
+			ops.Outpoints = append(ops.Outpoints, wire.NewOutPoint(&m.txns[i], 0, wire.TxTreeRegular))
+		}
+		addrOuts[address] = ops
+	}
+	return addrOuts, nil
+}
+
+type testWsMessage struct {
+	Event   string          `json:"event"`
+	Message json.RawMessage `json:"message"`
+}
+
+func receiveWsMessage(t *testing.T, ws *websocket.Conn, event string) json.RawMessage {
+	ws.SetReadDeadline(time.Now().Add(5 * time.Second))
+	var msg testWsMessage
+	if err := websocket.JSON.Receive(ws, &msg); err != nil {
+		t.Fatalf("Failed to receive %s message: %v", event, err)
+	}
+	if msg.Event != event {
+		t.Fatalf("Expected %s message, got %s: %s", event, msg.Event, msg.Message)
+	}
+	return msg.Message
+}
+
+func TestWebsocketHub(t *testing.T) {
+	params := &chaincfg.MainNetParams
+	addr, err := dcrutil.NewAddressPubKeyHash(make([]byte, 20), params, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	address := addr.EncodeAddress()
+
+	mempool := new(fakeMempool)
+	hub := NewWebsocketHub(mempool, nil, params)
+	defer hub.Stop()
+
+	server := httptest.NewServer(http.HandlerFunc(hub.WebsocketHandler))
+	defer server.Close()
+
+	ws, err := websocket.Dial("ws"+strings.TrimPrefix(server.URL, "http"), "", server.URL)
+	if err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+
+	// Subscribe to the address.
+	err = websocket.JSON.Send(ws, WebSocketRequest{Event: "subscribe", Message: address})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var subscribed string
+	json.Unmarshal(receiveWsMessage(t, ws, "subscribeResp"), &subscribed)
+	if subscribed != address {
+		t.Fatalf("Subscription failed: %s", subscribed)
+	}
+
+	// Simulate a new block.
+	hash := "000000000000000004b1a9d5d2ca1ea1d0f62d8d48bc4e1c0d1a5ba4e7b4b1e7"
+	err = hub.Store(&blockdata.BlockData{
+		Header: dcrjson.GetBlockHeaderVerboseResult{Height: 284000, Hash: hash},
+	}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var block WebSocketBlock
+	json.Unmarshal(receiveWsMessage(t, ws, "block"), &block)
+	if block.Height != 284000 || block.Hash != hash {
+		t.Errorf("Incorrect block message: %+v", block)
+	}
+
+	// A new mempool transaction paying to the address. A second subscribed
+	// address must not cause another mempool scan.
+	addr2, err := dcrutil.NewAddressPubKeyHash(chainhash.HashB([]byte("addr2"))[:20], params, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = websocket.JSON.Send(ws, WebSocketRequest{Event: "subscribe", Message: addr2.EncodeAddress()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	receiveWsMessage(t, ws, "subscribeResp")
+	txHash := chainhash.HashH([]byte("tx"))
+	mempool.setTxns(txHash)
+	scans := mempool.numScans()
+	hub.checkAddresses()
+	if n := mempool.numScans() - scans; n != 1 {
+		t.Errorf("Checking two addresses took %d mempool scans, wanted 1.", n)
+	}
+	notified := make(map[string]bool)
+	for i := 0; i < 2; i++ {
+		var txns WebSocketAddressTxns
+		json.Unmarshal(receiveWsMessage(t, ws, "address"), &txns)
+		if len(txns.Txns) != 1 || txns.Txns[0] != txHash.String() {
+			t.Errorf("Incorrect address message: %+v", txns)
+		}
+		notified[txns.Address] = true
+	}
+	if !notified[address] || !notified[addr2.EncodeAddress()] {
+		t.Errorf("Both addresses should be notified, got %v.", notified)
+	}
+
+	// Disconnecting must remove the client and its subscriptions.
+	ws.Close()
+	deadline := time.Now().Add(5 * time.Second)
+	for hub.subs.numClients() > 0 || len(hub.subs.addresses()) > 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("Client not unregistered after disconnect.")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestWsSubscriptionsLimit(t *testing.T) {
+	subs := newWsSubscriptions()
+	cl := subs.register()
+	for i := 0; i < maxClientAddresses; i++ {
+		if _, err := subs.subscribe(cl, chainhash.HashH([]byte{byte(i)}).String()); err != nil {
+			t.Fatalf("subscribe %d failed: %v", i, err)
+		}
+	}
+	if _, err := subs.subscribe(cl, "one too many"); err == nil {
+		t.Error("Subscriptions beyond the limit should fail.")
+	}
+
+	subs.unregister(cl)
+	if _, ok := <-cl.send; ok {
+		t.Error("Client queue not closed.")
+	}
+	if n := len(subs.addresses()); n != 0 {
+		t.Errorf("%d addresses remain after unregistering the client.", n)
+	}
+}
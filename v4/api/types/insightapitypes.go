@@ -30,10 +30,26 @@ type InsightAddressInfo struct {
 	UnconfirmedBalance       float64  `json:"unconfirmedBalance"`
 	UnconfirmedBalanceSat    int64    `json:"unconfirmedBalanceSat"`
 	UnconfirmedTxAppearances int64    `json:"unconfirmedTxApperances"`
+	ImmatureBalance          float64  `json:"immatureBalance"`
+	ImmatureBalanceSat       int64    `json:"immatureBalanceSat"`
 	TxAppearances            int64    `json:"txApperances"`
 	TransactionsID           []string `json:"transactions,omitempty"`
 }
 
+// InsightAddressNetFlow models the coins received and sent by an address in a
+// time window, and the net change in its balance.
+type InsightAddressNetFlow struct {
+	Address     string  `json:"addrStr"`
+	Start       int64   `json:"start"`
+	End         int64   `json:"end"`
+	Received    float64 `json:"received"`
+	ReceivedSat int64   `json:"receivedSat"`
+	Sent        float64 `json:"sent"`
+	SentSat     int64   `json:"sentSat"`
+	Net         float64 `json:"net"`
+	NetSat      int64   `json:"netSat"`
+}
+
 // InsightRawTx contains the raw transaction string of a transaction.
 type InsightRawTx struct {
 	Rawtx string `json:"rawtx"`
@@ -107,6 +123,7 @@ type InsightTx struct {
 	Size          uint32         `json:"size,omitempty"`
 	ValueIn       float64        `json:"valueIn,omitempty"`
 	Fees          float64        `json:"fees,omitempty"`
+	FeeRate       float64        `json:"feeRate"`
 }
 
 type InsightVin struct {
@@ -133,6 +150,9 @@ type InsightVout struct {
 	SpentTxID    interface{}         `json:"spentTxId"`   // Insight requires null if unspent and spending TxID if spent
 	SpentIndex   interface{}         `json:"spentIndex"`  // Insight requires null if unspent and Index if spent
 	SpentHeight  interface{}         `json:"spentHeight"` // Insight requires null if unspent and SpentHeight if spent
+	// SpendableAtHeight is the height at which a coinbase or stakebase output
+	// matures. It is omitted for other outputs.
+	SpendableAtHeight *int64 `json:"spendableAtHeight,omitempty"`
 }
 
 type InsightScriptPubKey struct {
@@ -160,6 +180,37 @@ type InsightBlockResult struct {
 	NextHash      string   `json:"nextblockhash,omitempty"`
 	Reward        float64  `json:"reward"`
 	IsMainChain   bool     `json:"isMainChain"`
+	PoolValue     *float64 `json:"poolValue,omitempty"`
+	PoolValueSat  *int64   `json:"poolValueSat,omitempty"`
+	TotalTxCount  *int64   `json:"totalTxCount,omitempty"`
+}
+
+// InsightBlockTxids models the list of all transaction hashes in a block, with
+// the regular transactions before the stake transactions.
+type InsightBlockTxids struct {
+	Txids []string `json:"txids"`
+	Total int      `json:"total"`
+}
+
+// InsightBlockTip models the data required by the chain tip json return for
+// Insight API
+type InsightBlockTip struct {
+	Hash          string  `json:"hash"`
+	Height        int64   `json:"height"`
+	Time          int64   `json:"time"`
+	Size          int32   `json:"size"`
+	TxLength      int64   `json:"txlength"`
+	Difficulty    float64 `json:"difficulty"`
+	Confirmations int64   `json:"confirmations"`
+}
+
+// InsightBlockVerification models the hashes of the mainchain block at a
+// height according to the database and the node, and whether they match.
+type InsightBlockVerification struct {
+	Height   int64  `json:"height"`
+	DBHash   string `json:"dbHash"`
+	NodeHash string `json:"nodeHash"`
+	Match    bool   `json:"match"`
 }
 
 // InsightBlocksSummaryResult models data required by blocks json return for
@@ -192,6 +192,9 @@ type BlocksGroupedInfo struct {
 	FreshStake   uint64
 	Revocations  uint64
 	BlocksCount  int64
+	// FeeRate is the average fee rate, in atoms/byte, of the non-coinbase
+	// transactions in the group.
+	FeeRate float64
 }
 
 // TimeBasedGroupings maps a given time grouping to its standard string value.
@@ -462,15 +465,29 @@ type AddressRow struct {
 	ValidMainChain bool
 	// MatchingTxHash provides the relationship between spending tx inputs and
 	// funding tx outputs.
-	MatchingTxHash   string
-	IsFunding        bool
-	TxBlockTime      TimeDef
-	TxHash           string
-	TxVinVoutIndex   uint32
-	Value            uint64
-	VinVoutDbID      uint64
-	MergedDebitCount uint64
-	TxType           int16
+	MatchingTxHash    string
+	IsFunding         bool
+	TxBlockTime       TimeDef
+	TxHash            string
+	TxVinVoutIndex    uint32
+	Value             uint64
+	VinVoutDbID       uint64
+	MergedDebitCount  uint64
+	MergedCreditCount uint64
+	TxType            int16
+}
+
+// AddressExportRow is an addresses table row prepared for export, e.g. as CSV.
+// Value is positive for credits (funding rows) and negative for debits.
+// BlockHeight is the height of the mainchain block containing the transaction,
+// from which its confirmations may be computed.
+type AddressExportRow struct {
+	TxHash         string
+	TxVinVoutIndex uint32
+	IsFunding      bool
+	Value          int64
+	BlockTime      TimeDef
+	BlockHeight    int64
 }
 
 // AddressMetrics defines address metrics needed to make decisions by which
@@ -783,6 +800,15 @@ type AddressBalance struct {
 	NumMergedSpent int64  `json:"num_merged_spent,omitempty"`
 }
 
+// AddressBalanceRow is an address with its balance, the total value of its
+// funding rows less the total value of its spending rows, and the number of
+// transactions involving the address.
+type AddressBalanceRow struct {
+	Address string `json:"address"`
+	Balance int64  `json:"balance"`
+	NumTxns int64  `json:"num_txns"`
+}
+
 // ReduceAddressHistory generates a template AddressInfo from a slice of
 // AddressRow. All fields except NumUnconfirmed and Transactions are set
 // completely. Transactions is partially set, with each transaction having only
@@ -813,6 +839,7 @@ func ReduceAddressHistory(addrHist []*AddressRow) *AddressInfo {
 			// Funding transaction
 			received += int64(addrOut.Value)
 			tx.ReceivedTotal = coin
+			tx.MergedTxnCount = addrOut.MergedCreditCount
 			creditTxns = append(creditTxns, &tx)
 		} else {
 			// Spending transaction
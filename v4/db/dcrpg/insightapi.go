@@ -6,6 +6,7 @@ package dcrpg
 
 import (
 	"context"
+	"time"
 
 	"github.com/decred/dcrd/dcrjson"
 	"github.com/decred/dcrd/dcrutil"
@@ -26,6 +27,17 @@ func (pgb *ChainDBRPC) GetRawTransaction(txid string) (*dcrjson.TxRawResult, err
 	return txraw, nil
 }
 
+// GetRawTransactions gets the dcrjson.TxRawResult for each of the specified
+// transaction hashes with a single batch of requests to the node.
+func (pgb *ChainDBRPC) GetRawTransactions(txids []string) ([]*dcrjson.TxRawResult, error) {
+	txraws, err := rpcutils.BatchGetRawTransactions(pgb.Client, txids)
+	if err != nil {
+		log.Errorf("GetRawTransactions: %v", err)
+		return nil, err
+	}
+	return txraws, nil
+}
+
 // GetBlockHeight returns the height of the block with the specified hash.
 func (pgb *ChainDB) GetBlockHeight(hash string) (int64, error) {
 	ctx, cancel := context.WithTimeout(pgb.ctx, pgb.queryTimeout)
@@ -83,6 +95,17 @@ func (pgb *ChainDB) AddressSpentUnspent(address string) (int64, int64, int64, in
 	return ns, nu, as, au, am, pgb.replaceCancelError(err)
 }
 
+// AddressImmatureBalance retrieves the value of the address' unspent coinbase
+// and stakebase outputs that are not yet spendable as of the block at the
+// specified height.
+func (pgb *ChainDB) AddressImmatureBalance(address string, tipHeight int64) (int64, error) {
+	ctx, cancel := context.WithTimeout(pgb.ctx, pgb.queryTimeout)
+	defer cancel()
+	immature, err := RetrieveAddressImmatureBalance(ctx, pgb.db, address,
+		tipHeight, int64(pgb.chainParams.CoinbaseMaturity))
+	return immature, pgb.replaceCancelError(err)
+}
+
 // AddressIDsByOutpoint fetches all address row IDs for a given outpoint
 // (txHash:voutIndex). TODO: Update the vin due to the issue with amountin
 // invalid for unconfirmed txns.
@@ -167,6 +190,54 @@ func (pgb *ChainDB) GetBlockHash(idx int64) (string, error) {
 	return hash, nil
 }
 
+// BlockSBits returns the stake difficulty, in atoms, of the mainchain block at
+// the specified height. sql.ErrNoRows is returned if there is no such block.
+func (pgb *ChainDB) BlockSBits(height int64) (int64, error) {
+	ctx, cancel := context.WithTimeout(pgb.ctx, pgb.queryTimeout)
+	defer cancel()
+	sbits, err := RetrieveBlockSBits(ctx, pgb.db, height)
+	return sbits, pgb.replaceCancelError(err)
+}
+
+// BlockDifficulty returns the proof-of-work difficulty of the mainchain block
+// at the specified height. sql.ErrNoRows is returned if there is no such block.
+func (pgb *ChainDB) BlockDifficulty(height int64) (float64, error) {
+	ctx, cancel := context.WithTimeout(pgb.ctx, pgb.queryTimeout)
+	defer cancel()
+	difficulty, err := RetrieveBlockDifficulty(ctx, pgb.db, height)
+	return difficulty, pgb.replaceCancelError(err)
+}
+
+// TicketPoolAtHeight returns the value, in coins, and size of the live ticket
+// pool as of the mainchain block at the specified height.
+func (pgb *ChainDB) TicketPoolAtHeight(height int64) (float64, int64, error) {
+	ctx, cancel := context.WithTimeout(pgb.ctx, pgb.queryTimeout)
+	defer cancel()
+	poolValue, poolSize, err := RetrieveTicketPoolAtHeight(ctx, pgb.db, height,
+		pgb.chainParams)
+	return poolValue, poolSize, pgb.replaceCancelError(err)
+}
+
+// CumulativeTxCountAtHeight returns the total number of transactions in the
+// mainchain up to and including the block at the specified height. Since the
+// query sums over the entire chain, counts are cached by the hash of the block
+// at the height.
+func (pgb *ChainDB) CumulativeTxCountAtHeight(height int64, hash string) (int64, error) {
+	if count, ok := pgb.txCounts.get(hash); ok {
+		return count, nil
+	}
+
+	ctx, cancel := context.WithTimeout(pgb.ctx, pgb.queryTimeout)
+	defer cancel()
+	count, err := RetrieveCumulativeTxCountAtHeight(ctx, pgb.db, height)
+	if err != nil {
+		return 0, pgb.replaceCancelError(err)
+	}
+
+	pgb.txCounts.set(hash, count)
+	return count, nil
+}
+
 // AddressBalance returns a AddressBalance for the specified address,
 // transaction count limit, and transaction number offset.
 func (pgb *ChainDB) AddressBalance(address string, N, offset int64) (*dbtypes.AddressBalance, error) {
@@ -186,6 +257,33 @@ func (pgb *ChainDB) BlockSummaryTimeRange(min, max int64, limit int) ([]dbtypes.
 	return blockSummary, pgb.replaceCancelError(err)
 }
 
+// BestBlockSummary returns the basic data, including difficulty, of the best
+// mainchain block.
+func (pgb *ChainDB) BestBlockSummary() (*dbtypes.BlockDataBasic, error) {
+	ctx, cancel := context.WithTimeout(pgb.ctx, pgb.queryTimeout)
+	defer cancel()
+	block, err := RetrieveBestBlockSummary(ctx, pgb.db)
+	return block, pgb.replaceCancelError(err)
+}
+
+// BlockSummaryHeightRange returns the mainchain blocks with heights in the
+// specified range (min, max height), highest first, up to limit blocks.
+func (pgb *ChainDB) BlockSummaryHeightRange(min, max int64, limit int) ([]dbtypes.BlockDataBasic, error) {
+	ctx, cancel := context.WithTimeout(pgb.ctx, pgb.queryTimeout)
+	defer cancel()
+	blockSummary, err := RetrieveBlockSummaryByHeightRange(ctx, pgb.db, min, max, limit)
+	return blockSummary, pgb.replaceCancelError(err)
+}
+
+// AddressTxnsInTimeRange returns the addresses table rows for the specified
+// address with block times in the range [start, end].
+func (pgb *ChainDB) AddressTxnsInTimeRange(address string, start, end time.Time) ([]*dbtypes.AddressRow, error) {
+	ctx, cancel := context.WithTimeout(pgb.ctx, pgb.queryTimeout)
+	defer cancel()
+	rows, err := RetrieveAddressTxnsInTimeRange(ctx, pgb.db, address, start, end)
+	return rows, pgb.replaceCancelError(err)
+}
+
 // AddressUTXO returns the unspent transaction outputs (UTXOs) paying to the
 // specified address in a []apitypes.AddressTxnOutput.
 func (pgb *ChainDB) AddressUTXO(address string) ([]apitypes.AddressTxnOutput, error) {
@@ -112,6 +112,14 @@ const (
 	SelectAddressesMergedSpentCount = `SELECT COUNT( DISTINCT tx_hash ) FROM addresses
 		WHERE address = $1 AND is_funding = FALSE AND valid_mainchain = TRUE;`
 
+	// SelectAddressRoleCounts counts the distinct transactions spending from
+	// (is_funding = FALSE) and paying to (is_funding = TRUE) the address $1.
+	// There is at most one row for each value of is_funding.
+	SelectAddressRoleCounts = `SELECT is_funding, COUNT(DISTINCT tx_hash)
+		FROM addresses
+		WHERE address = $1 AND valid_mainchain = TRUE
+		GROUP BY is_funding;`
+
 	// SelectAddressSpentUnspentCountAndValue gets the number and combined spent
 	// and unspent outpoints for the given address. The key is the "GROUP BY
 	// is_funding, matching_tx_hash=''" part of the statement that gets the data
@@ -143,7 +151,8 @@ const (
 		GROUP BY is_funding, matching_tx_hash=''  -- separate spent and unspent
 		ORDER BY count, is_funding;`
 
-	SelectAddressUnspentWithTxn = `SELECT
+	// selectAddressUnspentWithTxn is the basis for the address UTXO queries.
+	selectAddressUnspentWithTxn = `SELECT
 			addresses.address,
 			addresses.tx_hash,
 			addresses.value,
@@ -155,8 +164,32 @@ const (
 		JOIN transactions ON
 			addresses.tx_hash = transactions.tx_hash
 		JOIN vouts ON addresses.tx_hash = vouts.tx_hash AND addresses.tx_vin_vout_index=vouts.tx_index
-		WHERE addresses.address=$1 AND addresses.is_funding = TRUE AND addresses.matching_tx_hash = '' AND valid_mainchain = TRUE
-		ORDER BY addresses.block_time DESC;`
+		WHERE addresses.address=$1 AND addresses.is_funding = TRUE AND addresses.matching_tx_hash = '' AND valid_mainchain = TRUE `
+
+	SelectAddressUnspentWithTxn = selectAddressUnspentWithTxn +
+		`ORDER BY addresses.block_time DESC;`
+
+	// SelectAddressLargestUnspentWithTxn selects the $2 largest UTXOs paying to
+	// address $1.
+	SelectAddressLargestUnspentWithTxn = selectAddressUnspentWithTxn +
+		`ORDER BY addresses.value DESC, addresses.block_time DESC
+		LIMIT $2;`
+
+	// SelectAddressUnspentCoinbaseStakebase selects the value and block height
+	// of the unspent coinbase (regular tree, block index 0) and stakebase (vote)
+	// outputs paying to an address that are in blocks at height $2 or above.
+	SelectAddressUnspentCoinbaseStakebase = `SELECT
+			addresses.value,
+			transactions.block_height
+		FROM addresses
+		JOIN transactions ON
+			addresses.tx_hash = transactions.tx_hash
+			AND transactions.is_mainchain = TRUE
+		WHERE addresses.address = $1 AND addresses.is_funding = TRUE
+			AND addresses.matching_tx_hash = '' AND addresses.valid_mainchain = TRUE
+			AND transactions.block_height >= $2
+			AND ((transactions.tree = 0 AND transactions.block_index = 0)
+				OR addresses.tx_type = 2);`
 
 	SelectAddressLimitNByAddress = `SELECT ` + addrsColumnNames + ` FROM addresses
 		WHERE address=$1 AND valid_mainchain = TRUE
@@ -175,6 +208,12 @@ const (
 		GROUP BY (tx_hash, valid_mainchain, block_time)  -- merging common transactions in same valid mainchain block
 		ORDER BY block_time DESC LIMIT $2 OFFSET $3;`
 
+	SelectAddressMergedCreditView = `SELECT tx_hash, valid_mainchain, block_time, sum(value), COUNT(*)
+		FROM addresses
+		WHERE address=$1 AND is_funding = TRUE           -- funding transactions
+		GROUP BY (tx_hash, valid_mainchain, block_time)  -- merging common transactions in same valid mainchain block
+		ORDER BY block_time DESC LIMIT $2 OFFSET $3;`
+
 	SelectAddressDebitsLimitNByAddress = `SELECT ` + addrsColumnNames + `
 		FROM addresses WHERE address=$1 AND is_funding = FALSE AND valid_mainchain = TRUE
 		ORDER BY block_time DESC LIMIT $2 OFFSET $3;`
@@ -186,12 +225,180 @@ const (
 	SelectAddressIDsByFundingOutpoint = `SELECT id, address, value FROM addresses WHERE tx_hash=$1 AND
 		tx_vin_vout_index=$2 AND is_funding = TRUE ORDER BY block_time DESC;`
 
+	// SelectNewVsReusedAddressesByBlock counts, for each mainchain block with
+	// height in the range [$1, $2], the distinct addresses appearing for the
+	// first time in the chain (new) and those seen in an earlier block
+	// (reused). The first appearance of every address in the range must be
+	// found by scanning all of its rows, so the cost grows with the address
+	// history of the blocks' addresses, not just the size of the range.
+	SelectNewVsReusedAddressesByBlock = `WITH block_addrs AS (
+			SELECT DISTINCT transactions.block_height AS height, addresses.address
+			FROM addresses
+			JOIN transactions ON transactions.tx_hash = addresses.tx_hash
+				AND transactions.is_mainchain = TRUE
+			WHERE addresses.valid_mainchain = TRUE
+				AND transactions.block_height BETWEEN $1 AND $2
+		), first_seen AS (
+			SELECT addresses.address, MIN(transactions.block_height) AS first_height
+			FROM addresses
+			JOIN transactions ON transactions.tx_hash = addresses.tx_hash
+				AND transactions.is_mainchain = TRUE
+			WHERE addresses.valid_mainchain = TRUE
+				AND addresses.address IN (SELECT address FROM block_addrs)
+			GROUP BY addresses.address
+		)
+		SELECT block_addrs.height,
+			count(CASE WHEN first_seen.first_height = block_addrs.height THEN 1 ELSE NULL END) AS new,
+			count(CASE WHEN first_seen.first_height < block_addrs.height THEN 1 ELSE NULL END) AS reused
+		FROM block_addrs
+		JOIN first_seen ON first_seen.address = block_addrs.address
+		GROUP BY block_addrs.height
+		ORDER BY block_addrs.height;`
+
+	SelectAddressesUsed = `SELECT DISTINCT address FROM addresses WHERE address = ANY($1);`
+
+	SelectAddressesByFundingTx = `SELECT address, value, tx_vin_vout_index
+		FROM addresses WHERE tx_hash=$1 AND is_funding = TRUE
+		ORDER BY tx_vin_vout_index, address;`
+
 	SelectAddressIDByVoutIDAddress = `SELECT id FROM addresses WHERE address=$1 AND
 	    tx_vin_vout_row_id=$2 AND is_funding = TRUE;`
 
 	SelectAddressOldestTxBlockTime = `SELECT block_time FROM addresses WHERE
 		address=$1 ORDER BY block_time LIMIT 1;`
 
+	// SelectAddressBalanceSnapshots computes the confirmed balance of address
+	// $1 as of each of the block heights in the array $2, in the order given.
+	// Funding rows count from the height of the funding transaction, while
+	// spending rows count from the height of the spending transaction, so an
+	// output spent after a cutoff still contributes to that cutoff's balance.
+	SelectAddressBalanceSnapshots = `WITH activity AS (
+			SELECT
+				CASE WHEN addresses.is_funding THEN addresses.value
+					ELSE -addresses.value END AS value,
+				transactions.block_height
+			FROM addresses
+			JOIN transactions ON
+				addresses.tx_hash = transactions.tx_hash
+				AND transactions.is_mainchain = TRUE
+			WHERE addresses.address = $1 AND addresses.valid_mainchain = TRUE
+		)
+		SELECT cutoffs.idx,
+			COALESCE(SUM(CASE WHEN activity.block_height <= cutoffs.height
+				THEN activity.value ELSE 0 END), 0)
+		FROM UNNEST($2::INT8[]) WITH ORDINALITY AS cutoffs(height, idx)
+		LEFT JOIN activity ON TRUE
+		GROUP BY cutoffs.idx
+		ORDER BY cutoffs.idx;`
+
+	// SelectAddressFlowEdges selects the distinct (sender, receiver) address
+	// pairs of the valid mainchain transactions in which any of the addresses
+	// in the array $1 is either a sender or a receiver, up to $2 pairs. A
+	// sender spends a previous output in the transaction, and a receiver is
+	// paid by one of its outputs.
+	SelectAddressFlowEdges = `SELECT DISTINCT spends.address, funds.address
+		FROM addresses AS spends
+		JOIN addresses AS funds ON
+			funds.tx_hash = spends.tx_hash
+			AND funds.is_funding = TRUE
+			AND funds.valid_mainchain = TRUE
+		WHERE spends.is_funding = FALSE AND spends.valid_mainchain = TRUE
+			AND spends.address <> funds.address
+			AND (spends.address = ANY($1) OR funds.address = ANY($1))
+		ORDER BY spends.address, funds.address
+		LIMIT $2;`
+
+	// SelectAddressActivitySpan gets the oldest and newest block times and the
+	// number of rows for address $1. The times are NULL if there are no rows.
+	SelectAddressActivitySpan = `SELECT MIN(block_time), MAX(block_time), COUNT(*)
+		FROM addresses WHERE address=$1;`
+
+	// SelectPureHolderAddresses gets the addresses that have been funded but
+	// have never spent (no is_funding=false rows), with a balance of at least
+	// $1, ordered by balance.
+	SelectPureHolderAddresses = `SELECT address, SUM(value) AS balance
+		FROM addresses
+		WHERE valid_mainchain = TRUE
+		GROUP BY address
+		HAVING BOOL_AND(is_funding) AND SUM(value) >= $1
+		ORDER BY balance DESC
+		LIMIT $2;`
+
+	// SelectAddressesByBalance selects the addresses with a positive balance,
+	// the valid mainchain funded value less the spent value, along with the
+	// number of transactions involving each address. The addresses are ordered
+	// by balance, largest first, with a page of $1 addresses starting at $2.
+	SelectAddressesByBalance = `SELECT address,
+			SUM(CASE WHEN is_funding THEN value ELSE -value END) AS balance,
+			COUNT(DISTINCT tx_hash) AS num_txns
+		FROM addresses
+		WHERE valid_mainchain = TRUE
+		GROUP BY address
+		HAVING SUM(CASE WHEN is_funding THEN value ELSE -value END) > 0
+		ORDER BY balance DESC, address
+		LIMIT $1 OFFSET $2;`
+
+	// SelectAddressTxnsInTimeRange selects the valid mainchain rows for address
+	// $1 with block times in [$2, $3], oldest first.
+	SelectAddressTxnsInTimeRange = `SELECT tx_hash, is_funding, value, block_time
+		FROM addresses
+		WHERE address = $1 AND valid_mainchain = TRUE
+			AND block_time BETWEEN $2 AND $3
+		ORDER BY block_time;`
+
+	// SelectTotalUniqueAddresses counts the distinct addresses in the
+	// addresses table.
+	SelectTotalUniqueAddresses = `SELECT COUNT(DISTINCT address) FROM addresses;`
+
+	// SelectAddressRowsForExport selects every valid mainchain row for address
+	// $1 in chronological order, with debits given negative values, along
+	// with the height of the containing block.
+	SelectAddressRowsForExport = `SELECT addresses.tx_hash, addresses.tx_vin_vout_index,
+			addresses.is_funding,
+			CASE WHEN addresses.is_funding THEN addresses.value ELSE -addresses.value END,
+			addresses.block_time, transactions.block_height
+		FROM addresses
+		JOIN transactions ON transactions.tx_hash = addresses.tx_hash
+			AND transactions.is_mainchain = TRUE
+		WHERE addresses.address = $1 AND addresses.valid_mainchain = TRUE
+		ORDER BY addresses.block_time, transactions.block_height,
+			transactions.block_index, addresses.is_funding DESC,
+			addresses.tx_vin_vout_index;`
+
+	// SelectTxUnspentOutputCount counts the distinct outputs of transaction $1
+	// that are not yet spent. An output paying to several addresses (e.g.
+	// multisig) has several funding rows but is counted once.
+	SelectTxUnspentOutputCount = `SELECT COUNT(DISTINCT tx_vin_vout_index)
+		FROM addresses
+		WHERE tx_hash = $1 AND is_funding = TRUE AND matching_tx_hash = ''
+			AND valid_mainchain = TRUE;`
+
+	// SelectDistinctFundedAddressesInBlock counts the distinct addresses paid
+	// by the outputs of the transactions in the block with hash $1.
+	SelectDistinctFundedAddressesInBlock = `SELECT COUNT(DISTINCT addresses.address)
+		FROM addresses
+		JOIN transactions ON transactions.tx_hash = addresses.tx_hash
+			AND transactions.block_hash = $1
+		WHERE addresses.is_funding = TRUE;`
+
+	// SelectAddressesMostUnspentOutputs gets the $1 addresses with the most
+	// unspent outputs (funding rows with no matching spending transaction),
+	// ordered by the number of unspent outputs.
+	SelectAddressesMostUnspentOutputs = `SELECT address, COUNT(*) AS num_unspent
+		FROM addresses
+		WHERE is_funding = TRUE AND matching_tx_hash = '' AND valid_mainchain = TRUE
+		GROUP BY address
+		ORDER BY num_unspent DESC, address
+		LIMIT $1;`
+
+	// SelectAddressUnspentValueCounts counts the unspent outputs of address
+	// $1 with each distinct value.
+	SelectAddressUnspentValueCounts = `SELECT value, COUNT(*)
+		FROM addresses
+		WHERE address = $1 AND is_funding = TRUE AND matching_tx_hash = ''
+			AND valid_mainchain = TRUE
+		GROUP BY value;`
+
 	// selectAddressTxTypesByAddress gets the transaction type histogram for the
 	// given address using block time binning with bin size of block_time.
 	// Regular transactions are grouped into (SentRtx and ReceivedRtx), SSTx
@@ -98,6 +98,18 @@ const (
 		FROM blocks WHERE time BETWEEN $1 and $2 ORDER BY time DESC LIMIT $3;`
 	SelectBlockByTimeRangeSQLNoLimit = `SELECT hash, height, size, time, numtx
 		FROM blocks WHERE time BETWEEN $1 and $2 ORDER BY time DESC;`
+	SelectBlockByHeightRangeSQL = `SELECT hash, height, size, time, numtx
+		FROM blocks WHERE height BETWEEN $1 and $2 AND is_mainchain = true
+		ORDER BY height DESC LIMIT $3;`
+	SelectBlockByHeightRangeSQLNoLimit = `SELECT hash, height, size, time, numtx
+		FROM blocks WHERE height BETWEEN $1 and $2 AND is_mainchain = true
+		ORDER BY height DESC;`
+	// SelectBlocksPage selects a page of $1 mainchain blocks, highest first,
+	// skipping the $2 highest blocks.
+	SelectBlocksPage = `SELECT hash, height, size, time, numtx
+		FROM blocks WHERE is_mainchain = true
+		ORDER BY height DESC
+		LIMIT $1 OFFSET $2;`
 	SelectBlockHashByHeight = `SELECT hash FROM blocks WHERE height = $1 AND is_mainchain = true;`
 	SelectBlockHeightByHash = `SELECT height FROM blocks WHERE hash = $1;`
 
@@ -107,39 +119,137 @@ const (
 	RetrieveBestBlockHeight = `SELECT id, hash, height FROM blocks
 		WHERE is_mainchain = true ORDER BY height DESC LIMIT 1;`
 
+	// SelectBlockSummaryByHash selects the basic data of the block with hash
+	// $1, including its difficulty.
+	SelectBlockSummaryByHash = `SELECT hash, height, size, time, numtx, difficulty
+		FROM blocks WHERE hash = $1;`
+
+	// SelectBestBlockHeightTime selects the height and time of the best
+	// mainchain block.
+	SelectBestBlockHeightTime = `SELECT height, time FROM blocks
+		WHERE is_mainchain = true ORDER BY height DESC LIMIT 1;`
+
+	// SelectFirstBlockAtTime selects the height of the first mainchain block
+	// with a time no earlier than $1.
+	SelectFirstBlockAtTime = `SELECT height FROM blocks
+		WHERE is_mainchain = true AND time >= $1
+		ORDER BY height LIMIT 1;`
+
 	// SelectBlocksTicketsPrice selects the ticket price and difficulty for the
 	// first block in a stake difficulty window.
 	SelectBlocksTicketsPrice = `SELECT sbits, time, difficulty FROM blocks WHERE height % $1 = 0 ORDER BY time;`
 
-	SelectWindowsByLimit = `SELECT (height/$1)*$1 AS window_start,
-		MAX(difficulty) AS difficulty,
-		SUM(num_rtx) AS txs,
-		SUM(fresh_stake) AS tickets,
-		SUM(voters) AS votes,
-		SUM(revocations) AS revocations,
-		SUM(size) AS size,
-		MAX(sbits) AS sbits,
-		MIN(time) AS time,
-		COUNT(*) AS blocks_count
+	// SelectWindowsByLimit selects a page of stake difficulty windows of $1
+	// blocks. The total fees and size of the mainchain transactions in each
+	// window, excluding coinbase transactions, are aggregated over the heights
+	// spanned by the selected windows only.
+	SelectWindowsByLimit = `WITH windows AS (
+			SELECT (height/$1)*$1 AS window_start,
+			MAX(difficulty) AS difficulty,
+			SUM(num_rtx) AS txs,
+			SUM(fresh_stake) AS tickets,
+			SUM(voters) AS votes,
+			SUM(revocations) AS revocations,
+			SUM(size) AS size,
+			MAX(sbits) AS sbits,
+			MIN(time) AS time,
+			COUNT(*) AS blocks_count
+			FROM blocks
+			GROUP BY window_start
+			ORDER BY window_start DESC
+			LIMIT $2 OFFSET $3
+		), window_fees AS (
+			SELECT (block_height/$1)*$1 AS window_start,
+			SUM(fees) AS fees,
+			SUM(size) AS tx_size
+			FROM transactions
+			WHERE is_mainchain = true AND NOT (tree = 0 AND block_index = 0)
+				AND block_height >= (SELECT MIN(window_start) FROM windows)
+				AND block_height < (SELECT MAX(window_start) FROM windows) + $1
+			GROUP BY 1
+		)
+		SELECT windows.window_start, difficulty, txs, tickets, votes, revocations,
+			size, sbits, time, blocks_count,
+			COALESCE(window_fees.fees, 0), COALESCE(window_fees.tx_size, 0)
+		FROM windows
+		LEFT JOIN window_fees ON window_fees.window_start = windows.window_start
+		ORDER BY windows.window_start DESC;`
+
+	// SelectReorgsPerWindow counts, for each window of $1 blocks, the heights
+	// with more than one block where at least one is not mainchain (orphaned).
+	SelectReorgsPerWindow = `WITH heights AS (
+			SELECT height, COUNT(*) > 1 AND BOOL_OR(NOT is_mainchain) AS reorg
+			FROM blocks
+			GROUP BY height
+		)
+		SELECT (height/$1)*$1 AS window_start,
+			COUNT(CASE WHEN reorg THEN 1 ELSE NULL END) AS reorgs
+		FROM heights
+		GROUP BY window_start
+		ORDER BY window_start;`
+
+	// SelectAvgTxPerBlockPerWindow selects, for each window of $1 blocks, the
+	// average number of transactions per mainchain block.
+	SelectAvgTxPerBlockPerWindow = `SELECT (height/$1)*$1 AS window_start,
+			SUM(numtx)::FLOAT8 / COUNT(*) AS avg_txs
 		FROM blocks
+		WHERE is_mainchain = TRUE
 		GROUP BY window_start
-		ORDER BY window_start DESC
-		LIMIT $2 OFFSET $3;`
-
-	SelectBlocksTimeListingByLimit = `SELECT date_trunc($1, time) as index_value,
-		MAX(height),
-		SUM(num_rtx) AS txs,
-		SUM(fresh_stake) AS tickets,
-		SUM(voters) AS votes,
-		SUM(revocations) AS revocations,
-		SUM(size) AS size,
-		COUNT(*) AS blocks_count,
-		MIN(time) AS start_time,
-		MAX(time) AS end_time
+		ORDER BY window_start;`
+
+	// SelectBusiestBlock selects the hash, height and number of transactions of
+	// the mainchain block with the most transactions with a height in [$1, $2].
+	// Ties are resolved in favor of the lowest block.
+	SelectBusiestBlock = `SELECT hash, height, numtx
+		FROM blocks
+		WHERE is_mainchain = TRUE AND height BETWEEN $1 AND $2
+		ORDER BY numtx DESC, height
+		LIMIT 1;`
+
+	// SelectBlockFullnessPerWindow selects, for each window of $1 blocks, the
+	// fraction of mainchain blocks with a size greater than $2 bytes.
+	SelectBlockFullnessPerWindow = `SELECT (height/$1)*$1 AS window_start,
+			COUNT(CASE WHEN size > $2 THEN 1 ELSE NULL END)::FLOAT8 / COUNT(*) AS fraction_full
 		FROM blocks
-		GROUP BY index_value
-		ORDER BY index_value DESC
-		LIMIT $2 OFFSET $3;`
+		WHERE is_mainchain = TRUE
+		GROUP BY window_start
+		ORDER BY window_start;`
+
+	// SelectBlocksTimeListingByLimit selects a page of blocks grouped by the
+	// time interval $1. The total fees and size of the mainchain transactions
+	// in each interval, excluding coinbase transactions, are aggregated over
+	// the times spanned by the selected intervals only.
+	SelectBlocksTimeListingByLimit = `WITH intervals AS (
+			SELECT date_trunc($1, time) as index_value,
+			MAX(height) AS end_block,
+			SUM(num_rtx) AS txs,
+			SUM(fresh_stake) AS tickets,
+			SUM(voters) AS votes,
+			SUM(revocations) AS revocations,
+			SUM(size) AS size,
+			COUNT(*) AS blocks_count,
+			MIN(time) AS start_time,
+			MAX(time) AS end_time
+			FROM blocks
+			GROUP BY index_value
+			ORDER BY index_value DESC
+			LIMIT $2 OFFSET $3
+		), interval_fees AS (
+			SELECT date_trunc($1, block_time) AS index_value,
+			SUM(fees) AS fees,
+			SUM(size) AS tx_size
+			FROM transactions
+			WHERE is_mainchain = true AND NOT (tree = 0 AND block_index = 0)
+				AND block_time >= (SELECT MIN(start_time) FROM intervals)
+				AND block_time <= (SELECT MAX(end_time) FROM intervals)
+			GROUP BY 1
+		)
+		SELECT intervals.index_value, end_block, txs, tickets, votes,
+			revocations, size, blocks_count, start_time, end_time,
+			COALESCE(interval_fees.fees, 0), COALESCE(interval_fees.tx_size, 0)
+		FROM intervals
+		LEFT JOIN interval_fees ON interval_fees.index_value = intervals.index_value
+		ORDER BY intervals.index_value DESC;`
 
 	SelectBlocksBlockSize = `SELECT time, size, numtx, height FROM blocks ORDER BY time;`
 
@@ -149,6 +259,25 @@ const (
 
 	SelectBlockVoteCount = `SELECT voters FROM blocks WHERE hash = $1;`
 
+	// SelectBlockVotersByHeight selects the number of votes in the mainchain
+	// block at height $1.
+	SelectBlockVotersByHeight = `SELECT voters FROM blocks
+		WHERE height = $1 AND is_mainchain = true;`
+
+	SelectBlockSBitsByHeight = `SELECT sbits FROM blocks WHERE height = $1 AND is_mainchain = true;`
+
+	// SelectBlockDifficultyByHeight selects the proof-of-work difficulty of
+	// the mainchain block at height $1.
+	SelectBlockDifficultyByHeight = `SELECT difficulty FROM blocks
+		WHERE height = $1 AND is_mainchain = true;`
+
+	// SelectCumulativeTxCountAtHeight sums the transaction counts of all
+	// mainchain blocks up to and including height $1. This scans every block
+	// below the height, so the result is best cached by the caller.
+	SelectCumulativeTxCountAtHeight = `SELECT COALESCE(SUM(numtx), 0)
+		FROM blocks
+		WHERE is_mainchain = true AND height <= $1;`
+
 	SelectSideChainBlocks = `SELECT is_valid, height, previous_hash, hash, block_chain.next_hash
 		FROM blocks
 		JOIN block_chain ON this_hash=hash
@@ -161,6 +290,25 @@ const (
 		WHERE is_mainchain = FALSE AND block_chain.next_hash=''
 		ORDER BY height DESC;`
 
+	// SelectSideChainDepths selects the hash of each side chain tip and the
+	// number of consecutive side chain blocks leading to it from the last
+	// mainchain block, by walking back along previous_hash.
+	SelectSideChainDepths = `WITH RECURSIVE side_chain AS (
+			SELECT hash AS tip_hash, previous_hash, 1 AS depth
+			FROM blocks
+			JOIN block_chain ON this_hash=hash
+			WHERE is_mainchain = FALSE AND block_chain.next_hash=''
+		UNION ALL
+			SELECT side_chain.tip_hash, blocks.previous_hash, side_chain.depth + 1
+			FROM side_chain
+			JOIN blocks ON blocks.hash = side_chain.previous_hash
+			WHERE blocks.is_mainchain = FALSE
+		)
+		SELECT tip_hash, MAX(depth) AS depth
+		FROM side_chain
+		GROUP BY tip_hash
+		ORDER BY depth DESC, tip_hash;`
+
 	SelectBlockStatus = `SELECT is_valid, is_mainchain, height, previous_hash, hash, block_chain.next_hash
 		FROM blocks
 		JOIN block_chain ON this_hash=hash
@@ -204,6 +352,47 @@ const (
 	// Grab the timestamp and chainwork.
 	SelectChainWork = `SELECT time, chainwork FROM blocks WHERE is_mainchain = true ORDER BY time;`
 
+	// SelectBlockTimesByHeightRange selects the height and time of the
+	// mainchain blocks in a height range, including the block preceding the
+	// range so that the first block in the range has a predecessor.
+	SelectBlockTimesByHeightRange = `SELECT height, time FROM blocks
+		WHERE height BETWEEN $1 - 1 AND $2 AND is_mainchain = true
+		ORDER BY height;`
+
+	// SelectBlockTimeQuantiles selects the 50th, 90th and 99th percentiles of
+	// the seconds between consecutive mainchain blocks with heights in the
+	// range [$1, $2]. The block preceding the range is included so that the
+	// first block in the range has a predecessor. The percentiles are zero if
+	// there are no intervals.
+	SelectBlockTimeQuantiles = `WITH gaps AS (
+			SELECT height,
+				EXTRACT(EPOCH FROM time - LAG(time) OVER (ORDER BY height)) AS gap
+			FROM blocks
+			WHERE height BETWEEN $1 - 1 AND $2 AND is_mainchain = true
+		)
+		SELECT COALESCE(percentile_cont(0.5) WITHIN GROUP (ORDER BY gap), 0),
+			COALESCE(percentile_cont(0.9) WITHIN GROUP (ORDER BY gap), 0),
+			COALESCE(percentile_cont(0.99) WITHIN GROUP (ORDER BY gap), 0)
+		FROM gaps
+		WHERE height >= $1 AND gap IS NOT NULL;`
+
+	// SelectMaxBlockGap selects the largest number of seconds between
+	// consecutive mainchain blocks with heights in the range [$1, $2], and the
+	// height of the block ending that interval. The earliest height is chosen
+	// for ties. The block preceding the range is included so that the first
+	// block in the range has a predecessor.
+	SelectMaxBlockGap = `WITH gaps AS (
+			SELECT height,
+				EXTRACT(EPOCH FROM time - LAG(time) OVER (ORDER BY height))::INT8 AS gap
+			FROM blocks
+			WHERE height BETWEEN $1 - 1 AND $2 AND is_mainchain = true
+		)
+		SELECT gap, height
+		FROM gaps
+		WHERE height >= $1 AND gap IS NOT NULL
+		ORDER BY gap DESC, height
+		LIMIT 1;`
+
 	// TODO: index block_chain where needed
 )
 
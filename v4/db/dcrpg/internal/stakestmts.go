@@ -100,6 +100,39 @@ const (
 	SelectTicketsTxDbIDsInBlock = `SELECT purchase_tx_db_id FROM tickets WHERE block_hash = $1;`
 	SelectTicketsForAddress     = `SELECT * FROM tickets WHERE stakesubmission_address = $1;`
 
+	// SelectSplitTicketsPerWindow counts, for each window of $1 blocks, the
+	// mainchain split tickets, those purchased with multiple inputs.
+	SelectSplitTicketsPerWindow = `SELECT (block_height/$1)*$1 AS window_start,
+			COUNT(CASE WHEN is_split AND num_inputs > 1 THEN 1 ELSE NULL END) AS splits
+		FROM tickets
+		WHERE is_mainchain = TRUE
+		GROUP BY window_start
+		ORDER BY window_start;`
+
+	// SelectMultisigTicketCount counts the mainchain tickets purchased to
+	// multisig (e.g. stake pool) addresses.
+	SelectMultisigTicketCount = `SELECT COUNT(*) FROM tickets
+		WHERE is_multisig = TRUE AND is_mainchain = TRUE;`
+
+	// SelectUniqueVotersPerWindow counts, for each window of $1 blocks, the
+	// distinct stake submission addresses of the tickets that cast mainchain
+	// votes in the window.
+	SelectUniqueVotersPerWindow = `SELECT (votes.height/$1)*$1 AS window_start,
+			COUNT(DISTINCT tickets.stakesubmission_address) AS voters
+		FROM votes
+		JOIN tickets ON tickets.tx_hash = votes.ticket_hash
+		WHERE votes.is_mainchain = TRUE
+		GROUP BY window_start
+		ORDER BY window_start;`
+
+	// SelectAddressStakingActivity counts the mainchain tickets with stake
+	// submission address $1, and the mainchain votes cast by those tickets.
+	SelectAddressStakingActivity = `SELECT COUNT(DISTINCT tickets.id), COUNT(votes.id)
+		FROM tickets
+		LEFT JOIN votes ON votes.ticket_hash = tickets.tx_hash
+			AND votes.is_mainchain = TRUE
+		WHERE tickets.stakesubmission_address = $1 AND tickets.is_mainchain = TRUE;`
+
 	forTxHashMainchainFirst    = ` WHERE tx_hash = $1 ORDER BY is_mainchain DESC;`
 	SelectTicketIDHeightByHash = `SELECT id, block_height FROM tickets` + forTxHashMainchainFirst
 	SelectTicketIDByHash       = `SELECT id FROM tickets` + forTxHashMainchainFirst
@@ -126,6 +159,12 @@ const (
 		WHERE pool_status = 0 AND tickets.is_mainchain = TRUE
 		GROUP BY timestamp ORDER BY timestamp;`
 
+	// SelectTicketsAvgPriceByBlock selects the average price of the tickets
+	// purchased in each mainchain block.
+	SelectTicketsAvgPriceByBlock = `SELECT block_height, AVG(price)
+		FROM tickets WHERE is_mainchain = TRUE
+		GROUP BY block_height ORDER BY block_height;`
+
 	SelectTicketSpendTypeByBlock = `SELECT block_height, 
 		SUM(CASE WHEN spend_type = 0 THEN 1 ELSE 0 END) as unspent,
 		SUM(CASE WHEN spend_type = 1 THEN 1 ELSE 0 END) as revoked
@@ -139,9 +178,55 @@ const (
 	SetTicketSpendingInfoForTicketDbID = `UPDATE tickets
 		SET spend_type = $4, spend_height = $2, spend_tx_db_id = $3, pool_status = $5
 		WHERE id = $1;`
+	// SetTicketSpendingInfoForTicketDbIDs is a bulk version of
+	// SetTicketSpendingInfoForTicketDbID taking parallel arrays of the ticket
+	// row IDs, spend heights, spending tx row IDs, spend types and pool
+	// statuses.
+	SetTicketSpendingInfoForTicketDbIDs = `UPDATE tickets
+		SET spend_type = s.spend_type, spend_height = s.spend_height,
+			spend_tx_db_id = s.spend_tx_db_id, pool_status = s.pool_status
+		FROM unnest($1::INT8[], $2::INT4[], $3::INT8[], $4::INT2[], $5::INT2[])
+			AS s(id, spend_height, spend_tx_db_id, spend_type, pool_status)
+		WHERE tickets.id = s.id;`
 	SetTicketSpendingInfoForTxDbID = `UPDATE tickets
 		SET spend_type = $4, spend_height = $2, spend_tx_db_id = $3, pool_status = $5
 		WHERE purchase_tx_db_id = $1;`
+	// SelectLiveTicketAgeBuckets assigns the ages (blocks since purchase as of
+	// height $1) of the mainchain tickets with pool status $2 to $3 equal width
+	// buckets spanning the observed ages, and counts the tickets in each
+	// bucket. The lower and upper (exclusive) bounds of the age range are also
+	// returned with each bucket.
+	SelectLiveTicketAgeBuckets = `WITH ages AS (
+			SELECT $1 - block_height AS age
+			FROM tickets
+			WHERE is_mainchain = TRUE AND pool_status = $2 AND block_height <= $1
+		), bounds AS (
+			SELECT MIN(age) AS lo, MAX(age) + 1 AS hi FROM ages
+		)
+		SELECT width_bucket(age, lo, hi, $3) AS bucket, lo, hi, count(*)
+		FROM ages, bounds
+		GROUP BY bucket, lo, hi
+		ORDER BY bucket;`
+
+	// SelectTicketPoolValueAndSizeAtHeight selects the total price and number
+	// of the mainchain tickets mined in the range [$2, $3] that were neither
+	// spent (voted or revoked) nor missed by height $1. A missed ticket leaves
+	// the pool, but its spend_height is not set until it is revoked, so the
+	// mainchain misses are checked too. The caller computes the range from the
+	// ticket maturity and expiry, e.g. to select the tickets that were live at
+	// height $1.
+	SelectTicketPoolValueAndSizeAtHeight = `SELECT COALESCE(SUM(price), 0), COUNT(*)
+		FROM tickets
+		WHERE is_mainchain = TRUE
+			AND block_height BETWEEN $2 AND $3
+			AND (spend_height IS NULL OR spend_height > $1)
+			AND NOT EXISTS (
+				SELECT 1
+				FROM misses
+				JOIN blocks ON blocks.hash = misses.block_hash AND blocks.is_mainchain
+				WHERE misses.ticket_hash = tickets.tx_hash AND misses.height <= $1
+			);`
+
 	SetTicketPoolStatusForTicketDbID = `UPDATE tickets SET pool_status = $2 WHERE id = $1;`
 	SetTicketPoolStatusForHash       = `UPDATE tickets SET pool_status = $2 WHERE tx_hash = $1;`
 
@@ -248,6 +333,28 @@ const (
 	SelectAllVoteDbIDsHeightsTicketHashes = `SELECT id, height, ticket_hash FROM votes;`
 	SelectAllVoteDbIDsHeightsTicketDbIDs  = `SELECT id, height, ticket_tx_db_id FROM votes;`
 
+	// SelectTicketVoteIntervals counts the mainchain votes by the number of
+	// blocks between the purchase of the spent ticket and the vote.
+	SelectTicketVoteIntervals = `SELECT votes.height - tickets.block_height AS blocks,
+			COUNT(*)
+		FROM votes
+		JOIN tickets ON tickets.id = votes.ticket_tx_db_id
+		WHERE votes.is_mainchain = TRUE
+		GROUP BY blocks
+		ORDER BY blocks;`
+
+	// SelectContestedBlocks selects the mainchain blocks with heights in
+	// [$1, $2] containing both votes approving and votes disapproving the
+	// previous block, along with the numbers of each.
+	SelectContestedBlocks = `SELECT block_hash,
+			COUNT(CASE WHEN block_valid THEN 1 ELSE NULL END) AS approve,
+			COUNT(CASE WHEN NOT block_valid THEN 1 ELSE NULL END) AS disapprove
+		FROM votes
+		WHERE is_mainchain = true AND height BETWEEN $1 AND $2
+		GROUP BY block_hash, height
+		HAVING bool_or(block_valid) AND NOT bool_and(block_valid)
+		ORDER BY height;`
+
 	UpdateVotesMainchainAll = `UPDATE votes
 		SET is_mainchain=b.is_mainchain
 		FROM (
@@ -320,6 +427,24 @@ const (
 
 	SelectMissesInBlock = `SELECT ticket_hash FROM misses WHERE block_hash = $1;`
 
+	// SelectMissesWithAddressesInBlock selects the hashes of the tickets that
+	// were called to vote in block $1 but missed, and the stakesubmission
+	// addresses of the mainchain tickets. The address is empty if the ticket is
+	// not found.
+	SelectMissesWithAddressesInBlock = `SELECT misses.ticket_hash,
+			COALESCE(tickets.stakesubmission_address, '')
+		FROM misses
+		LEFT JOIN tickets ON tickets.tx_hash = misses.ticket_hash
+			AND tickets.is_mainchain = TRUE
+		WHERE misses.block_hash = $1
+		ORDER BY misses.ticket_hash;`
+
+	// SelectMissesForTicket selects the heights of the blocks in which ticket
+	// $1 was called to vote but missed.
+	SelectMissesForTicket = `SELECT height FROM misses
+		WHERE ticket_hash = $1
+		ORDER BY height;`
+
 	// agendas table
 
 	CreateAgendasTable = `CREATE TABLE IF NOT EXISTS agendas (
@@ -378,6 +503,40 @@ const (
 		  AND block_height <= (` + agendaLockinBlock + `)
 		GROUP BY block_height;`
 
+	// SelectAgendaVoteTallyInRange counts the yes and no votes for an agenda
+	// cast in mainchain blocks with height in the range [$4, $5]. The agendas
+	// table does not track side chain votes, so the votes are joined.
+	SelectAgendaVoteTallyInRange = `SELECT
+			count(CASE WHEN agendas.agenda_vote_choice = $1 THEN 1 ELSE NULL END) AS yes,
+			count(CASE WHEN agendas.agenda_vote_choice = $2 THEN 1 ELSE NULL END) AS no
+		FROM agendas
+		JOIN votes ON votes.tx_hash = agendas.tx_hash
+			AND votes.height = agendas.block_height AND votes.is_mainchain
+		WHERE agendas.agenda_id = $3 AND agendas.block_height BETWEEN $4 AND $5;`
+
+	// SelectAgendaVoteCountInRange counts the votes of any choice for agenda
+	// $1 cast in mainchain blocks with height in the range [$2, $3]. As with
+	// SelectAgendaVoteTallyInRange, the votes are joined to exclude side chain
+	// votes.
+	SelectAgendaVoteCountInRange = `SELECT count(*)
+		FROM agendas
+		JOIN votes ON votes.tx_hash = agendas.tx_hash
+			AND votes.height = agendas.block_height AND votes.is_mainchain
+		WHERE agendas.agenda_id = $1 AND agendas.block_height BETWEEN $2 AND $3;`
+
+	// SelectAgendaVoteTallyByBlock counts the yes and no votes for an agenda
+	// cast in each mainchain block with height in the range [$4, $5]. Blocks
+	// without any votes on the agenda are included with zero counts.
+	SelectAgendaVoteTallyByBlock = `SELECT blocks.height,
+			count(CASE WHEN agendas.agenda_vote_choice = $1 THEN 1 ELSE NULL END) AS yes,
+			count(CASE WHEN agendas.agenda_vote_choice = $2 THEN 1 ELSE NULL END) AS no
+		FROM blocks
+		LEFT JOIN votes ON votes.block_hash = blocks.hash AND votes.is_mainchain
+		LEFT JOIN agendas ON agendas.tx_hash = votes.tx_hash AND agendas.agenda_id = $3
+		WHERE blocks.is_mainchain AND blocks.height BETWEEN $4 AND $5
+		GROUP BY blocks.height
+		ORDER BY blocks.height;`
+
 	SelectAgendasLockedIn   = `SELECT block_height FROM agendas WHERE locked_in = true AND agenda_id = $1 LIMIT 1;`
 	SelectAgendasHardForked = `SELECT block_height FROM agendas WHERE hard_forked = true AND agenda_id = $1 LIMIT 1;`
 	SelectAgendasActivated  = `SELECT block_height FROM agendas WHERE activated = true AND agenda_id = $1 LIMIT 1;`
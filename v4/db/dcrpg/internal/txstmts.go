@@ -102,15 +102,107 @@ const (
 	SelectTxsByBlockHash = `SELECT id, tx_hash, block_index, tree, block_time
 		FROM transactions WHERE block_hash = $1;`
 
+	// SelectTxsByBlockHeight selects the transactions of the mainchain block at
+	// height $1. Side chain blocks at the same height are ignored.
+	SelectTxsByBlockHeight = `SELECT id, tx_hash, block_index, tree, block_time
+		FROM transactions WHERE block_height = $1 AND is_mainchain = true;`
+
 	SelectTxBlockTimeByHash = `SELECT block_time
 		FROM transactions
 		WHERE tx_hash = $1
 		ORDER BY is_mainchain DESC, is_valid DESC, block_time DESC
 		LIMIT 1;`
 
+	SelectTxsByVersionInHeightRange = `SELECT tx_hash FROM transactions
+		WHERE version = $1 AND block_height BETWEEN $2 AND $3 AND is_mainchain = true
+		ORDER BY block_height, block_index
+		LIMIT $4;`
+
+	// SelectMaxFeeTx selects the mainchain transaction paying the largest fee.
+	// Negative fees and fees exceeding the total input amount are corrupt and
+	// ignored.
+	SelectMaxFeeTx = `SELECT tx_hash, fees, block_height FROM transactions
+		WHERE is_mainchain = true AND fees >= 0 AND fees <= spent
+		ORDER BY fees DESC, block_height
+		LIMIT 1;`
+
+	// SelectConsolidationTxs selects up to $4 mainchain transactions in blocks
+	// with heights in [$2, $3] that have at least $1 inputs and a single
+	// output. Coinbase transactions, the first in the regular tree, are
+	// excluded.
+	SelectConsolidationTxs = `SELECT tx_hash, num_vin FROM transactions
+		WHERE num_vin >= $1 AND num_vout = 1 AND is_mainchain = true
+			AND block_height BETWEEN $2 AND $3
+			AND NOT (tree = 0 AND block_index = 0)
+		ORDER BY num_vin DESC, block_height, block_index
+		LIMIT $4;`
+
+	// SelectRecentTxs selects the $1 most recent mainchain transactions, with
+	// the newest first.
+	SelectRecentTxs = `SELECT tx_hash, block_height, block_time FROM transactions
+		WHERE is_mainchain = true
+		ORDER BY block_time DESC, block_height DESC, tree DESC, block_index DESC
+		LIMIT $1;`
+
+	// SelectValueMismatchTxs selects the mainchain transactions in blocks with
+	// heights in [$1, $2] for which the total input amount (spent) less the
+	// total output amount (sent) differs from the fees by more than $3 atoms,
+	// along with the difference. Coinbase transactions, the first in the
+	// regular tree, and votes (tx_type 2), which have a stakebase input, are
+	// excluded.
+	SelectValueMismatchTxs = `SELECT tx_hash, spent - sent - fees AS mismatch
+		FROM transactions
+		WHERE block_height BETWEEN $1 AND $2 AND is_mainchain = true
+			AND NOT (tree = 0 AND block_index = 0) AND tx_type != 2
+			AND ABS(spent - sent - fees) > $3
+		ORDER BY block_height, tree, block_index;`
+
+	// SelectBlockSizeByTree selects, for each mainchain block with transactions
+	// and a height in [$1, $2], the total size of the regular tree and of the
+	// stake tree transactions.
+	SelectBlockSizeByTree = `SELECT block_height,
+			COALESCE(SUM(size) FILTER (WHERE tree = 0), 0),
+			COALESCE(SUM(size) FILTER (WHERE tree = 1), 0)
+		FROM transactions
+		WHERE block_height BETWEEN $1 AND $2 AND is_mainchain = true
+		GROUP BY block_height
+		ORDER BY block_height;`
+
+	// SelectCoinbaseValuePerBlock selects the height and total output value
+	// (sent) of the coinbase transaction, the first in the regular tree, of
+	// each mainchain block with height in [$1, $2].
+	SelectCoinbaseValuePerBlock = `SELECT block_height, sent
+		FROM transactions
+		WHERE block_height BETWEEN $1 AND $2 AND is_mainchain = true
+			AND tree = 0 AND block_index = 0
+		ORDER BY block_height;`
+
+	// SelectTimeLockedTxs selects the hashes and lock times of up to $3
+	// mainchain transactions with a nonzero lock time in blocks with heights
+	// in [$1, $2]. A NULL limit selects all such transactions.
+	SelectTimeLockedTxs = `SELECT tx_hash, lock_time
+		FROM transactions
+		WHERE block_height BETWEEN $1 AND $2 AND is_mainchain = true
+			AND lock_time != 0
+		ORDER BY block_height, tree, block_index
+		LIMIT $3;`
+
 	SelectTxsPerDay = `SELECT date_trunc('day',time) AS date, count(*) FROM transactions
 		GROUP BY date ORDER BY date;`
 
+	SelectFeesPerDay = `SELECT date_trunc('day',time) AS date, SUM(fees) FROM transactions
+		WHERE is_mainchain = true
+		GROUP BY date ORDER BY date;`
+
+	// SelectFeeRatePerDay selects the mean transaction fee rate, in atoms per
+	// byte, of each day's mainchain transactions. The coinbase transactions,
+	// the first in the regular tree of each block, pay no fees and are excluded.
+	SelectFeeRatePerDay = `SELECT date_trunc('day',time) AS date,
+			COALESCE(SUM(fees)::FLOAT8 / NULLIF(SUM(size), 0), 0)
+		FROM transactions
+		WHERE is_mainchain = true AND NOT (tree = 0 AND block_index = 0)
+		GROUP BY date ORDER BY date;`
+
 	SelectFullTxByHash = `SELECT id, block_hash, block_height, block_time, 
 		time, tx_type, version, tree, tx_hash, block_index, lock_time, expiry, 
 		size, spent, sent, fees, num_vin, vin_db_ids, num_vout, vout_db_ids,
@@ -222,6 +314,10 @@ var (
 		SUM(CASE WHEN num_vout = 5 THEN 1 ELSE 0 END) as pooled
 		FROM transactions WHERE tx_type = %d
 		GROUP BY count ORDER BY count;`, stake.TxTypeSStx)
+
+	SelectRevokesPerDay = fmt.Sprintf(`SELECT date_trunc('day',time) AS date, count(*)
+		FROM transactions WHERE tx_type = %d AND is_mainchain = true
+		GROUP BY date ORDER BY date;`, stake.TxTypeSSRtx)
 )
 
 // func makeTxInsertStatement(voutDbIDs, vinDbIDs []uint64, vouts []*dbtypes.Vout, checked bool) string {
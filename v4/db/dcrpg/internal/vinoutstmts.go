@@ -42,6 +42,11 @@ const (
 			prev_tx_hash = $4, prev_tx_index = $5, prev_tx_tree = $6
 		RETURNING id;`
 
+	// SelectVinsNextIDs reserves $1 ids from the vins table's id sequence.
+	// This allows COPY, which cannot return the ids of the rows it creates, to
+	// be used to insert rows with known ids.
+	SelectVinsNextIDs = `SELECT nextval('vins_id_seq') FROM generate_series(1, $1);`
+
 	// InsertVinRowOnConflictDoNothing allows an INSERT with a DO NOTHING on
 	// conflict with vins' unique tx index, while returning the row id of either
 	// the inserted row or the existing row that causes the conflict. The
@@ -75,8 +80,15 @@ const (
 		ON vins(prev_tx_hash, prev_tx_index);`
 	DeindexVinTableOnPrevOuts = `DROP INDEX uix_vin_prevout;`
 
-	SelectVinIDsALL = `SELECT id FROM vins;`
-	CountVinsRows   = `SELECT reltuples::BIGINT AS estimate FROM pg_class WHERE relname='vins';`
+	CountVinsRows = `SELECT reltuples::BIGINT AS estimate FROM pg_class WHERE relname='vins';`
+
+	// SelectVinIDsRange selects the row IDs of the vins table in the inclusive
+	// range [$1, $2].
+	SelectVinIDsRange = `SELECT id FROM vins WHERE id BETWEEN $1 AND $2 ORDER BY id;`
+
+	// SelectMaxVinID selects the largest row ID in the vins table, or 0 if the
+	// table is empty.
+	SelectMaxVinID = `SELECT COALESCE(MAX(id), 0) FROM vins;`
 
 	SetTxTypeOnVinsByVinIDs = `UPDATE vins SET tx_type=$1 WHERE id=$2;`
 
@@ -89,11 +101,31 @@ const (
 		WHERE prev_tx_hash=$1 AND vins.is_valid=TRUE AND vins.is_mainchain=TRUE;`
 	SelectSpendingTxByPrevOut = `SELECT id, tx_hash, tx_index, tx_tree FROM vins
 		WHERE prev_tx_hash=$1 AND prev_tx_index=$2 ORDER BY is_valid DESC, is_mainchain DESC, block_time DESC;`
-	SelectFundingTxsByTx        = `SELECT id, prev_tx_hash FROM vins WHERE tx_hash=$1;`
 	SelectFundingTxByTxIn       = `SELECT id, prev_tx_hash FROM vins WHERE tx_hash=$1 AND tx_index=$2;`
 	SelectFundingOutpointByTxIn = `SELECT id, prev_tx_hash, prev_tx_index, prev_tx_tree FROM vins
 		WHERE tx_hash=$1 AND tx_index=$2;`
 
+	// SelectFundingTxsByTx selects, for each input of the transaction with
+	// hash $1, the vins row ID and the transactions row of the previous
+	// (funding) transaction. When the funding transaction is in several
+	// blocks, the row for a mainchain and valid block is preferred. Inputs
+	// with no funding transaction in the table, such as stakebase inputs, are
+	// omitted.
+	SelectFundingTxsByTx = `SELECT DISTINCT ON (vins.id) vins.id,
+			transactions.block_hash, transactions.block_height,
+			transactions.block_time, transactions.time, transactions.tx_type,
+			transactions.version, transactions.tree, transactions.tx_hash,
+			transactions.block_index, transactions.lock_time, transactions.expiry,
+			transactions.size, transactions.spent, transactions.sent,
+			transactions.fees, transactions.num_vin, transactions.vin_db_ids,
+			transactions.num_vout, transactions.vout_db_ids,
+			transactions.is_valid, transactions.is_mainchain
+		FROM vins
+		JOIN transactions ON transactions.tx_hash = vins.prev_tx_hash
+		WHERE vins.tx_hash = $1
+		ORDER BY vins.id, transactions.is_mainchain DESC,
+			transactions.is_valid DESC, transactions.block_time DESC;`
+
 	SelectFundingOutpointByVinID     = `SELECT prev_tx_hash, prev_tx_index, prev_tx_tree FROM vins WHERE id=$1;`
 	SelectFundingOutpointIndxByVinID = `SELECT prev_tx_index FROM vins WHERE id=$1;`
 	SelectFundingTxByVinID           = `SELECT prev_tx_hash FROM vins WHERE id=$1;`
@@ -104,6 +136,16 @@ const (
 
 	SetIsValidIsMainchainByTxHash = `UPDATE vins SET is_valid = $1, is_mainchain = $2
 		WHERE tx_hash = $3 AND block_time = $4 AND tx_tree = $5;`
+
+	// SetIsValidIsMainchainByBlockHash sets is_valid and is_mainchain for the
+	// vins of all transactions in the block with hash $3, matching the vins
+	// by transaction hash, block time, and tree.
+	SetIsValidIsMainchainByBlockHash = `UPDATE vins SET is_valid = $1, is_mainchain = $2
+		FROM transactions
+		WHERE transactions.block_hash = $3
+			AND vins.tx_hash = transactions.tx_hash
+			AND vins.block_time = transactions.block_time
+			AND vins.tx_tree = transactions.tree;`
 	SetIsValidIsMainchainByVinID = `UPDATE vins SET is_valid = $2, is_mainchain = $3
 		WHERE id = $1;`
 	SetIsValidByTxHash = `UPDATE vins SET is_valid = $1
@@ -209,9 +251,57 @@ const (
 	SelectVoutIDByOutpoint = `SELECT id FROM vouts WHERE tx_hash=$1 and tx_index=$2;`
 	SelectVoutByID         = `SELECT * FROM vouts WHERE id=$1;`
 
+	// SelectVoutsByTxHash selects the vouts of the transaction with hash $1,
+	// ordered by output index. The vouts are those of the transactions row
+	// for a mainchain and valid block, if the transaction is in several
+	// blocks.
+	SelectVoutsByTxHash = `WITH tx AS (
+			SELECT vout_db_ids FROM transactions
+			WHERE tx_hash = $1
+			ORDER BY is_mainchain DESC, is_valid DESC, block_time DESC
+			LIMIT 1
+		)
+		SELECT vouts.tx_hash, vouts.tx_index, vouts.tx_tree, vouts.value,
+			vouts.version, vouts.pkscript, vouts.script_req_sigs,
+			vouts.script_type, vouts.script_addresses
+		FROM tx
+		JOIN vouts ON vouts.id = ANY(tx.vout_db_ids)
+		ORDER BY vouts.tx_index;`
+
 	RetrieveVoutValue  = `SELECT value FROM vouts WHERE tx_hash=$1 and tx_index=$2;`
 	RetrieveVoutValues = `SELECT value, tx_index, tx_tree FROM vouts WHERE tx_hash=$1;`
 
+	// SelectSpendDepthBuckets assigns the spend depths (spending block height
+	// minus funding block height) of the outputs spent by valid mainchain
+	// inputs in blocks with heights in [$1, $2] to $3 equal width buckets
+	// spanning the observed depths, and counts the spent outputs in each
+	// bucket. The lower and upper (exclusive) bounds of the depth range are
+	// also returned with each bucket.
+	SelectSpendDepthBuckets = `WITH depths AS (
+			SELECT spending.block_height - funding.block_height AS depth
+			FROM vins
+			JOIN transactions AS spending ON spending.tx_hash = vins.tx_hash
+				AND spending.is_mainchain = TRUE
+			JOIN transactions AS funding ON funding.tx_hash = vins.prev_tx_hash
+				AND funding.is_mainchain = TRUE
+			WHERE vins.is_valid = TRUE AND vins.is_mainchain = TRUE
+				AND spending.block_height BETWEEN $1 AND $2
+		), bounds AS (
+			SELECT MIN(depth) AS lo, MAX(depth) + 1 AS hi FROM depths
+		)
+		SELECT width_bucket(depth, lo, hi, $3) AS bucket, lo, hi, count(*)
+		FROM depths, bounds
+		GROUP BY bucket, lo, hi
+		ORDER BY bucket;`
+
+	// SelectUnspendableValue sums the value of the provably-unspendable outputs
+	// (nulldata and nonstandard scripts) of valid mainchain transactions.
+	SelectUnspendableValue = `SELECT COALESCE(SUM(vouts.value), 0)
+		FROM vouts
+		JOIN transactions ON transactions.tx_hash = vouts.tx_hash
+		WHERE vouts.script_type IN ('nulldata', 'nonstandard')
+			AND transactions.is_mainchain = TRUE AND transactions.is_valid = TRUE;`
+
 	CreateVoutType = `CREATE TYPE vout_t AS (
 		value INT8,
 		version INT2,
@@ -246,7 +336,8 @@ var (
 		"tx_hash", "tx_index", "tx_tree", "value", "version",
 		"pkscript", "script_req_sigs", " script_type", "script_addresses")
 	vinCopyStmt = pq.CopyIn("vins",
-		"tx_hash", "tx_index", "prev_tx_hash", "prev_tx_index")
+		"id", "tx_hash", "tx_index", "tx_tree", "prev_tx_hash", "prev_tx_index",
+		"prev_tx_tree", "value_in", "is_valid", "is_mainchain", "block_time", "tx_type")
 )
 
 func MakeVoutCopyInStatement() string {
@@ -38,6 +38,11 @@ var (
 	zeroHashStringBytes = []byte(chainhash.Hash{}.String())
 )
 
+// DefaultHashrateWindow is the default number of blocks over which the network
+// hashrate chart data is averaged. 120 is the default used by the RPC method
+// `getnetworkhashps`.
+const DefaultHashrateWindow = 120
+
 // DevFundBalance is a block-stamped wrapper for dbtypes.AddressBalance. It is
 // intended to be used for the project address.
 type DevFundBalance struct {
@@ -201,6 +206,8 @@ type ChainDB struct {
 	bestBlock          *BestBlock
 	lastBlock          map[chainhash.Hash]uint64
 	addressCounts      *addressCounter
+	txCounts           *txCountCache
+	richList           *richListCache
 	stakeDB            *stakedb.StakeDatabase
 	unspentTicketCache *TicketTxnIDGetter
 	DevFundBalance     *DevFundBalance
@@ -209,6 +216,7 @@ type ChainDB struct {
 	InReorg            bool
 	tpUpdatePermission map[dbtypes.TimeBasedGrouping]*trylock.Mutex
 	utxoCache          utxoStore
+	hashrateWindow     int
 }
 
 // BestBlock is mutex-protected block hash and height.
@@ -356,6 +364,100 @@ func makeAddressCounter() *addressCounter {
 	}
 }
 
+// txCountCacheSize is the maximum number of cumulative transaction counts held
+// by the txCountCache before it is cleared.
+const txCountCacheSize = 1000
+
+// txCountCache provides a cache for the cumulative mainchain transaction counts
+// at blocks, keyed by block hash. The count at a mainchain block depends only
+// on the block and its ancestors, so cached counts do not go stale.
+type txCountCache struct {
+	sync.Mutex
+	counts map[string]int64
+}
+
+func newTxCountCache() *txCountCache {
+	return &txCountCache{
+		counts: make(map[string]int64),
+	}
+}
+
+// get retrieves the cached count for the block with the given hash.
+func (c *txCountCache) get(hash string) (int64, bool) {
+	c.Lock()
+	defer c.Unlock()
+	count, ok := c.counts[hash]
+	return count, ok
+}
+
+// set stores the count for the block with the given hash, first clearing the
+// cache if it is full.
+func (c *txCountCache) set(hash string, count int64) {
+	c.Lock()
+	defer c.Unlock()
+	if len(c.counts) >= txCountCacheSize {
+		c.counts = make(map[string]int64)
+	}
+	c.counts[hash] = count
+}
+
+// richListCacheTTL is the longest time that a page of addresses ordered by
+// balance is served from the richListCache, even if the best block has not
+// changed.
+const richListCacheTTL = 5 * time.Minute
+
+// richListPage identifies a page of addresses ordered by balance.
+type richListPage struct {
+	limit, offset int
+}
+
+// richListEntry is a cached page of addresses ordered by balance.
+type richListEntry struct {
+	rows    []dbtypes.AddressBalanceRow
+	fetched time.Time
+}
+
+// richListCache provides a cache for pages of addresses ordered by balance,
+// valid for a certain best block height.
+type richListCache struct {
+	sync.Mutex
+	height int64
+	pages  map[richListPage]richListEntry
+}
+
+func newRichListCache() *richListCache {
+	return &richListCache{
+		pages: make(map[richListPage]richListEntry),
+	}
+}
+
+// get retrieves the cached page for the best block height. The page is only
+// returned if it was fetched within richListCacheTTL of now.
+func (c *richListCache) get(height int64, page richListPage, now time.Time) ([]dbtypes.AddressBalanceRow, bool) {
+	c.Lock()
+	defer c.Unlock()
+	if c.height != height {
+		return nil, false
+	}
+	entry, ok := c.pages[page]
+	if !ok || now.Sub(entry.fetched) > richListCacheTTL {
+		return nil, false
+	}
+	return entry.rows, true
+}
+
+// set stores the page for the best block height, clearing any pages cached
+// for a different height.
+func (c *richListCache) set(height int64, page richListPage, rows []dbtypes.AddressBalanceRow, now time.Time) {
+	c.Lock()
+	defer c.Unlock()
+	if c.height != height {
+		c.height = height
+		c.pages = make(map[richListPage]richListEntry)
+	}
+	c.pages[page] = richListEntry{rows: rows, fetched: now}
+}
+
 // TicketTxnIDGetter provides a cache for DB row IDs of tickets.
 type TicketTxnIDGetter struct {
 	sync.RWMutex
@@ -512,12 +614,15 @@ func NewChainDBWithCancel(ctx context.Context, dbi *DBInfo, params *chaincfg.Par
 		bestBlock:          bestBlock,
 		lastBlock:          make(map[chainhash.Hash]uint64),
 		addressCounts:      makeAddressCounter(),
+		txCounts:           newTxCountCache(),
+		richList:           newRichListCache(),
 		stakeDB:            stakeDB,
 		unspentTicketCache: unspentTicketCache,
 		DevFundBalance:     new(DevFundBalance),
 		devPrefetch:        devPrefetch,
 		tpUpdatePermission: tpUpdatePermissions,
 		utxoCache:          newUtxoStore(2e4),
+		hashrateWindow:     DefaultHashrateWindow,
 	}, nil
 }
 
@@ -539,6 +644,16 @@ func (pgb *ChainDB) EnableDuplicateCheckOnInsert(dupCheck bool) {
 	pgb.dupChecks = dupCheck
 }
 
+// SetHashrateWindow sets the number of blocks over which the network hashrate
+// chart data is averaged. The window must be positive.
+func (pgb *ChainDB) SetHashrateWindow(blocks int) error {
+	if blocks < 1 {
+		return fmt.Errorf("invalid hashrate window %d", blocks)
+	}
+	pgb.hashrateWindow = blocks
+	return nil
+}
+
 // SetupTables creates the required tables and type, and prints table versions
 // stored in the table comments when debug level logging is enabled.
 func (pgb *ChainDB) SetupTables() error {
@@ -757,6 +872,15 @@ func (pgb *ChainDB) BlockHash(height int64) (string, error) {
 	return hash, pgb.replaceCancelError(err)
 }
 
+// BlocksPage retrieves a page of up to limit mainchain block summaries,
+// highest first, after skipping the offset highest blocks.
+func (pgb *ChainDB) BlocksPage(offset, limit int) ([]dbtypes.BlockDataBasic, error) {
+	ctx, cancel := context.WithTimeout(pgb.ctx, pgb.queryTimeout)
+	defer cancel()
+	blocks, err := RetrieveBlocksPage(ctx, pgb.db, offset, limit)
+	return blocks, pgb.replaceCancelError(err)
+}
+
 // VotesInBlock returns the number of votes mined in the block with the
 // specified hash.
 func (pgb *ChainDB) VotesInBlock(hash string) (int16, error) {
@@ -926,25 +1050,11 @@ func (pgb *ChainDB) AddressMetrics(addr string) (*dbtypes.AddressMetrics, error)
 // txnType transactions.
 func (pgb *ChainDB) AddressTransactions(address string, N, offset int64,
 	txnType dbtypes.AddrTxnType) (addressRows []*dbtypes.AddressRow, err error) {
-	var addrFunc func(context.Context, *sql.DB, string, int64, int64) ([]uint64, []*dbtypes.AddressRow, error)
-	switch txnType {
-	case dbtypes.AddrTxnCredit:
-		addrFunc = RetrieveAddressCreditTxns
-	case dbtypes.AddrTxnAll:
-		addrFunc = RetrieveAddressTxns
-	case dbtypes.AddrTxnDebit:
-		addrFunc = RetrieveAddressDebitTxns
-
-	case dbtypes.AddrMergedTxnDebit:
-		addrFunc = RetrieveAddressMergedDebitTxns
-	default:
-		return nil, fmt.Errorf("unknown AddrTxnType %v", txnType)
-	}
-
 	ctx, cancel := context.WithTimeout(pgb.ctx, pgb.queryTimeout)
 	defer cancel()
 
-	_, addressRows, err = addrFunc(ctx, pgb.db, address, N, offset)
+	_, addressRows, err = RetrieveAddressTxnsByType(ctx, pgb.db, address,
+		txnType, N, offset)
 	err = pgb.replaceCancelError(err)
 	return
 }
@@ -1268,6 +1378,28 @@ func (pgb *ChainDB) addressBalance(address string) (*dbtypes.AddressBalance, err
 	return &balanceInfo, nil
 }
 
+// TopAddressesByBalance retrieves a page of up to limit addresses, starting at
+// offset, in the list of addresses ordered by balance, largest first. Since
+// the query scans the entire addresses table, pages are cached for the current
+// best block for up to richListCacheTTL.
+func (pgb *ChainDB) TopAddressesByBalance(limit, offset int) ([]dbtypes.AddressBalanceRow, error) {
+	height := int64(pgb.bestBlock.Height())
+	page := richListPage{limit, offset}
+	if rows, ok := pgb.richList.get(height, page, time.Now()); ok {
+		return rows, nil
+	}
+
+	ctx, cancel := context.WithTimeout(pgb.ctx, pgb.queryTimeout)
+	defer cancel()
+	rows, err := RetrieveTopAddressesByBalance(ctx, pgb.db, limit, offset)
+	if err != nil {
+		return nil, pgb.replaceCancelError(err)
+	}
+
+	pgb.richList.set(height, page, rows, time.Now())
+	return rows, nil
+}
+
 // AddressHistory queries the database for rows of the addresses table
 // containing values for a certain type of transaction (all, credits, or debits)
 // for the given address.
@@ -1848,6 +1980,15 @@ func (pgb *ChainDB) TicketsPriceByHeight() (*dbtypes.ChartsData, error) {
 	return &dbtypes.ChartsData{Time: d.Time, ValueF: d.ValueF}, nil
 }
 
+// RollingAvgTicketPrice returns chart data for the moving average of the
+// ticket prices paid over the trailing window of windowBlocks blocks.
+func (pgb *ChainDB) RollingAvgTicketPrice(windowBlocks int64) (*dbtypes.ChartsData, error) {
+	ctx, cancel := context.WithTimeout(pgb.ctx, pgb.queryTimeout)
+	defer cancel()
+	d, err := retrieveRollingAvgTicketPrice(ctx, pgb.db, windowBlocks)
+	return d, pgb.replaceCancelError(err)
+}
+
 // TicketsByPrice returns chart data for tickets grouped by price. maturityBlock
 // is used to define when tickets are considered live.
 func (pgb *ChainDB) TicketsByPrice(maturityBlock int64) (*dbtypes.PoolTicketsData, error) {
@@ -1930,7 +2071,23 @@ func (pgb *ChainDB) GetPgChartsData() (map[string]*dbtypes.ChartsData, error) {
 		return nil, fmt.Errorf("retrieveTicketByOutputCount by All TP window: %v", err)
 	}
 
-	chainWork, hashrates, err := retrieveChainWork(pgb.db)
+	ctx, cancel = context.WithTimeout(pgb.ctx, pgb.queryTimeout)
+	feesPerDay, err := retrieveFeesPerDay(ctx, pgb.db)
+	cancel()
+	if err != nil {
+		err = pgb.replaceCancelError(err)
+		return nil, fmt.Errorf("retrieveFeesPerDay: %v", err)
+	}
+
+	ctx, cancel = context.WithTimeout(pgb.ctx, pgb.queryTimeout)
+	feeRatePerDay, err := retrieveFeeRatePerDay(ctx, pgb.db)
+	cancel()
+	if err != nil {
+		err = pgb.replaceCancelError(err)
+		return nil, fmt.Errorf("retrieveFeeRatePerDay: %v", err)
+	}
+
+	chainWork, hashrates, err := retrieveChainWork(pgb.db, pgb.hashrateWindow)
 	if err != nil {
 		return nil, fmt.Errorf("retrieveChainWork: %v", err)
 	}
@@ -1941,6 +2098,8 @@ func (pgb *ChainDB) GetPgChartsData() (map[string]*dbtypes.ChartsData, error) {
 		"tx-per-block":              {Value: size.Value, Count: size.Count},
 		"duration-btw-blocks":       {Value: size.Value, ValueF: size.ValueF},
 		"tx-per-day":                txRate,
+		"fees-per-day":              feesPerDay,
+		"fee-rate":                  feeRatePerDay,
 		"pow-difficulty":            {Time: tickets.Time, Difficulty: tickets.Difficulty},
 		"ticket-price":              {Time: tickets.Time, ValueF: tickets.ValueF},
 		"coin-supply":               supply,
@@ -2206,7 +2365,7 @@ func (pgb *ChainDB) StoreBlock(msgBlock *wire.MsgBlock, winningTickets []string,
 	// regular transactions
 	resChanReg := make(chan storeTxnsResult)
 	go func() {
-		resChanReg <- pgb.storeTxns(MsgBlockPG, wire.TxTreeRegular,
+		resChanReg <- pgb.storeTxns(pgb.ctx, MsgBlockPG, wire.TxTreeRegular,
 			pgb.chainParams, &dbBlock.TxDbIDs, isValid, isMainchain,
 			updateExistingRecords,
 			updateAddressesSpendingInfo, updateTicketsSpendingInfo)
@@ -2215,7 +2374,7 @@ func (pgb *ChainDB) StoreBlock(msgBlock *wire.MsgBlock, winningTickets []string,
 	// stake transactions
 	resChanStake := make(chan storeTxnsResult)
 	go func() {
-		resChanStake <- pgb.storeTxns(MsgBlockPG, wire.TxTreeStake,
+		resChanStake <- pgb.storeTxns(pgb.ctx, MsgBlockPG, wire.TxTreeStake,
 			pgb.chainParams, &dbBlock.STxDbIDs, isValid, isMainchain,
 			updateExistingRecords,
 			updateAddressesSpendingInfo, updateTicketsSpendingInfo)
@@ -2411,7 +2570,7 @@ type MsgBlockPG struct {
 }
 
 // storeTxns stores the transactions of a given block.
-func (pgb *ChainDB) storeTxns(msgBlock *MsgBlockPG, txTree int8,
+func (pgb *ChainDB) storeTxns(ctx context.Context, msgBlock *MsgBlockPG, txTree int8,
 	chainParams *chaincfg.Params, TxDbIDs *[]uint64, isValid, isMainchain bool,
 	updateExistingRecords, updateAddressesSpendingInfo, updateTicketsSpendingInfo bool) storeTxnsResult {
 	// For the given block, transaction tree, and network, extract the
@@ -2442,7 +2601,7 @@ func (pgb *ChainDB) storeTxns(msgBlock *MsgBlockPG, txTree int8,
 	for it, dbtx := range dbTransactions {
 		// Insert vouts, and collect AddressRows to add to address table for
 		// each output.
-		dbtx.VoutDbIds, dbAddressRows[it], err = InsertVouts(pgb.db,
+		dbtx.VoutDbIds, dbAddressRows[it], err = InsertVouts(ctx, pgb.db,
 			dbTxVouts[it], pgb.dupChecks, updateExistingRecords)
 		if err != nil && err != sql.ErrNoRows {
 			log.Error("InsertVouts:", err)
@@ -2456,7 +2615,7 @@ func (pgb *ChainDB) storeTxns(msgBlock *MsgBlockPG, txTree int8,
 		}
 
 		// Insert vins
-		dbtx.VinDbIds, err = InsertVins(pgb.db, dbTxVins[it], pgb.dupChecks,
+		dbtx.VinDbIds, err = InsertVins(ctx, pgb.db, dbTxVins[it], pgb.dupChecks,
 			updateExistingRecords)
 		if err != nil && err != sql.ErrNoRows {
 			log.Error("InsertVins:", err)
@@ -2472,7 +2631,7 @@ func (pgb *ChainDB) storeTxns(msgBlock *MsgBlockPG, txTree int8,
 	}
 
 	// Get the tx PK IDs for storage in the blocks, tickets, and votes table
-	*TxDbIDs, err = InsertTxns(pgb.db, dbTransactions, pgb.dupChecks,
+	*TxDbIDs, err = InsertTxns(ctx, pgb.db, dbTransactions, pgb.dupChecks,
 		updateExistingRecords)
 	if err != nil && err != sql.ErrNoRows {
 		log.Error("InsertTxns:", err)
@@ -2485,7 +2644,7 @@ func (pgb *ChainDB) storeTxns(msgBlock *MsgBlockPG, txTree int8,
 	// new votes, revokes, misses, and expires.
 	if txTree == wire.TxTreeStake {
 		// Tickets: Insert new (unspent) tickets
-		newTicketDbIDs, newTicketTx, err := InsertTickets(pgb.db, dbTransactions, *TxDbIDs,
+		newTicketDbIDs, newTicketTx, err := InsertTickets(ctx, pgb.db, dbTransactions, *TxDbIDs,
 			pgb.dupChecks, updateExistingRecords)
 		if err != nil && err != sql.ErrNoRows {
 			log.Error("InsertTickets:", err)
@@ -2788,68 +2947,79 @@ func (pgb *ChainDB) CollectTicketSpendDBInfo(dbTxns []*dbtypes.Tx, txDbIDs []uin
 // with storeTxns, which will update these addresses table columns too, but much
 // more slowly for a number of reasons (that are well worth investigating BTW!).
 func (pgb *ChainDB) UpdateSpendingInfoInAllAddresses(barLoad chan *dbtypes.ProgressBarLoad) (int64, error) {
-	// Get the full list of vinDbIDs
-	allVinDbIDs, err := RetrieveAllVinDbIDs(pgb.db)
+	// The queries in this function should not timeout, so use a background
+	// context.
+	ctx := context.Background()
+
+	// The vins table is processed in ranges of row IDs rather than loading
+	// every vin row ID at once.
+	maxVinDbID, err := RetrieveMaxVinDbID(ctx, pgb.db)
 	if err != nil {
-		log.Errorf("RetrieveAllVinDbIDs: %v", err)
+		log.Errorf("RetrieveMaxVinDbID: %v", err)
 		return 0, err
 	}
 
+	const idsPerRange = 100000
 	updatesPerDBTx := 1000
-	totalVinIbIDs := len(allVinDbIDs)
 
 	timeStart := time.Now()
 
-	log.Infof("Updating spending tx info for %d addresses...", totalVinIbIDs)
+	log.Infof("Updating spending tx info for vins with row IDs up to %d...", maxVinDbID)
 	var numAddresses int64
-	for i := 0; i < totalVinIbIDs; i += updatesPerDBTx {
-		if i%100000 == 0 {
-			endRange := i + 100000 - 1
-			if endRange > totalVinIbIDs {
-				endRange = totalVinIbIDs
-			}
-			log.Infof("Updating from vins %d to %d...", i, endRange)
+	for minID := uint64(1); minID <= maxVinDbID; minID += idsPerRange {
+		maxID := minID + idsPerRange - 1
+		if maxID > maxVinDbID {
+			maxID = maxVinDbID
 		}
+		log.Infof("Updating from vins %d to %d...", minID, maxID)
 
-		var numAddressRowsSet int64
-		endChunk := i + updatesPerDBTx
-		if endChunk > totalVinIbIDs {
-			endChunk = totalVinIbIDs
+		vinDbIDs, err := RetrieveVinDbIDsRange(ctx, pgb.db, minID, maxID)
+		if err != nil {
+			log.Errorf("RetrieveVinDbIDsRange: %v", err)
+			return numAddresses, err
 		}
 
-		if barLoad != nil {
-			// Full mode is definitely running so no need to check.
-			timeTakenPerBlock := (time.Since(timeStart).Seconds() / float64(endChunk-i))
-			barLoad <- &dbtypes.ProgressBarLoad{
-				From:      int64(i),
-				To:        int64(totalVinIbIDs),
-				Msg:       AddressesSyncStatusMsg,
-				BarID:     dbtypes.AddressesTableSync,
-				Timestamp: int64(timeTakenPerBlock * float64(totalVinIbIDs-endChunk)),
+		for i := 0; i < len(vinDbIDs); i += updatesPerDBTx {
+			endChunk := i + updatesPerDBTx
+			if endChunk > len(vinDbIDs) {
+				endChunk = len(vinDbIDs)
 			}
 
-			timeStart = time.Now()
-		}
+			if barLoad != nil {
+				// Full mode is definitely running so no need to check.
+				from := vinDbIDs[i]
+				timeTakenPerID := time.Since(timeStart).Seconds() / float64(updatesPerDBTx)
+				barLoad <- &dbtypes.ProgressBarLoad{
+					From:      int64(from),
+					To:        int64(maxVinDbID),
+					Msg:       AddressesSyncStatusMsg,
+					BarID:     dbtypes.AddressesTableSync,
+					Timestamp: int64(timeTakenPerID * float64(maxVinDbID-from)),
+				}
 
-		_, numAddressRowsSet, err = SetSpendingForVinDbIDs(pgb.db, allVinDbIDs[i:endChunk])
-		if err != nil {
-			log.Errorf("SetSpendingForVinDbIDs: %v", err)
-			continue
+				timeStart = time.Now()
+			}
+
+			_, numAddressRowsSet, err := SetSpendingForVinDbIDs(pgb.db, vinDbIDs[i:endChunk])
+			if err != nil {
+				log.Errorf("SetSpendingForVinDbIDs: %v", err)
+				continue
+			}
+			numAddresses += numAddressRowsSet
 		}
-		numAddresses += numAddressRowsSet
 	}
 
 	// Signal the completion of the sync to the status page.
 	if barLoad != nil {
 		barLoad <- &dbtypes.ProgressBarLoad{
-			From:  int64(totalVinIbIDs),
-			To:    int64(totalVinIbIDs),
+			From:  int64(maxVinDbID),
+			To:    int64(maxVinDbID),
 			Msg:   AddressesSyncStatusMsg,
 			BarID: dbtypes.AddressesTableSync,
 		}
 	}
 
-	return numAddresses, err
+	return numAddresses, nil
 }
 
 // UpdateSpendingInfoInAllTickets reviews all votes and revokes and sets this
@@ -3,27 +3,38 @@
 package dcrpg
 
 import (
+	"context"
+	"database/sql"
 	"fmt"
+	"math"
 	"os"
+	"reflect"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/davecgh/go-spew/spew"
+	"github.com/decred/dcrd/blockchain/stake"
 	"github.com/decred/dcrd/chaincfg"
 	"github.com/decred/dcrd/wire"
+	"github.com/decred/hcData/v4/db/dbtypes"
+	"github.com/decred/hcData/v4/db/dcrpg/internal"
+	"github.com/lib/pq"
 )
 
 var (
 	db *ChainDB
-)
 
-func openDB() (func() error, error) {
-	dbi := DBInfo{
+	dbi = DBInfo{
 		Host:   "localhost",
 		Port:   "5432",
 		User:   "dcrdata",
 		Pass:   "dcrdata",
 		DBName: "dcrdata",
 	}
+)
+
+func openDB() (func() error, error) {
 	var err error
 	db, err = NewChainDB(&dbi, &chaincfg.MainNetParams, nil, true)
 	cleanUp := func() error { return nil }
@@ -47,6 +58,73 @@ func TestMain(m *testing.M) {
 	os.Exit(retCode)
 }
 
+// openSeedDB opens a handle to the test database for tests that seed their own
+// rows. The handle has a single connection on which a transaction is started,
+// so the seeded rows are only visible to queries made with the handle, and the
+// returned function rolls the transaction back. Outside of that transaction the
+// connection is read-only, so nothing can be committed to the database, even if
+// the connection is lost and reopened.
+func openSeedDB(t *testing.T) (*sql.DB, func()) {
+	t.Helper()
+	sdb, err := sql.Open("postgres", fmt.Sprintf("host=%s port=%s user=%s "+
+		"password=%s dbname=%s sslmode=disable default_transaction_read_only=on",
+		dbi.Host, dbi.Port, dbi.User, dbi.Pass, dbi.DBName))
+	if err != nil {
+		t.Fatalf("failed to open seeding database handle: %v", err)
+	}
+	sdb.SetMaxOpenConns(1)
+	if _, err = sdb.Exec(`BEGIN READ WRITE;`); err != nil {
+		sdb.Close()
+		t.Fatalf("failed to begin seeding transaction: %v", err)
+	}
+	return sdb, func() {
+		if _, err := sdb.Exec(`ROLLBACK;`); err != nil {
+			t.Errorf("failed to roll back seeding transaction: %v", err)
+		}
+		sdb.Close()
+	}
+}
+
+// seedRow holds the column values of a row seeded by insertRows or
+// insertAddressRows.
+type seedRow []interface{}
+
+// insertRows seeds the table with rows having values for the columns in the
+// comma-separated list, and returns the ids of the new rows. The test fails if
+// any row cannot be inserted.
+func insertRows(t *testing.T, db *sql.DB, table, columns string, rows ...seedRow) []uint64 {
+	t.Helper()
+	params := make([]string, len(strings.Split(columns, ",")))
+	for i := range params {
+		params[i] = fmt.Sprintf("$%d", i+1)
+	}
+	stmt := fmt.Sprintf(`INSERT INTO %s (%s) VALUES (%s) RETURNING id;`,
+		table, columns, strings.Join(params, ", "))
+	return execSeedRows(t, db, stmt, rows)
+}
+
+// insertAddressRows seeds the addresses table using internal.InsertAddressRow,
+// so each row has values for all of the columns set by that statement.
+func insertAddressRows(t *testing.T, db *sql.DB, rows ...seedRow) []uint64 {
+	t.Helper()
+	return execSeedRows(t, db, internal.InsertAddressRow, rows)
+}
+
+// execSeedRows executes the insert statement, which returns the id of the new
+// row, for each of the rows.
+func execSeedRows(t *testing.T, db *sql.DB, stmt string, rows []seedRow) []uint64 {
+	t.Helper()
+	ids := make([]uint64, 0, len(rows))
+	for _, row := range rows {
+		var id uint64
+		if err := db.QueryRow(stmt, row...).Scan(&id); err != nil {
+			t.Fatalf("failed to seed row %v: %v", row, err)
+		}
+		ids = append(ids, id)
+	}
+	return ids
+}
+
 func TestStuff(t *testing.T) {
 	//testTx := "fa9acf7a4b1e9a52df1795f3e1c295613c9df44f5562de66595acc33b3831118"
 	// A fully spent transaction
@@ -144,3 +222,2424 @@ func TestStuff(t *testing.T) {
 			voutValue, voutValues[int(voutInd)])
 	}
 }
+
+func TestRetrievePureHolderAddresses(t *testing.T) {
+	sdb, rollback := openSeedDB(t)
+	defer rollback()
+
+	const (
+		holder  = "DsTestPureHolderAddress"
+		spender = "DsTestSpenderAddress"
+		// Larger than any real balance so the seeded rows sort first.
+		minBalance = int64(1e17)
+	)
+
+	now := time.Now()
+	insertAddressRows(t, sdb,
+		seedRow{holder, "", "testtx", 0, -1, int64(3e17), now, true, true, 0},
+		seedRow{spender, "", "testtx", 0, -2, int64(2e17), now, true, true, 0},
+		seedRow{spender, "", "testtx", 0, -3, int64(1e8), now, false, true, 0})
+
+	addrs, balances, err := RetrievePureHolderAddresses(context.Background(),
+		sdb, minBalance, 10)
+	if err != nil {
+		t.Fatalf("RetrievePureHolderAddresses: %v", err)
+	}
+	if len(addrs) != 1 || len(balances) != 1 {
+		t.Fatalf("Incorrect number of pure holders. Got %d, wanted 1.", len(addrs))
+	}
+	if addrs[0] != holder {
+		t.Errorf("Incorrect pure holder. Got %s, wanted %s.", addrs[0], holder)
+	}
+	if balances[0] != 3e17 {
+		t.Errorf("Incorrect balance. Got %d, wanted %d.", balances[0], int64(3e17))
+	}
+}
+
+func TestRetrieveTxOutputAddresses(t *testing.T) {
+	sdb, rollback := openSeedDB(t)
+	defer rollback()
+
+	const txHash = "testtxoutputaddresses"
+	now := time.Now()
+	insertAddressRows(t, sdb,
+		seedRow{"DsTestRecipientA", "", txHash, 0, -11, int64(1e8), now, true, true, 0},
+		seedRow{"DsTestRecipientB", "", txHash, 1, -12, int64(2e8), now, true, true, 0})
+
+	addrs, values, voutInds, err := RetrieveTxOutputAddresses(context.Background(),
+		sdb, txHash)
+	if err != nil {
+		t.Fatalf("RetrieveTxOutputAddresses: %v", err)
+	}
+	want := []struct {
+		address   string
+		value     int64
+		voutIndex uint32
+	}{
+		{"DsTestRecipientA", 1e8, 0},
+		{"DsTestRecipientB", 2e8, 1},
+	}
+	if len(addrs) != len(want) {
+		t.Fatalf("Incorrect number of addresses. Got %d, wanted %d.",
+			len(addrs), len(want))
+	}
+	for i, r := range want {
+		if addrs[i] != r.address || values[i] != r.value || voutInds[i] != r.voutIndex {
+			t.Errorf("Incorrect output %d. Got (%s, %d, %d), wanted (%s, %d, %d).",
+				i, addrs[i], values[i], voutInds[i], r.address, r.value, r.voutIndex)
+		}
+	}
+}
+
+func TestRetrieveRevocationsPerDay(t *testing.T) {
+	sdb, rollback := openSeedDB(t)
+	defer rollback()
+
+	// Seed revocations on two days far in the future so that they are the
+	// only transactions on those days.
+	day1 := time.Date(2100, 1, 1, 0, 0, 0, 0, time.UTC)
+	day2 := day1.AddDate(0, 0, 1)
+	insertRows(t, sdb, "transactions", "tx_hash, time, tx_type, is_mainchain",
+		seedRow{"testrevoke1", day1.Add(time.Hour), stake.TxTypeSSRtx, true},
+		seedRow{"testrevoke2", day1.Add(2 * time.Hour), stake.TxTypeSSRtx, true},
+		seedRow{"testrevoke3", day2.Add(time.Hour), stake.TxTypeSSRtx, true})
+
+	revokes, err := retrieveRevocationsPerDay(context.Background(), sdb)
+	if err != nil {
+		t.Fatalf("retrieveRevocationsPerDay: %v", err)
+	}
+
+	counts := make(map[time.Time]uint64)
+	for i := range revokes.Time {
+		counts[revokes.Time[i].T.UTC()] = revokes.Count[i]
+	}
+	if counts[day1] != 2 {
+		t.Errorf("Incorrect revocations on %v. Got %d, wanted 2.", day1, counts[day1])
+	}
+	if counts[day2] != 1 {
+		t.Errorf("Incorrect revocations on %v. Got %d, wanted 1.", day2, counts[day2])
+	}
+}
+
+func TestRetrieveFeeRatePerDay(t *testing.T) {
+	sdb, rollback := openSeedDB(t)
+	defer rollback()
+
+	// Seed transactions on two days far in the future so that they are the
+	// only transactions on those days.
+	day1 := time.Date(2101, 1, 1, 0, 0, 0, 0, time.UTC)
+	day2 := day1.AddDate(0, 0, 1)
+	// Coinbase and side chain transactions do not count.
+	insertRows(t, sdb, "transactions",
+		"tx_hash, time, tree, block_index, fees, size, is_mainchain",
+		seedRow{"testfeerate1", day1.Add(time.Hour), 0, 1, 30000, 200, true},
+		seedRow{"testfeerate2", day1.Add(2 * time.Hour), 0, 2, 70000, 800, true},
+		seedRow{"testfeerate3", day2.Add(time.Hour), 0, 1, 50000, 250, true},
+		seedRow{"testfeerate4", day2.Add(time.Hour), 0, 0, 0, 5000, true},
+		seedRow{"testfeerate5", day2.Add(2 * time.Hour), 0, 1, 90000, 100, false})
+
+	feeRates, err := retrieveFeeRatePerDay(context.Background(), sdb)
+	if err != nil {
+		t.Fatalf("retrieveFeeRatePerDay: %v", err)
+	}
+
+	rates := make(map[time.Time]float64)
+	for i := range feeRates.Time {
+		rates[feeRates.Time[i].T.UTC()] = feeRates.ValueF[i]
+	}
+	// Day 1 has 100000 atoms in 1000 bytes (0.001 DCR/kB), and day 2 has 50000
+	// atoms in 250 bytes (0.002 DCR/kB).
+	if rates[day1] != 0.001 {
+		t.Errorf("Incorrect fee rate on %v. Got %f, wanted 0.001.", day1, rates[day1])
+	}
+	if rates[day2] != 0.002 {
+		t.Errorf("Incorrect fee rate on %v. Got %f, wanted 0.002.", day2, rates[day2])
+	}
+}
+
+func TestRetrieveTxHasUnspentOutputs(t *testing.T) {
+	sdb, rollback := openSeedDB(t)
+	defer rollback()
+
+	const txHash = "testtxhasunspent"
+	now := time.Now()
+	insertAddressRows(t, sdb,
+		seedRow{"DsTestHasUnspent", "testtxhasunspentspender", txHash, 0, -3000001,
+			int64(1e8), now, true, true, 0},
+		seedRow{"DsTestHasUnspent", "", txHash, 1, -3000002, int64(1e8), now, true,
+			true, 0})
+
+	hasUnspent, numUnspent, err := RetrieveTxHasUnspentOutputs(context.Background(), sdb, txHash)
+	if err != nil {
+		t.Fatalf("RetrieveTxHasUnspentOutputs: %v", err)
+	}
+	if !hasUnspent || numUnspent != 1 {
+		t.Errorf("Incorrect unspent outputs. Got %v and %d, wanted true and 1.",
+			hasUnspent, numUnspent)
+	}
+}
+
+func TestRetrieveMostFragmentedAddresses(t *testing.T) {
+	sdb, rollback := openSeedDB(t)
+	defer rollback()
+
+	const fragmented, consolidated = "DsTestFragmented", "DsTestConsolidated"
+
+	// Seed more unspent outputs than the most fragmented real address so that
+	// the seeded addresses rank first.
+	_, counts, err := RetrieveMostFragmentedAddresses(context.Background(), sdb, 1)
+	if err != nil {
+		t.Fatalf("RetrieveMostFragmentedAddresses: %v", err)
+	}
+	var maxReal int64
+	if len(counts) > 0 {
+		maxReal = counts[0]
+	}
+	seed := []struct {
+		address  string
+		numUTXOs int64
+		rowID    int64
+	}{
+		{fragmented, maxReal + 10, -1000000},
+		{consolidated, maxReal + 1, -2000000},
+	}
+	for _, s := range seed {
+		// Each output is small, and one more output is spent.
+		_, err := sdb.Exec(`INSERT INTO addresses (address, matching_tx_hash,
+				tx_hash, tx_vin_vout_index, tx_vin_vout_row_id, value, block_time,
+				is_funding, valid_mainchain, tx_type)
+			SELECT $1, '', 'testfragmented', i, $2 - i, 1000, now(), TRUE, TRUE, 0
+			FROM generate_series(0, $3) AS i;`, s.address, s.rowID, s.numUTXOs)
+		if err != nil {
+			t.Fatalf("failed to insert address rows: %v", err)
+		}
+		_, err = sdb.Exec(`UPDATE addresses SET matching_tx_hash = 'testspent'
+			WHERE address = $1 AND tx_vin_vout_row_id = $2;`, s.address, s.rowID)
+		if err != nil {
+			t.Fatalf("failed to spend address row: %v", err)
+		}
+	}
+
+	addrs, counts, err := RetrieveMostFragmentedAddresses(context.Background(), sdb, 2)
+	if err != nil {
+		t.Fatalf("RetrieveMostFragmentedAddresses: %v", err)
+	}
+	if len(addrs) != 2 || len(counts) != 2 {
+		t.Fatalf("Incorrect number of addresses. Got %d, wanted 2.", len(addrs))
+	}
+	for i, s := range seed {
+		if addrs[i] != s.address || counts[i] != s.numUTXOs {
+			t.Errorf("Incorrect address at rank %d. Got %s with %d UTXOs, "+
+				"wanted %s with %d.", i, addrs[i], counts[i], s.address, s.numUTXOs)
+		}
+	}
+}
+
+func TestRetrieveAddressValueDenominations(t *testing.T) {
+	sdb, rollback := openSeedDB(t)
+	defer rollback()
+
+	// Seed three unspent outputs of 1 DCR and two of 0.5 DCR, plus a spent
+	// output of 1 DCR that must not be counted.
+	const address = "DsTestDenominations"
+	values := []int64{1e8, 1e8, 1e8, 5e7, 5e7, 1e8}
+	now := time.Now()
+	var rows []seedRow
+	for i, value := range values {
+		matching := ""
+		if i == len(values)-1 {
+			matching = "testdenominationsspend"
+		}
+		rows = append(rows, seedRow{address, matching, "testdenominations", i,
+			-3000000 - int64(i), value, now, true, true, 0})
+	}
+	insertAddressRows(t, sdb, rows...)
+
+	denominations, err := RetrieveAddressValueDenominations(context.Background(), sdb, address)
+	if err != nil {
+		t.Fatalf("RetrieveAddressValueDenominations: %v", err)
+	}
+
+	expected := map[int64]int64{1e8: 3, 5e7: 2}
+	if !reflect.DeepEqual(denominations, expected) {
+		t.Errorf("Incorrect denominations. Got %v, wanted %v.", denominations, expected)
+	}
+}
+
+func TestRetrieveAddressActivitySpan(t *testing.T) {
+	sdb, rollback := openSeedDB(t)
+	defer rollback()
+
+	const address = "DsTestActivitySpan"
+	first := time.Date(2200, 1, 1, 0, 0, 0, 0, time.UTC)
+	times := []time.Time{first.Add(time.Hour), first, first.Add(48 * time.Hour)}
+	var rows []seedRow
+	for i, blockTime := range times {
+		rows = append(rows, seedRow{address, "", "testactivityspan", i,
+			-3100000 - int64(i), int64(1e8), blockTime, true, true, 0})
+	}
+	insertAddressRows(t, sdb, rows...)
+
+	ctx := context.Background()
+	firstSeen, lastSeen, count, err := RetrieveAddressActivitySpan(ctx, sdb, address)
+	if err != nil {
+		t.Fatalf("RetrieveAddressActivitySpan: %v", err)
+	}
+	if !firstSeen.T.Equal(first) || !lastSeen.T.Equal(times[2]) || count != 3 {
+		t.Errorf("Incorrect activity span. Got %v to %v with %d rows, wanted "+
+			"%v to %v with 3.", firstSeen.T, lastSeen.T, count, first, times[2])
+	}
+
+	// An unused address has no span.
+	firstSeen, lastSeen, count, err = RetrieveAddressActivitySpan(ctx, sdb, "DsTestNoActivity")
+	if err != nil {
+		t.Fatalf("RetrieveAddressActivitySpan: %v", err)
+	}
+	if !firstSeen.T.IsZero() || !lastSeen.T.IsZero() || count != 0 {
+		t.Errorf("Expected no activity, got %v to %v with %d rows.",
+			firstSeen.T, lastSeen.T, count)
+	}
+}
+
+func TestRetrieveDistinctFundedAddressesInBlock(t *testing.T) {
+	sdb, rollback := openSeedDB(t)
+	defer rollback()
+
+	// Seed two transactions in a block, paying to three addresses with one
+	// address paid twice, and a debit row for another address that must not
+	// be counted.
+	const blockHash = "testfundedaddrsblock"
+	const tx0, tx1 = "testfundedaddrstx0", "testfundedaddrstx1"
+	insertRows(t, sdb, "transactions", "tx_hash, block_hash, block_height, is_mainchain",
+		seedRow{tx0, blockHash, 250000000, true},
+		seedRow{tx1, blockHash, 250000000, true})
+	now := time.Now()
+	insertAddressRows(t, sdb,
+		seedRow{"DsTestFundedA", "", tx0, 0, -3200000, int64(1e8), now, true, true, 0},
+		seedRow{"DsTestFundedB", "", tx0, 1, -3200001, int64(1e8), now, true, true, 0},
+		seedRow{"DsTestFundedA", "", tx1, 2, -3200002, int64(1e8), now, true, true, 0},
+		seedRow{"DsTestFundedC", "", tx1, 3, -3200003, int64(1e8), now, true, true, 0},
+		seedRow{"DsTestFundedDebit", "", tx1, 4, -3200004, int64(1e8), now, false, true, 0})
+
+	count, err := RetrieveDistinctFundedAddressesInBlock(context.Background(), sdb, blockHash)
+	if err != nil {
+		t.Fatalf("RetrieveDistinctFundedAddressesInBlock: %v", err)
+	}
+	if count != 3 {
+		t.Errorf("Incorrect number of funded addresses. Got %d, wanted 3.", count)
+	}
+}
+
+func TestRetrieveAddressesUsed(t *testing.T) {
+	sdb, rollback := openSeedDB(t)
+	defer rollback()
+
+	addrs := []string{"DsTestUsedA", "DsTestUnusedB", "DsTestUsedC", "DsTestUnusedD"}
+	now := time.Now()
+	insertAddressRows(t, sdb,
+		seedRow{addrs[0], "", "testaddressesused", 0, -21, int64(1e8), now, true, true, 0},
+		seedRow{addrs[2], "", "testaddressesused", 1, -22, int64(1e8), now, true, true, 0})
+
+	used, err := RetrieveAddressesUsed(context.Background(), sdb, addrs)
+	if err != nil {
+		t.Fatalf("RetrieveAddressesUsed: %v", err)
+	}
+
+	expected := map[string]bool{
+		addrs[0]: true,
+		addrs[1]: false,
+		addrs[2]: true,
+		addrs[3]: false,
+	}
+	if !reflect.DeepEqual(used, expected) {
+		t.Errorf("Incorrect address usage. Got %v, wanted %v.", used, expected)
+	}
+}
+
+func TestRetrieveReorgsPerWindow(t *testing.T) {
+	sdb, rollback := openSeedDB(t)
+	defer rollback()
+
+	// Seed a reorg, a mainchain and an orphaned block at the same height, far
+	// beyond the current best block.
+	const (
+		windowSize = int64(144)
+		height     = int64(144 * 100000)
+	)
+	insertRows(t, sdb, "blocks", "hash, height, is_mainchain",
+		seedRow{"testreorgmain", height, true},
+		seedRow{"testreorgside", height, false})
+
+	reorgs, err := retrieveReorgsPerWindow(context.Background(), sdb, windowSize)
+	if err != nil {
+		t.Fatalf("retrieveReorgsPerWindow: %v", err)
+	}
+
+	for i := range reorgs.Height {
+		if int64(reorgs.Height[i]) == height {
+			if reorgs.Count[i] != 1 {
+				t.Errorf("Incorrect reorg count. Got %d, wanted 1.", reorgs.Count[i])
+			}
+			return
+		}
+	}
+	t.Errorf("Window starting at %d not found.", height)
+}
+
+func TestRetrieveAvgTxPerBlockPerWindow(t *testing.T) {
+	sdb, rollback := openSeedDB(t)
+	defer rollback()
+
+	// Seed two windows beyond the best block. The first has blocks with 4 and
+	// 6 transactions, and the second 3, 5 and 10. A side chain block in the
+	// first window must not count.
+	const (
+		windowSize = int64(144)
+		height     = int64(144 * 1500000)
+	)
+	insertRows(t, sdb, "blocks", "hash, height, numtx, is_mainchain",
+		seedRow{"testavgtx0", height, 4, true},
+		seedRow{"testavgtx1", height + 1, 6, true},
+		seedRow{"testavgtxside", height + 1, 50, false},
+		seedRow{"testavgtx2", height + windowSize, 3, true},
+		seedRow{"testavgtx3", height + windowSize + 1, 5, true},
+		seedRow{"testavgtx4", height + windowSize + 2, 10, true})
+
+	avgs, err := retrieveAvgTxPerBlockPerWindow(context.Background(), sdb, windowSize)
+	if err != nil {
+		t.Fatalf("retrieveAvgTxPerBlockPerWindow: %v", err)
+	}
+
+	expected := map[int64]float64{
+		height:              5,
+		height + windowSize: 6,
+	}
+	for i := range avgs.Height {
+		want, ok := expected[int64(avgs.Height[i])]
+		if !ok {
+			continue
+		}
+		if avgs.ValueF[i] != want {
+			t.Errorf("Incorrect average for window %d. Got %f, wanted %f.",
+				avgs.Height[i], avgs.ValueF[i], want)
+		}
+		delete(expected, int64(avgs.Height[i]))
+	}
+	if len(expected) > 0 {
+		t.Errorf("Windows not found: %v", expected)
+	}
+}
+
+func TestRetrieveSideChainDepths(t *testing.T) {
+	sdb, rollback := openSeedDB(t)
+	defer rollback()
+
+	// Seed a mainchain block beyond the best block, with a two-block side
+	// chain forking from it.
+	const height = int64(220000000)
+	ids := insertRows(t, sdb, "blocks", "hash, height, previous_hash, is_mainchain",
+		seedRow{"testsidedepthmain", height, "", true},
+		seedRow{"testsidedepth1", height + 1, "testsidedepthmain", false},
+		seedRow{"testsidedepth2", height + 2, "testsidedepth1", false})
+	links := [][3]string{
+		{"", "testsidedepthmain", ""},
+		{"testsidedepthmain", "testsidedepth1", "testsidedepth2"},
+		{"testsidedepth1", "testsidedepth2", ""},
+	}
+	for i, l := range links {
+		_, err := sdb.Exec(internal.InsertBlockPrevNext, ids[i], l[0], l[1], l[2])
+		if err != nil {
+			t.Fatalf("failed to insert block_chain row: %v", err)
+		}
+	}
+
+	tips, depths, err := RetrieveSideChainDepths(context.Background(), sdb)
+	if err != nil {
+		t.Fatalf("RetrieveSideChainDepths: %v", err)
+	}
+	if len(tips) != len(depths) {
+		t.Fatalf("Mismatched results: %d tips, %d depths.", len(tips), len(depths))
+	}
+
+	for i := range tips {
+		switch tips[i] {
+		case "testsidedepth2":
+			if depths[i] != 2 {
+				t.Errorf("Incorrect side chain depth. Got %d, wanted 2.", depths[i])
+			}
+			return
+		case "testsidedepth1":
+			t.Errorf("Side chain block with a successor reported as a tip.")
+		}
+	}
+	t.Errorf("Side chain tip not found.")
+}
+
+func TestRetrieveBestBlockSummary(t *testing.T) {
+	sdb, rollback := openSeedDB(t)
+	defer rollback()
+
+	// Seed a mainchain block beyond the current best block, and a side chain
+	// block above it that must be ignored.
+	blockTime := time.Date(2200, 6, 1, 0, 0, 0, 0, time.UTC)
+	insertRows(t, sdb, "blocks",
+		"hash, height, size, time, numtx, difficulty, is_mainchain",
+		seedRow{"testbestsummary", 230000000, 4321, blockTime, 17, 1234.5, true},
+		seedRow{"testbestsummaryside", 230000001, 4321, blockTime, 17, 1234.5, false})
+
+	block, err := RetrieveBestBlockSummary(context.Background(), sdb)
+	if err != nil {
+		t.Fatalf("RetrieveBestBlockSummary: %v", err)
+	}
+	expected := dbtypes.BlockDataBasic{
+		Height:     230000000,
+		Size:       4321,
+		Hash:       "testbestsummary",
+		Difficulty: 1234.5,
+		Time:       dbtypes.TimeDef{T: blockTime},
+		NumTx:      17,
+	}
+	if !block.Time.T.Equal(blockTime) {
+		t.Errorf("Incorrect block time. Got %v, wanted %v.", block.Time.T, blockTime)
+	}
+	block.Time = expected.Time
+	if *block != expected {
+		t.Errorf("Incorrect best block. Got %+v, wanted %+v.", *block, expected)
+	}
+}
+
+// benchVins creates n vins with transaction hashes unique to the given prefix.
+func benchVins(prefix string, n int) dbtypes.VinTxPropertyARRAY {
+	now := dbtypes.TimeDef{T: time.Now()}
+	vins := make(dbtypes.VinTxPropertyARRAY, n)
+	for i := range vins {
+		vins[i] = dbtypes.VinTxProperty{
+			TxID:        fmt.Sprintf("%s%d", prefix, i),
+			PrevTxHash:  "benchvinprevout",
+			PrevTxIndex: uint32(i),
+			ValueIn:     1e8,
+			IsValid:     true,
+			IsMainchain: true,
+			Time:        now,
+		}
+	}
+	return vins
+}
+
+// benchmarkVinInserts benchmarks inserting 10k vins in a database transaction
+// that is rolled back after each iteration.
+func benchmarkVinInserts(b *testing.B, insert func(*sql.Tx, dbtypes.VinTxPropertyARRAY) ([]uint64, error)) {
+	const numVins = 10000
+	for i := 0; i < b.N; i++ {
+		b.StopTimer()
+		vins := benchVins(fmt.Sprintf("benchvin%d_", i), numVins)
+		dbtx, err := db.db.Begin()
+		if err != nil {
+			b.Fatalf("failed to begin transaction: %v", err)
+		}
+		b.StartTimer()
+
+		ids, err := insert(dbtx, vins)
+
+		b.StopTimer()
+		if errRoll := dbtx.Rollback(); errRoll != nil {
+			b.Fatalf("failed to roll back transaction: %v", errRoll)
+		}
+		if err != nil {
+			b.Fatal(err)
+		}
+		if len(ids) != numVins {
+			b.Fatalf("Inserted %d vins, expected %d.", len(ids), numVins)
+		}
+		b.StartTimer()
+	}
+}
+
+func BenchmarkInsertVinsByRow(b *testing.B) {
+	benchmarkVinInserts(b, func(dbtx *sql.Tx, vins dbtypes.VinTxPropertyARRAY) ([]uint64, error) {
+		return insertVinsByRowTx(context.Background(), dbtx, vins, false, false)
+	})
+}
+
+func BenchmarkCopyInVins(b *testing.B) {
+	benchmarkVinInserts(b, func(dbtx *sql.Tx, vins dbtypes.VinTxPropertyARRAY) ([]uint64, error) {
+		return copyInVinsTx(context.Background(), dbtx, vins)
+	})
+}
+
+func TestRetrieveTxsByVersion(t *testing.T) {
+	sdb, rollback := openSeedDB(t)
+	defer rollback()
+
+	// Seed transactions of two versions at a height beyond the best block.
+	const height = int64(10000000)
+	insertRows(t, sdb, "transactions",
+		"tx_hash, version, block_height, block_index, is_mainchain",
+		seedRow{"testtxversion1a", 1, height, 0, true},
+		seedRow{"testtxversion2", 2, height, 1, true},
+		seedRow{"testtxversion1b", 1, height, 2, true})
+
+	txHashes, err := RetrieveTxsByVersion(context.Background(), sdb, 1,
+		height, height, 10)
+	if err != nil {
+		t.Fatalf("RetrieveTxsByVersion: %v", err)
+	}
+	expected := []string{"testtxversion1a", "testtxversion1b"}
+	if !reflect.DeepEqual(txHashes, expected) {
+		t.Errorf("Incorrect transactions. Got %v, wanted %v.", txHashes, expected)
+	}
+
+	txHashes, err = RetrieveTxsByVersion(context.Background(), sdb, 2,
+		height, height, 10)
+	if err != nil {
+		t.Fatalf("RetrieveTxsByVersion: %v", err)
+	}
+	if len(txHashes) != 1 || txHashes[0] != "testtxversion2" {
+		t.Errorf("Incorrect transactions. Got %v, wanted [testtxversion2].", txHashes)
+	}
+}
+
+func TestRetrieveBlocksBySignaling(t *testing.T) {
+	sdb, rollback := openSeedDB(t)
+	defer rollback()
+
+	// Seed two blocks beyond the best block, the first with votes mostly
+	// signaling yes on the agenda and the second mostly signaling no.
+	const (
+		agendaID = "testsignaling"
+		height   = int64(20000000)
+	)
+	seed := []struct {
+		blockHash string
+		choices   []dbtypes.VoteChoice
+	}{
+		{"testsignalingblockyes", []dbtypes.VoteChoice{dbtypes.Yes, dbtypes.Yes, dbtypes.No, dbtypes.Abstain}},
+		{"testsignalingblockno", []dbtypes.VoteChoice{dbtypes.Yes, dbtypes.No, dbtypes.No}},
+	}
+	for i, b := range seed {
+		blockHeight := height + int64(i)
+		insertRows(t, sdb, "blocks", "hash, height, is_mainchain",
+			seedRow{b.blockHash, blockHeight, true})
+		for j, choice := range b.choices {
+			voteHash := fmt.Sprintf("%svote%d", b.blockHash, j)
+			insertRows(t, sdb, "votes",
+				"height, tx_hash, block_hash, candidate_block_hash, is_mainchain",
+				seedRow{blockHeight, voteHash, b.blockHash, "", true})
+			insertRows(t, sdb, "agendas",
+				"agenda_id, agenda_vote_choice, tx_hash, block_height",
+				seedRow{agendaID, choice, voteHash, blockHeight})
+		}
+	}
+
+	heights, signaledYes, err := RetrieveBlocksBySignaling(context.Background(),
+		sdb, agendaID, &chaincfg.MainNetParams, height, height+1)
+	if err != nil {
+		t.Fatalf("RetrieveBlocksBySignaling: %v", err)
+	}
+	wantHeights := []int64{height, height + 1}
+	if !reflect.DeepEqual(heights, wantHeights) {
+		t.Fatalf("Incorrect heights. Got %v, wanted %v.", heights, wantHeights)
+	}
+	wantSignaled := []bool{true, false}
+	if !reflect.DeepEqual(signaledYes, wantSignaled) {
+		t.Errorf("Incorrect signaling. Got %v, wanted %v.", signaledYes, wantSignaled)
+	}
+}
+
+func TestRetrieveMaxFeeTx(t *testing.T) {
+	sdb, rollback := openSeedDB(t)
+	defer rollback()
+
+	// Seed transactions with fees exceeding any real fee, including corrupt
+	// ones that must be ignored.
+	const height = int64(10000001)
+	insertRows(t, sdb, "transactions",
+		"tx_hash, block_height, block_index, spent, fees, is_mainchain",
+		seedRow{"testmaxfeesmall", height, 0, int64(1e16), int64(1e14), true},
+		seedRow{"testmaxfeelarge", height, 1, int64(1e16), int64(2e14), true},
+		seedRow{"testmaxfeecorrupt", height, 2, int64(1e14), int64(3e14), true},
+		seedRow{"testmaxfeenegative", height, 3, int64(1e16), int64(-4e14), true})
+
+	txHash, fee, blockHeight, err := RetrieveMaxFeeTx(context.Background(), sdb)
+	if err != nil {
+		t.Fatalf("RetrieveMaxFeeTx: %v", err)
+	}
+	if txHash != "testmaxfeelarge" || fee != 2e14 || blockHeight != height {
+		t.Errorf("Incorrect max fee tx. Got (%s, %d, %d), wanted (%s, %d, %d).",
+			txHash, fee, blockHeight, "testmaxfeelarge", int64(2e14), height)
+	}
+}
+
+func TestRetrieveNewVsReusedAddresses(t *testing.T) {
+	sdb, rollback := openSeedDB(t)
+	defer rollback()
+
+	// Seed two blocks beyond the best block. The reused address appears in
+	// both, while the new address first appears in the second block.
+	const (
+		height  = int64(10000002)
+		reused  = "DsTestReusedAddress"
+		newAddr = "DsTestNewAddress"
+	)
+	insertRows(t, sdb, "transactions", "tx_hash, block_height, block_index, is_mainchain",
+		seedRow{"testnewvsreusedtx1", height, 0, true},
+		seedRow{"testnewvsreusedtx2", height + 1, 0, true})
+	now := time.Now()
+	insertAddressRows(t, sdb,
+		seedRow{reused, "", "testnewvsreusedtx1", 0, -4, int64(1e8), now, true, true, 0},
+		seedRow{reused, "", "testnewvsreusedtx2", 0, -5, int64(1e8), now, true, true, 0},
+		seedRow{newAddr, "", "testnewvsreusedtx2", 0, -6, int64(1e8), now, true, true, 0})
+
+	heights, newCounts, reusedCounts, err := retrieveNewVsReusedAddresses(
+		context.Background(), sdb, height+1, height+1)
+	if err != nil {
+		t.Fatalf("retrieveNewVsReusedAddresses: %v", err)
+	}
+	if len(heights) != 1 || heights[0] != height+1 {
+		t.Fatalf("Incorrect heights. Got %v, wanted [%d].", heights, height+1)
+	}
+	if newCounts[0] != 1 || reusedCounts[0] != 1 {
+		t.Errorf("Incorrect split. Got %d new and %d reused, wanted 1 and 1.",
+			newCounts[0], reusedCounts[0])
+	}
+}
+
+func TestSetSpendingForTicketsBulk(t *testing.T) {
+	sdb, rollback := openSeedDB(t)
+	defer rollback()
+
+	// Seed unspent tickets to be updated in bulk. SetSpendingForTickets, which
+	// updates each ticket in its own database transaction, cannot run in the
+	// seeding transaction, so the expected values are checked directly.
+	const numTickets = 3
+	rows := make([]seedRow, numTickets)
+	for i := range rows {
+		rows[i] = seedRow{fmt.Sprintf("testspendingbulk%d", i), "", 60000000, true,
+			dbtypes.PoolStatusLive}
+	}
+	ids := insertRows(t, sdb, "tickets",
+		"tx_hash, block_hash, block_height, is_mainchain, pool_status", rows...)
+
+	spendDbIDs := []uint64{101, 102, 103}
+	blockHeights := []int64{60000010, 60000011, 60000012}
+	spendTypes := []dbtypes.TicketSpendType{dbtypes.TicketVoted,
+		dbtypes.TicketRevoked, dbtypes.TicketVoted}
+	poolStatuses := []dbtypes.TicketPoolStatus{dbtypes.PoolStatusVoted,
+		dbtypes.PoolStatusMissed, dbtypes.PoolStatusVoted}
+
+	n, err := SetSpendingForTicketsBulk(sdb, ids, spendDbIDs, blockHeights,
+		spendTypes, poolStatuses)
+	if err != nil || n != numTickets {
+		t.Fatalf("SetSpendingForTicketsBulk updated %d tickets: %v", n, err)
+	}
+
+	type spendInfo struct {
+		spendType, poolStatus int16
+		spendHeight           int64
+		spendTxDbID           uint64
+	}
+	for i, id := range ids {
+		var si spendInfo
+		err = sdb.QueryRow(`SELECT spend_type, pool_status, spend_height,
+			spend_tx_db_id FROM tickets WHERE id = $1;`, id).Scan(&si.spendType,
+			&si.poolStatus, &si.spendHeight, &si.spendTxDbID)
+		if err != nil {
+			t.Fatalf("failed to select ticket: %v", err)
+		}
+		expected := spendInfo{int16(spendTypes[i]), int16(poolStatuses[i]),
+			blockHeights[i], spendDbIDs[i]}
+		if si != expected {
+			t.Errorf("Incorrect spending info for ticket %d. Got %+v, wanted %+v.",
+				i, si, expected)
+		}
+	}
+
+	// Mismatched slices are rejected.
+	_, err = SetSpendingForTicketsBulk(sdb, ids, spendDbIDs[:1], blockHeights,
+		spendTypes, poolStatuses)
+	if err == nil {
+		t.Error("Expected an error for mismatched slice lengths.")
+	}
+}
+
+func TestRetrieveSplitTicketsPerWindow(t *testing.T) {
+	sdb, rollback := openSeedDB(t)
+	defer rollback()
+
+	// Seed tickets in a window beyond the best block: two split tickets, a
+	// solo ticket, and a ticket flagged split but with a single input.
+	const (
+		windowSize = int64(144)
+		height     = int64(144 * 200000)
+	)
+	insertRows(t, sdb, "tickets",
+		"tx_hash, block_hash, block_height, is_split, num_inputs, is_mainchain",
+		seedRow{"testsplitticket0", "", height, true, 3, true},
+		seedRow{"testsplitticket1", "", height + 1, true, 2, true},
+		seedRow{"testsoloticket", "", height + 2, false, 1, true},
+		seedRow{"testsplitoneinput", "", height + 3, true, 1, true})
+
+	splits, err := retrieveSplitTicketsPerWindow(context.Background(), sdb, windowSize)
+	if err != nil {
+		t.Fatalf("retrieveSplitTicketsPerWindow: %v", err)
+	}
+
+	for i := range splits.Height {
+		if int64(splits.Height[i]) == height {
+			if splits.Count[i] != 2 {
+				t.Errorf("Incorrect split ticket count. Got %d, wanted 2.", splits.Count[i])
+			}
+			return
+		}
+	}
+	t.Errorf("Window starting at %d not found.", height)
+}
+
+func TestRetrieveMultisigTicketCount(t *testing.T) {
+	sdb, rollback := openSeedDB(t)
+	defer rollback()
+
+	ctx := context.Background()
+	before, err := RetrieveMultisigTicketCount(ctx, sdb)
+	if err != nil {
+		t.Fatalf("RetrieveMultisigTicketCount: %v", err)
+	}
+
+	// Seed two multisig tickets, a single-sig ticket, and a side chain
+	// multisig ticket.
+	insertRows(t, sdb, "tickets",
+		"tx_hash, block_hash, block_height, is_multisig, is_mainchain",
+		seedRow{"testmultisigticket0", "", 250000000, true, true},
+		seedRow{"testmultisigticket1", "", 250000000, true, true},
+		seedRow{"testsinglesigticket", "", 250000000, false, true},
+		seedRow{"testmultisigticketside", "", 250000000, true, false})
+
+	after, err := RetrieveMultisigTicketCount(ctx, sdb)
+	if err != nil {
+		t.Fatalf("RetrieveMultisigTicketCount: %v", err)
+	}
+	if after-before != 2 {
+		t.Errorf("Incorrect number of new multisig tickets. Got %d, wanted 2.",
+			after-before)
+	}
+}
+
+func TestRetrieveUniqueVotersPerWindow(t *testing.T) {
+	sdb, rollback := openSeedDB(t)
+	defer rollback()
+
+	// Seed three votes in a window beyond the best block, cast by tickets of
+	// two distinct staking addresses.
+	const (
+		windowSize = int64(144)
+		height     = int64(144 * 1800000)
+	)
+	seed := []struct {
+		ticketHash, staker string
+	}{
+		{"testuniquevoterticket0", "DsTestVoterA"},
+		{"testuniquevoterticket1", "DsTestVoterA"},
+		{"testuniquevoterticket2", "DsTestVoterB"},
+	}
+	var tickets, votes []seedRow
+	for i, s := range seed {
+		tickets = append(tickets, seedRow{s.ticketHash, "", height - 1000, s.staker, true})
+		votes = append(votes, seedRow{height + int64(i), s.ticketHash + "vote", "", "",
+			s.ticketHash, true})
+	}
+	insertRows(t, sdb, "tickets",
+		"tx_hash, block_hash, block_height, stakesubmission_address, is_mainchain",
+		tickets...)
+	insertRows(t, sdb, "votes", "height, tx_hash, block_hash, "+
+		"candidate_block_hash, ticket_hash, is_mainchain", votes...)
+
+	voters, err := retrieveUniqueVotersPerWindow(context.Background(), sdb, windowSize)
+	if err != nil {
+		t.Fatalf("retrieveUniqueVotersPerWindow: %v", err)
+	}
+
+	for i := range voters.Height {
+		if int64(voters.Height[i]) == height {
+			if voters.Count[i] != 2 {
+				t.Errorf("Incorrect unique voter count. Got %d, wanted 2.", voters.Count[i])
+			}
+			return
+		}
+	}
+	t.Errorf("Window starting at %d not found.", height)
+}
+
+func TestRetrieveAddressStakingActivity(t *testing.T) {
+	sdb, rollback := openSeedDB(t)
+	defer rollback()
+
+	// Seed three tickets for the staking address, two of which voted.
+	const staker = "DsTestStaker"
+	insertRows(t, sdb, "tickets",
+		"tx_hash, block_hash, block_height, stakesubmission_address, is_mainchain",
+		seedRow{"teststakingticket0", "", 60000100, staker, true},
+		seedRow{"teststakingticket1", "", 60000100, staker, true},
+		seedRow{"teststakingticket2", "", 60000100, staker, true})
+	insertRows(t, sdb, "votes", "height, tx_hash, block_hash, "+
+		"candidate_block_hash, ticket_hash, is_mainchain",
+		seedRow{60000300, "teststakingticket0vote", "", "", "teststakingticket0", true},
+		seedRow{60000300, "teststakingticket1vote", "", "", "teststakingticket1", true})
+
+	tests := []struct {
+		address     string
+		hasStaked   bool
+		ticketCount int64
+		voteCount   int64
+	}{
+		{staker, true, 3, 2},
+		{"DsTestNonStaker", false, 0, 0},
+	}
+	for _, tt := range tests {
+		hasStaked, ticketCount, voteCount, err := RetrieveAddressStakingActivity(
+			context.Background(), sdb, tt.address)
+		if err != nil {
+			t.Fatalf("RetrieveAddressStakingActivity: %v", err)
+		}
+		if hasStaked != tt.hasStaked || ticketCount != tt.ticketCount ||
+			voteCount != tt.voteCount {
+			t.Errorf("Incorrect staking activity for %s. Got %v, %d tickets, "+
+				"%d votes, wanted %v, %d tickets, %d votes.", tt.address,
+				hasStaked, ticketCount, voteCount, tt.hasStaked, tt.ticketCount,
+				tt.voteCount)
+		}
+	}
+}
+
+func TestRetrieveTicketPoolAtHeight(t *testing.T) {
+	sdb, rollback := openSeedDB(t)
+	defer rollback()
+
+	// Seed tickets mined beyond the best block: two live, one spent before the
+	// block of interest, one missed but not yet revoked, one still live as it
+	// was only missed in a side chain block, and one still immature.
+	params := &chaincfg.MainNetParams
+	const height = int64(20000100)
+	maturity := int64(params.TicketMaturity)
+	insertRows(t, sdb, "tickets",
+		"tx_hash, block_hash, block_height, price, is_mainchain, spend_height",
+		seedRow{"testpoolvaluelive1", "", height - maturity - 10, 100.0, true, nil},
+		seedRow{"testpoolvaluelive2", "", height - maturity, 150.5, true, height + 1},
+		seedRow{"testpoolvaluespent", "", height - maturity - 5, 1000.0, true, height},
+		seedRow{"testpoolvaluemissed", "", height - maturity - 3, 1000.0, true, height + 5},
+		seedRow{"testpoolvaluesidemissed", "", height - maturity - 2, 20.0, true, nil},
+		seedRow{"testpoolvalueimmature", "", height - maturity + 1, 1000.0, true, nil})
+	insertRows(t, sdb, "blocks", "hash, height, is_mainchain",
+		seedRow{"testpoolvaluemainblock", height - 1, true},
+		seedRow{"testpoolvaluesideblock", height - 1, false})
+	insertRows(t, sdb, "misses",
+		"height, block_hash, candidate_block_hash, ticket_hash",
+		seedRow{height - 1, "testpoolvaluemainblock", "", "testpoolvaluemissed"},
+		seedRow{height - 1, "testpoolvaluesideblock", "", "testpoolvaluesidemissed"})
+
+	poolValue, poolSize, err := RetrieveTicketPoolAtHeight(context.Background(),
+		sdb, height, params)
+	if err != nil {
+		t.Fatalf("RetrieveTicketPoolAtHeight: %v", err)
+	}
+	if poolValue != 270.5 || poolSize != 3 {
+		t.Errorf("Incorrect ticket pool. Got value %f and size %d, wanted 270.5 and 3.",
+			poolValue, poolSize)
+	}
+
+	// Blocks before ticketing began have an empty pool.
+	poolValue, poolSize, err = RetrieveTicketPoolAtHeight(context.Background(),
+		sdb, params.StakeEnabledHeight-1, params)
+	if err != nil {
+		t.Fatalf("RetrieveTicketPoolAtHeight: %v", err)
+	}
+	if poolValue != 0 || poolSize != 0 {
+		t.Errorf("Expected an empty pool before ticketing, got value %f and size %d.",
+			poolValue, poolSize)
+	}
+}
+
+func TestRetrieveCumulativeTxCountAtHeight(t *testing.T) {
+	sdb, rollback := openSeedDB(t)
+	defer rollback()
+
+	// Seed a short chain beyond the best block, with a side chain block that
+	// must not be counted. The real blocks below the seeded ones contribute a
+	// fixed base count.
+	const height = int64(30000000)
+	insertRows(t, sdb, "blocks", "hash, height, numtx, is_mainchain",
+		seedRow{"testcumtxcount0", height, 3, true},
+		seedRow{"testcumtxcount1", height + 1, 5, true},
+		seedRow{"testcumtxcountside", height + 1, 100, false})
+
+	ctx := context.Background()
+	base, err := RetrieveCumulativeTxCountAtHeight(ctx, sdb, height-1)
+	if err != nil {
+		t.Fatalf("RetrieveCumulativeTxCountAtHeight: %v", err)
+	}
+	for i, want := range []int64{3, 8} {
+		count, err := RetrieveCumulativeTxCountAtHeight(ctx, sdb, height+int64(i))
+		if err != nil {
+			t.Fatalf("RetrieveCumulativeTxCountAtHeight: %v", err)
+		}
+		if count-base != want {
+			t.Errorf("Incorrect cumulative count at height %d. Got %d, wanted %d.",
+				height+int64(i), count-base, want)
+		}
+	}
+}
+
+func TestRetrieveBlockSummaryByHeightRange(t *testing.T) {
+	sdb, rollback := openSeedDB(t)
+	defer rollback()
+
+	// Seed five mainchain blocks beyond the best block, and a side chain block
+	// that must be excluded.
+	const height = int64(40000000)
+	now := time.Now()
+	rows := []seedRow{{"testheightrangeside", height + 2, 1000, now, 1, false}}
+	for i := int64(0); i < 5; i++ {
+		rows = append(rows, seedRow{fmt.Sprintf("testheightrange%d", i), height + i,
+			1000, now, 1, true})
+	}
+	insertRows(t, sdb, "blocks", "hash, height, size, time, numtx, is_mainchain",
+		rows...)
+
+	tests := []struct {
+		limit   int
+		heights []int64
+	}{
+		{0, []int64{height + 4, height + 3, height + 2, height + 1, height}},
+		{2, []int64{height + 4, height + 3}},
+		{10, []int64{height + 4, height + 3, height + 2, height + 1, height}},
+	}
+	for _, tt := range tests {
+		blocks, err := RetrieveBlockSummaryByHeightRange(context.Background(),
+			sdb, height, height+4, tt.limit)
+		if err != nil {
+			t.Fatalf("RetrieveBlockSummaryByHeightRange: %v", err)
+		}
+		if len(blocks) != len(tt.heights) {
+			t.Fatalf("Incorrect number of blocks with limit %d. Got %d, wanted %d.",
+				tt.limit, len(blocks), len(tt.heights))
+		}
+		for i, b := range blocks {
+			if int64(b.Height) != tt.heights[i] || b.Hash == "testheightrangeside" {
+				t.Errorf("Incorrect block %d with limit %d. Got %s at %d, wanted height %d.",
+					i, tt.limit, b.Hash, b.Height, tt.heights[i])
+			}
+		}
+	}
+}
+
+func TestRetrieveBlockTimeQuantiles(t *testing.T) {
+	sdb, rollback := openSeedDB(t)
+	defer rollback()
+
+	// Seed blocks beyond the best block where block height+i follows its
+	// predecessor by i seconds, giving intervals of 1 to 100 seconds.
+	const height = int64(50000000)
+	blockTime := time.Unix(1454954400, 0)
+	var rows []seedRow
+	for i := int64(0); i <= 100; i++ {
+		blockTime = blockTime.Add(time.Duration(i) * time.Second)
+		rows = append(rows, seedRow{fmt.Sprintf("testblocktimequantile%d", i),
+			height + i, blockTime, true})
+	}
+	insertRows(t, sdb, "blocks", "hash, height, time, is_mainchain", rows...)
+
+	p50, p90, p99, err := RetrieveBlockTimeQuantiles(context.Background(),
+		sdb, height+1, height+100)
+	if err != nil {
+		t.Fatalf("RetrieveBlockTimeQuantiles: %v", err)
+	}
+	// Linear interpolation over 1..100 puts percentile p at 1 + 99p.
+	for _, q := range []struct {
+		name      string
+		got, want float64
+	}{
+		{"p50", p50, 50.5},
+		{"p90", p90, 90.1},
+		{"p99", p99, 99.01},
+	} {
+		if math.Abs(q.got-q.want) > 1e-9 {
+			t.Errorf("Incorrect %s. Got %f, wanted %f.", q.name, q.got, q.want)
+		}
+	}
+}
+
+func TestRetrieveLiveTicketAgeDistribution(t *testing.T) {
+	sdb, rollback := openSeedDB(t)
+	defer rollback()
+
+	// Use a negative current height with live tickets seeded below it so that
+	// the real live tickets, all purchased after currentHeight, are excluded.
+	const currentHeight = int64(-1000000)
+	var rows []seedRow
+	for _, age := range []int64{0, 3, 5, 9} {
+		rows = append(rows, seedRow{fmt.Sprintf("testticketage%d", age), "",
+			currentHeight - age, dbtypes.PoolStatusLive, true})
+	}
+	insertRows(t, sdb, "tickets",
+		"tx_hash, block_hash, block_height, pool_status, is_mainchain", rows...)
+
+	ageBounds, counts, err := RetrieveLiveTicketAgeDistribution(context.Background(),
+		sdb, currentHeight, 3)
+	if err != nil {
+		t.Fatalf("RetrieveLiveTicketAgeDistribution: %v", err)
+	}
+	// Buckets of width 10/3 blocks: [0, 3.3), [3.3, 6.7), [6.7, 10).
+	wantBounds, wantCounts := []int64{0, 4, 7}, []int64{2, 1, 1}
+	if !reflect.DeepEqual(ageBounds, wantBounds) {
+		t.Errorf("Incorrect age bounds. Got %v, wanted %v.", ageBounds, wantBounds)
+	}
+	if !reflect.DeepEqual(counts, wantCounts) {
+		t.Errorf("Incorrect counts. Got %v, wanted %v.", counts, wantCounts)
+	}
+}
+
+func TestRetrieveUnspendableValue(t *testing.T) {
+	sdb, rollback := openSeedDB(t)
+	defer rollback()
+
+	ctx := context.Background()
+	before, err := RetrieveUnspendableValue(ctx, sdb)
+	if err != nil {
+		t.Fatalf("RetrieveUnspendableValue: %v", err)
+	}
+
+	// A transaction with a zero-value OP_RETURN output, a non-standard burn
+	// output, and a regular output that is not burned.
+	const txHash = "testunspendablevaluetx"
+	insertRows(t, sdb, "transactions",
+		"tx_hash, block_height, block_index, is_valid, is_mainchain",
+		seedRow{txHash, 70000000, 0, true, true})
+	insertRows(t, sdb, "vouts", "tx_hash, tx_index, tx_tree, value, script_type",
+		seedRow{txHash, 0, 0, 0, "nulldata"},
+		seedRow{txHash, 1, 0, int64(5e8), "nonstandard"},
+		seedRow{txHash, 2, 0, int64(3e8), "pubkeyhash"})
+
+	after, err := RetrieveUnspendableValue(ctx, sdb)
+	if err != nil {
+		t.Fatalf("RetrieveUnspendableValue: %v", err)
+	}
+	if burned := after - before; burned != 5e8 {
+		t.Errorf("Incorrect burned value. Got %d, wanted %d.", burned, int64(5e8))
+	}
+}
+
+func TestRetrieveVoutsByTxHash(t *testing.T) {
+	sdb, rollback := openSeedDB(t)
+	defer rollback()
+
+	// Seed a transaction with a pubkeyhash, a multisig and a nulldata output,
+	// inserted out of order, and a side chain copy of the transaction
+	// referencing a different vout row.
+	const txHash = "testvoutsbytxhash"
+	outputs := []struct {
+		txIndex    int
+		value      int64
+		reqSigs    int
+		scriptType string
+		addresses  []string
+	}{
+		{2, 0, 0, "nulldata", nil},
+		{0, 5e8, 1, "pubkeyhash", []string{"DsTestVoutPKH"}},
+		{1, 2e8, 2, "multisig", []string{"DsTestVoutMS1", "DsTestVoutMS2", "DsTestVoutMS3"}},
+		{7, 1e8, 1, "pubkeyhash", []string{"DsTestVoutSide"}},
+	}
+	var rows []seedRow
+	for _, out := range outputs {
+		rows = append(rows, seedRow{txHash, out.txIndex, 0, out.value, 0,
+			[]byte{byte(out.txIndex)}, out.reqSigs, out.scriptType,
+			pq.Array(out.addresses)})
+	}
+	ids := insertRows(t, sdb, "vouts", "tx_hash, tx_index, tx_tree, value, "+
+		"version, pkscript, script_req_sigs, script_type, script_addresses", rows...)
+	voutIDs := make([]int64, len(ids))
+	for i, id := range ids {
+		voutIDs[i] = int64(id)
+	}
+	insertRows(t, sdb, "transactions", "tx_hash, block_hash, block_height, "+
+		"vout_db_ids, is_valid, is_mainchain",
+		seedRow{txHash, "testvoutsbytxhashside", 290000000, pq.Array(voutIDs[3:]),
+			true, false},
+		seedRow{txHash, "testvoutsbytxhashmain", 290000000, pq.Array(voutIDs[:3]),
+			true, true})
+
+	vouts, err := RetrieveVoutsByTxHash(context.Background(), sdb, txHash)
+	if err != nil {
+		t.Fatalf("RetrieveVoutsByTxHash: %v", err)
+	}
+	if len(vouts) != 3 {
+		t.Fatalf("Incorrect number of vouts. Got %d, wanted 3.", len(vouts))
+	}
+	// The vouts are ordered by index: pubkeyhash, multisig, nulldata.
+	for i, want := range []int{1, 2, 0} {
+		out, vout := outputs[want], vouts[i]
+		if vout.TxHash != txHash || int(vout.TxIndex) != out.txIndex ||
+			int64(vout.Value) != out.value || vout.ScriptPubKey[0] != byte(out.txIndex) ||
+			int(vout.ScriptPubKeyData.ReqSigs) != out.reqSigs ||
+			vout.ScriptPubKeyData.Type != out.scriptType ||
+			!reflect.DeepEqual(vout.ScriptPubKeyData.Addresses, out.addresses) {
+			t.Errorf("Incorrect vout %d: %+v", i, vout)
+		}
+	}
+}
+
+func TestRetrieveRecentTxs(t *testing.T) {
+	sdb, rollback := openSeedDB(t)
+	defer rollback()
+
+	// Seed transactions in two blocks far in the future so they are the most
+	// recent, and a newer side chain transaction that must be excluded.
+	blockTime := time.Date(2200, 1, 1, 0, 0, 0, 0, time.UTC)
+	insertRows(t, sdb, "transactions",
+		"tx_hash, block_height, block_index, block_time, tree, is_mainchain",
+		seedRow{"testrecenttx0", 80000000, 0, blockTime, 0, true},
+		seedRow{"testrecenttx1", 80000000, 1, blockTime, 0, true},
+		seedRow{"testrecenttx2", 80000001, 0, blockTime.Add(5 * time.Minute), 0, true},
+		seedRow{"testrecenttxside", 80000002, 0, blockTime.Add(10 * time.Minute), 0, false})
+
+	hashes, heights, times, err := RetrieveRecentTxs(context.Background(), sdb, 3)
+	if err != nil {
+		t.Fatalf("RetrieveRecentTxs: %v", err)
+	}
+	wantHashes := []string{"testrecenttx2", "testrecenttx1", "testrecenttx0"}
+	wantHeights := []int64{80000001, 80000000, 80000000}
+	if !reflect.DeepEqual(hashes, wantHashes) {
+		t.Fatalf("Incorrect transactions. Got %v, wanted %v.", hashes, wantHashes)
+	}
+	if !reflect.DeepEqual(heights, wantHeights) {
+		t.Errorf("Incorrect heights. Got %v, wanted %v.", heights, wantHeights)
+	}
+	if !times[0].T.Equal(blockTime.Add(5*time.Minute)) || !times[2].T.Equal(blockTime) {
+		t.Errorf("Incorrect block times: %v", times)
+	}
+}
+
+func TestRetrieveTxsByBlockHeight(t *testing.T) {
+	sdb, rollback := openSeedDB(t)
+	defer rollback()
+
+	// Two blocks at the same height, one of them on a side chain.
+	const height = int64(90000000)
+	insertRows(t, sdb, "transactions", "tx_hash, block_hash, block_height, "+
+		"block_index, tree, block_time, is_mainchain",
+		seedRow{"testtxsbyheightmain0", "testtxsbyheightmainblock", height, 0, 0, time.Now(), true},
+		seedRow{"testtxsbyheightmain1", "testtxsbyheightmainblock", height, 1, 0, time.Now(), true},
+		seedRow{"testtxsbyheightside0", "testtxsbyheightsideblock", height, 0, 0, time.Now(), false})
+
+	_, txs, blockInds, _, _, err := RetrieveTxsByBlockHeight(context.Background(),
+		sdb, height)
+	if err != nil {
+		t.Fatalf("RetrieveTxsByBlockHeight: %v", err)
+	}
+	got := make(map[string]uint32, len(txs))
+	for i, tx := range txs {
+		got[tx] = blockInds[i]
+	}
+	want := map[string]uint32{"testtxsbyheightmain0": 0, "testtxsbyheightmain1": 1}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Incorrect transactions. Got %v, wanted %v.", got, want)
+	}
+}
+
+func TestBlocksPage(t *testing.T) {
+	bestHeight, _, _, err := RetrieveBestBlockHeight(context.Background(), db.db)
+	if err != nil {
+		t.Fatalf("RetrieveBestBlockHeight: %v", err)
+	}
+
+	// The first page starts at the tip.
+	blocks, err := db.BlocksPage(0, 3)
+	if err != nil {
+		t.Fatalf("BlocksPage: %v", err)
+	}
+	if len(blocks) != 3 {
+		t.Fatalf("Got %d blocks, wanted 3.", len(blocks))
+	}
+	for i, block := range blocks {
+		if want := uint32(bestHeight) - uint32(i); block.Height != want {
+			t.Errorf("Incorrect height for block %d. Got %d, wanted %d.", i, block.Height, want)
+		}
+	}
+
+	// The last page is short.
+	blocks, err = db.BlocksPage(int(bestHeight), 3)
+	if err != nil {
+		t.Fatalf("BlocksPage: %v", err)
+	}
+	if len(blocks) != 1 || blocks[0].Height != 0 {
+		t.Errorf("Expected only the genesis block on the last page, got %v.", blocks)
+	}
+
+	// Beyond the tip, and zero-length pages, are empty rather than errors.
+	for _, page := range []struct{ offset, limit int }{{int(bestHeight) + 1, 3}, {0, 0}} {
+		blocks, err = db.BlocksPage(page.offset, page.limit)
+		if err != nil {
+			t.Fatalf("BlocksPage(%d, %d): %v", page.offset, page.limit, err)
+		}
+		if blocks == nil || len(blocks) != 0 {
+			t.Errorf("Expected an empty page for BlocksPage(%d, %d), got %v.",
+				page.offset, page.limit, blocks)
+		}
+	}
+
+	if _, err = db.BlocksPage(-1, 3); err == nil {
+		t.Error("Expected an error for a negative offset.")
+	}
+}
+
+func TestRetrieveConsolidationTxs(t *testing.T) {
+	sdb, rollback := openSeedDB(t)
+	defer rollback()
+
+	const height = int64(100000000)
+	insertRows(t, sdb, "transactions", "tx_hash, block_height, block_index, "+
+		"tree, num_vin, num_vout, is_mainchain",
+		seedRow{"testconsolidationcoinbase", height, 0, 0, 10, 1, true},
+		seedRow{"testconsolidationtx", height, 1, 0, 10, 1, true},
+		seedRow{"testconsolidationtwoouts", height, 2, 0, 10, 2, true},
+		seedRow{"testconsolidationfewins", height, 3, 0, 3, 1, true})
+
+	hashes, numVins, err := RetrieveConsolidationTxs(context.Background(), sdb,
+		5, height, height, 10)
+	if err != nil {
+		t.Fatalf("RetrieveConsolidationTxs: %v", err)
+	}
+	if len(hashes) != 1 || hashes[0] != "testconsolidationtx" || numVins[0] != 10 {
+		t.Errorf("Incorrect consolidation transactions. Got %v with %v inputs.",
+			hashes, numVins)
+	}
+}
+
+func TestRetrieveAddressRowsForExport(t *testing.T) {
+	sdb, rollback := openSeedDB(t)
+	defer rollback()
+
+	const address = "DsTestExportAddress"
+	const height = int64(110000000)
+	blockTime := time.Date(2200, 1, 1, 0, 0, 0, 0, time.UTC)
+	insertRows(t, sdb, "transactions", "tx_hash, block_height, block_index, is_mainchain",
+		seedRow{"testexportfundtx", height, 0, true},
+		seedRow{"testexportspendtx", height + 1, 0, true})
+	// Insert the later debit first so the ordering is not by row ID.
+	insertAddressRows(t, sdb,
+		seedRow{address, "", "testexportspendtx", 0, -8, int64(3e8),
+			blockTime.Add(5 * time.Minute), false, true, 0},
+		seedRow{address, "", "testexportfundtx", 0, -7, int64(5e8), blockTime,
+			true, true, 0})
+
+	var rows []dbtypes.AddressExportRow
+	err := RetrieveAddressRowsForExport(context.Background(), sdb, address,
+		func(row *dbtypes.AddressExportRow) error {
+			rows = append(rows, *row)
+			return nil
+		})
+	if err != nil {
+		t.Fatalf("RetrieveAddressRowsForExport: %v", err)
+	}
+	if len(rows) != 2 {
+		t.Fatalf("Got %d rows, wanted 2.", len(rows))
+	}
+	if rows[0].TxHash != "testexportfundtx" || rows[0].Value != 5e8 ||
+		rows[0].BlockHeight != height {
+		t.Errorf("Incorrect credit row: %+v", rows[0])
+	}
+	if rows[1].TxHash != "testexportspendtx" || rows[1].Value != -3e8 ||
+		rows[1].BlockHeight != height+1 {
+		t.Errorf("Incorrect debit row: %+v", rows[1])
+	}
+
+	// An error from the callback stops the iteration.
+	errStop := fmt.Errorf("stop")
+	var n int
+	err = RetrieveAddressRowsForExport(context.Background(), sdb, address,
+		func(*dbtypes.AddressExportRow) error {
+			n++
+			return errStop
+		})
+	if err != errStop || n != 1 {
+		t.Errorf("Expected iteration to stop after 1 row with %v, got %d rows and %v.",
+			errStop, n, err)
+	}
+}
+
+func TestRetrieveTotalUniqueAddresses(t *testing.T) {
+	sdb, rollback := openSeedDB(t)
+	defer rollback()
+
+	ctx := context.Background()
+	before, err := RetrieveTotalUniqueAddresses(ctx, sdb)
+	if err != nil {
+		t.Fatalf("RetrieveTotalUniqueAddresses: %v", err)
+	}
+
+	// Two new addresses, one of them appearing twice.
+	addresses := []string{"DsTestUniqueAddress1", "DsTestUniqueAddress1", "DsTestUniqueAddress2"}
+	var rows []seedRow
+	for i, addr := range addresses {
+		rows = append(rows, seedRow{addr, "", "testuniqueaddrtx", i, -9 - int64(i),
+			int64(1e8), time.Now(), true, true, 0})
+	}
+	insertAddressRows(t, sdb, rows...)
+
+	after, err := RetrieveTotalUniqueAddresses(ctx, sdb)
+	if err != nil {
+		t.Fatalf("RetrieveTotalUniqueAddresses: %v", err)
+	}
+	if added := after - before; added != 2 {
+		t.Errorf("Incorrect number of new unique addresses. Got %d, wanted 2.", added)
+	}
+}
+
+func TestRetrieveContestedBlocks(t *testing.T) {
+	sdb, rollback := openSeedDB(t)
+	defer rollback()
+
+	// One block with 3 approving and 2 disapproving votes, and one with
+	// unanimous approval.
+	const height = int64(120000000)
+	blocks := []struct {
+		blockHash string
+		height    int64
+		valid     []bool
+	}{
+		{"testcontestedblock", height, []bool{true, true, true, false, false}},
+		{"testuncontestedblock", height + 1, []bool{true, true, true, true, true}},
+	}
+	var rows []seedRow
+	for _, b := range blocks {
+		for i, valid := range b.valid {
+			rows = append(rows, seedRow{b.height, fmt.Sprintf("%svote%d", b.blockHash, i),
+				b.blockHash, "", valid, true})
+		}
+	}
+	insertRows(t, sdb, "votes", "height, tx_hash, block_hash, "+
+		"candidate_block_hash, block_valid, is_mainchain", rows...)
+
+	hashes, approves, disapproves, err := RetrieveContestedBlocks(context.Background(),
+		sdb, height, height+1)
+	if err != nil {
+		t.Fatalf("RetrieveContestedBlocks: %v", err)
+	}
+	if len(hashes) != 1 || hashes[0] != "testcontestedblock" {
+		t.Fatalf("Incorrect contested blocks: %v", hashes)
+	}
+	if approves[0] != 3 || disapproves[0] != 2 {
+		t.Errorf("Incorrect vote counts. Got %d approve and %d disapprove, wanted 3 and 2.",
+			approves[0], disapproves[0])
+	}
+}
+
+func TestGroupedBlocksFeeRate(t *testing.T) {
+	sdb, rollback := openSeedDB(t)
+	defer rollback()
+
+	// Two blocks at the start of the highest stake difficulty window, far in
+	// the future so that they are in the first window and time interval.
+	windowSize := db.chainParams.StakeDiffWindowSize
+	base := (int64(130000000)/windowSize + 1) * windowSize
+	blockTime := time.Date(2200, 1, 1, 0, 0, 0, 0, time.UTC)
+	blockTime2 := blockTime.Add(5 * time.Minute)
+	insertRows(t, sdb, "blocks", "hash, height, size, time, numtx, num_rtx, "+
+		"fresh_stake, voters, revocations, difficulty, sbits, is_mainchain",
+		seedRow{"testfeerateblock0", base, 1000, blockTime, 2, 2, 0, 5, 0, 1, 1, true},
+		seedRow{"testfeerateblock1", base + 1, 1000, blockTime2, 2, 2, 0, 5, 0, 1, 1, true})
+	// The coinbase transaction is excluded from the average.
+	insertRows(t, sdb, "transactions", "tx_hash, block_hash, block_height, "+
+		"block_time, block_index, tree, fees, size, is_mainchain",
+		seedRow{"testfeeratecoinbase", "testfeerateblock0", base, blockTime, 0, 0, 999, 100, true},
+		seedRow{"testfeeratetx0", "testfeerateblock0", base, blockTime, 1, 0, 2000, 500, true},
+		seedRow{"testfeeratetx1", "testfeerateblock1", base + 1, blockTime2, 1, 0, 1000, 250, true})
+
+	// (2000 + 1000) atoms / (500 + 250) bytes
+	const wantFeeRate = 4.0
+
+	windows, err := retrieveWindowBlocks(context.Background(), sdb, windowSize, 1, 0)
+	if err != nil {
+		t.Fatalf("retrieveWindowBlocks: %v", err)
+	}
+	if len(windows) != 1 || windows[0].BlocksCount != 2 {
+		t.Fatalf("Incorrect window: %+v", windows)
+	}
+	if windows[0].FeeRate != wantFeeRate {
+		t.Errorf("Incorrect window fee rate. Got %f, wanted %f.", windows[0].FeeRate, wantFeeRate)
+	}
+
+	days, err := retrieveTimeBasedBlockListing(context.Background(), sdb,
+		dbtypes.DayGrouping.String(), 1, 0)
+	if err != nil {
+		t.Fatalf("retrieveTimeBasedBlockListing: %v", err)
+	}
+	if len(days) != 1 || days[0].BlocksCount != 2 {
+		t.Fatalf("Incorrect day: %+v", days)
+	}
+	if days[0].FeeRate != wantFeeRate {
+		t.Errorf("Incorrect daily fee rate. Got %f, wanted %f.", days[0].FeeRate, wantFeeRate)
+	}
+}
+
+func TestRetrieveMissedVotesForTicket(t *testing.T) {
+	sdb, rollback := openSeedDB(t)
+	defer rollback()
+
+	const ticketHash = "testmissedticket"
+	insertRows(t, sdb, "misses", "height, block_hash, candidate_block_hash, ticket_hash",
+		seedRow{140000010, "testmissblock140000010", "", ticketHash},
+		seedRow{140000002, "testmissblock140000002", "", ticketHash})
+
+	missHeights, err := RetrieveMissedVotesForTicket(context.Background(), sdb, ticketHash)
+	if err != nil {
+		t.Fatalf("RetrieveMissedVotesForTicket: %v", err)
+	}
+	wantHeights := []int64{140000002, 140000010}
+	if !reflect.DeepEqual(missHeights, wantHeights) {
+		t.Errorf("Incorrect miss heights. Got %v, wanted %v.", missHeights, wantHeights)
+	}
+
+	// A ticket that never missed has no misses.
+	missHeights, err = RetrieveMissedVotesForTicket(context.Background(), sdb, "testnevermissed")
+	if err != nil || len(missHeights) != 0 {
+		t.Errorf("Expected no misses, got %v (err: %v).", missHeights, err)
+	}
+}
+
+func TestRetrieveAddressTxnsInTimeRange(t *testing.T) {
+	sdb, rollback := openSeedDB(t)
+	defer rollback()
+
+	const address = "DsTestNetFlowAddress"
+	start := time.Date(2200, 2, 1, 0, 0, 0, 0, time.UTC)
+	end := start.AddDate(0, 1, 0)
+	insertAddressRows(t, sdb,
+		seedRow{address, "", "testnetflowbefore", 0, -20, int64(1e8), start.Add(-time.Hour), true, true, 0},
+		seedRow{address, "", "testnetflowin1", 0, -21, int64(1e8), start.Add(time.Hour), true, true, 0},
+		seedRow{address, "", "testnetflowin2", 0, -22, int64(1e8), end.Add(-time.Hour), false, true, 0},
+		seedRow{address, "", "testnetflowafter", 0, -23, int64(1e8), end.Add(time.Hour), false, true, 0})
+
+	rows, err := RetrieveAddressTxnsInTimeRange(context.Background(), sdb,
+		address, start, end)
+	if err != nil {
+		t.Fatalf("RetrieveAddressTxnsInTimeRange: %v", err)
+	}
+	if len(rows) != 2 {
+		t.Fatalf("Got %d rows, wanted 2.", len(rows))
+	}
+	if rows[0].TxHash != "testnetflowin1" || !rows[0].IsFunding ||
+		rows[1].TxHash != "testnetflowin2" || rows[1].IsFunding {
+		t.Errorf("Incorrect rows in the time window: %+v, %+v", rows[0], rows[1])
+	}
+}
+
+func TestRetrieveSpendDepthHistogram(t *testing.T) {
+	sdb, rollback := openSeedDB(t)
+	defer rollback()
+
+	// Outputs held for 0, 2, 4, and 9 blocks before being spent at height.
+	const height = int64(150000000)
+	var txRows, vinRows []seedRow
+	for i, depth := range []int64{0, 2, 4, 9} {
+		fundingHash := fmt.Sprintf("testspenddepthfund%d", i)
+		spendingHash := fmt.Sprintf("testspenddepthspend%d", i)
+		txRows = append(txRows, seedRow{fundingHash, height - depth, 1, true},
+			seedRow{spendingHash, height, 1, true})
+		vinRows = append(vinRows, seedRow{spendingHash, 0, 0, fundingHash, 0, 0,
+			true, true})
+	}
+	insertRows(t, sdb, "transactions", "tx_hash, block_height, block_index, is_mainchain",
+		txRows...)
+	insertRows(t, sdb, "vins", "tx_hash, tx_index, tx_tree, prev_tx_hash, "+
+		"prev_tx_index, prev_tx_tree, is_valid, is_mainchain", vinRows...)
+
+	depthBounds, counts, err := retrieveSpendDepthHistogram(context.Background(),
+		sdb, height, height, 3)
+	if err != nil {
+		t.Fatalf("retrieveSpendDepthHistogram: %v", err)
+	}
+	// Buckets of width 10/3 blocks: [0, 3.3), [3.3, 6.7), [6.7, 10).
+	wantBounds, wantCounts := []int64{0, 4, 7}, []int64{2, 1, 1}
+	if !reflect.DeepEqual(depthBounds, wantBounds) {
+		t.Errorf("Incorrect depth bounds. Got %v, wanted %v.", depthBounds, wantBounds)
+	}
+	if !reflect.DeepEqual(counts, wantCounts) {
+		t.Errorf("Incorrect counts. Got %v, wanted %v.", counts, wantCounts)
+	}
+}
+
+func TestRetrieveVinDbIDsRange(t *testing.T) {
+	sdb, rollback := openSeedDB(t)
+	defer rollback()
+
+	// Vins with row IDs far beyond those of the synced chain.
+	const baseID = uint64(9000000000000)
+	ids := []uint64{baseID, baseID + 1, baseID + 5, baseID + 10}
+	var rows []seedRow
+	for _, id := range ids {
+		rows = append(rows, seedRow{int64(id), fmt.Sprintf("testvinrange%d", id), 0, 0})
+	}
+	insertRows(t, sdb, "vins", "id, tx_hash, tx_index, tx_tree", rows...)
+
+	ctx := context.Background()
+	tests := []struct {
+		name         string
+		minID, maxID uint64
+		want         []uint64
+	}{
+		{"inclusive", baseID, baseID + 10, ids},
+		{"exclusive of ends", baseID + 1, baseID + 9, []uint64{baseID + 1, baseID + 5}},
+		{"single ID", baseID + 5, baseID + 5, []uint64{baseID + 5}},
+		{"no IDs", baseID + 2, baseID + 4, nil},
+		{"inverted", baseID + 10, baseID, nil},
+		{"beyond the end", baseID + 10, math.MaxUint64, []uint64{baseID + 10}},
+	}
+	for _, tt := range tests {
+		got, err := RetrieveVinDbIDsRange(ctx, sdb, tt.minID, tt.maxID)
+		if err != nil {
+			t.Fatalf("%s: RetrieveVinDbIDsRange: %v", tt.name, err)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: got IDs %v, wanted %v.", tt.name, got, tt.want)
+		}
+	}
+
+	maxID, err := RetrieveMaxVinDbID(ctx, sdb)
+	if err != nil {
+		t.Fatalf("RetrieveMaxVinDbID: %v", err)
+	}
+	if maxID != baseID+10 {
+		t.Errorf("Incorrect max vin ID. Got %d, wanted %d.", maxID, baseID+10)
+	}
+}
+
+func TestRetrieveFundingTxsByTx(t *testing.T) {
+	sdb, rollback := openSeedDB(t)
+	defer rollback()
+
+	// The spending transaction has inputs spending two funding transactions,
+	// the second of which is also in a side chain block, and a stakebase
+	// input with no funding transaction.
+	const spendingHash = "testfundingtxsspender"
+	blockTime := time.Date(2200, 2, 1, 0, 0, 0, 0, time.UTC)
+	seed := []struct {
+		txHash, blockHash string
+		sent              int64
+		isMainchain       bool
+	}{
+		{"testfundingtxs0", "testfundingtxsblock", 3e8, true},
+		{"testfundingtxs1", "testfundingtxsblock", 7e8, true},
+		{"testfundingtxs1", "testfundingtxsside", 7e8, false},
+	}
+	var txRows []seedRow
+	for i, tx := range seed {
+		txRows = append(txRows, seedRow{tx.blockHash, 260000000, blockTime,
+			blockTime, 0, 1, 0, tx.txHash, i + 1, 0, 0, 250, tx.sent, tx.sent, 0,
+			1, "{}", 1, "{}", true, tx.isMainchain})
+	}
+	insertRows(t, sdb, "transactions", "block_hash, block_height, block_time, "+
+		"time, tx_type, version, tree, tx_hash, block_index, lock_time, expiry, "+
+		"size, spent, sent, fees, num_vin, vin_db_ids, num_vout, vout_db_ids, "+
+		"is_valid, is_mainchain", txRows...)
+	prevTxHashes := []string{"testfundingtxs0", "testfundingtxs1",
+		"0000000000000000000000000000000000000000000000000000000000000000"}
+	var vinRows []seedRow
+	for i, prevTxHash := range prevTxHashes {
+		vinRows = append(vinRows, seedRow{spendingHash, i, 0, prevTxHash, 0, 0,
+			true, true})
+	}
+	insertRows(t, sdb, "vins", "tx_hash, tx_index, tx_tree, prev_tx_hash, "+
+		"prev_tx_index, prev_tx_tree, is_valid, is_mainchain", vinRows...)
+
+	ids, txs, err := RetrieveFundingTxsByTx(context.Background(), sdb, spendingHash)
+	if err != nil {
+		t.Fatalf("RetrieveFundingTxsByTx: %v", err)
+	}
+	if len(ids) != 2 || len(txs) != 2 {
+		t.Fatalf("Expected 2 funding transactions, got %d IDs and %d transactions.",
+			len(ids), len(txs))
+	}
+	for i, tx := range txs {
+		want := seed[i]
+		if tx.TxID != want.txHash || tx.BlockHash != want.blockHash ||
+			tx.Sent != want.sent || !tx.IsMainchainBlock || tx.Size != 250 ||
+			!tx.BlockTime.T.Equal(blockTime) {
+			t.Errorf("Incorrect funding transaction %d: %+v", i, tx)
+		}
+	}
+	if ids[0] >= ids[1] {
+		t.Errorf("Vin IDs not in input order: %v", ids)
+	}
+}
+
+func TestUpdateLastVins(t *testing.T) {
+	sdb, rollback := openSeedDB(t)
+	defer rollback()
+
+	// Seed a block with a regular and a stake transaction with several vins,
+	// and a vin of the same regular transaction in a side chain block, with a
+	// different block time, that must not be updated.
+	const blockHash = "testlastvinsblock"
+	blockTime := time.Date(2200, 3, 1, 0, 0, 0, 0, time.UTC)
+	sideBlockTime := blockTime.Add(time.Minute)
+	insertRows(t, sdb, "transactions", "tx_hash, block_hash, block_height, "+
+		"block_time, tree, block_index, is_valid, is_mainchain",
+		seedRow{"testlastvinstx0", blockHash, 280000000, blockTime, 0, 0, true, true},
+		seedRow{"testlastvinstx1", blockHash, 280000000, blockTime, 1, 1, true, true})
+	vinIDs := insertRows(t, sdb, "vins",
+		"tx_hash, tx_index, tx_tree, block_time, is_valid, is_mainchain",
+		seedRow{"testlastvinstx0", 0, 0, blockTime, true, true},
+		seedRow{"testlastvinstx0", 1, 0, blockTime, true, true},
+		seedRow{"testlastvinstx1", 0, 1, blockTime, true, true},
+		seedRow{"testlastvinstx0", 2, 0, sideBlockTime, true, true})
+
+	if err := UpdateLastVins(sdb, blockHash, false, false); err != nil {
+		t.Fatalf("UpdateLastVins: %v", err)
+	}
+
+	for i, id := range vinIDs {
+		var isValid, isMainchain bool
+		err := sdb.QueryRow(`SELECT is_valid, is_mainchain FROM vins WHERE id = $1;`,
+			id).Scan(&isValid, &isMainchain)
+		if err != nil {
+			t.Fatalf("failed to select vin: %v", err)
+		}
+		// Only the last vin is in the side chain block.
+		wantUpdated := i < len(vinIDs)-1
+		if isValid == wantUpdated || isMainchain == wantUpdated {
+			t.Errorf("Vin %d: is_valid = %v, is_mainchain = %v, updated = %v.",
+				i, isValid, isMainchain, wantUpdated)
+		}
+	}
+
+	// A block without transactions is an error.
+	if err := UpdateLastVins(sdb, "testlastvinsnoblock", false, false); err == nil {
+		t.Error("Expected an error for a block without vins.")
+	}
+}
+
+func TestRetrieveTxNullBlockTime(t *testing.T) {
+	sdb, rollback := openSeedDB(t)
+	defer rollback()
+
+	const txHash = "testnullblocktimetx"
+	insertRows(t, sdb, "transactions", "block_hash, block_height, time, "+
+		"tx_type, version, tree, tx_hash, block_index, lock_time, expiry, size, "+
+		"spent, sent, fees, num_vin, vin_db_ids, num_vout, vout_db_ids, "+
+		"is_valid, is_mainchain",
+		seedRow{"testnullblocktimeblock", 160000000, time.Unix(1500000000, 0).UTC(),
+			0, 1, 0, txHash, 1, 0, 0, 200, 0, 0, 0, 0, "{}", 0, "{}", false, false})
+
+	ctx := context.Background()
+	_, dbTx, err := RetrieveDbTxByHash(ctx, sdb, txHash)
+	if err != nil {
+		t.Fatalf("RetrieveDbTxByHash: %v", err)
+	}
+	if !dbTx.BlockTime.T.IsZero() || dbTx.Size != 200 {
+		t.Errorf("Incorrect transaction: block time %v, size %d.",
+			dbTx.BlockTime.T, dbTx.Size)
+	}
+
+	_, dbTxs, err := RetrieveDbTxsByHash(ctx, sdb, txHash)
+	if err != nil {
+		t.Fatalf("RetrieveDbTxsByHash: %v", err)
+	}
+	if len(dbTxs) != 1 || !dbTxs[0].BlockTime.T.IsZero() {
+		t.Errorf("Expected one transaction with zero block time, got %d.", len(dbTxs))
+	}
+
+	_, _, _, _, _, _, _, _, _, _, _, _, _, _, _, _, _, _, _, _, _, err = RetrieveFullTxByHash(ctx, sdb, txHash)
+	if err != nil {
+		t.Errorf("RetrieveFullTxByHash: %v", err)
+	}
+
+	blockTime, err := RetrieveTxBlockTimeByHash(ctx, sdb, txHash)
+	if err != nil {
+		t.Fatalf("RetrieveTxBlockTimeByHash: %v", err)
+	}
+	if !blockTime.T.IsZero() {
+		t.Errorf("Expected zero block time, got %v.", blockTime.T)
+	}
+
+	_, txs, _, _, blockTimes, err := RetrieveTxsByBlockHash(ctx, sdb, "testnullblocktimeblock")
+	if err != nil {
+		t.Fatalf("RetrieveTxsByBlockHash: %v", err)
+	}
+	if len(txs) != 1 || !blockTimes[0].T.IsZero() {
+		t.Errorf("Expected one transaction with zero block time, got %d.", len(txs))
+	}
+}
+
+func TestRetrieveMaxBlockGap(t *testing.T) {
+	sdb, rollback := openSeedDB(t)
+	defer rollback()
+
+	// Blocks 150 seconds apart, except for a 40 minute stall before the block
+	// at height+6.
+	const height = int64(170000000)
+	blockTime := time.Unix(1454954400, 0)
+	var rows []seedRow
+	for i := int64(0); i <= 10; i++ {
+		gap := 150 * time.Second
+		if i == 6 {
+			gap = 40 * time.Minute
+		}
+		blockTime = blockTime.Add(gap)
+		rows = append(rows, seedRow{fmt.Sprintf("testmaxblockgap%d", i), height + i,
+			blockTime, true})
+	}
+	insertRows(t, sdb, "blocks", "hash, height, time, is_mainchain", rows...)
+
+	ctx := context.Background()
+	gap, atHeight, err := RetrieveMaxBlockGap(ctx, sdb, height+1, height+10)
+	if err != nil {
+		t.Fatalf("RetrieveMaxBlockGap: %v", err)
+	}
+	if gap != 2400 || atHeight != height+6 {
+		t.Errorf("Incorrect gap. Got %d s at height %d, wanted 2400 s at %d.",
+			gap, atHeight, height+6)
+	}
+
+	// Excluding the stall leaves only the regular intervals, the first of
+	// which is reported.
+	gap, atHeight, err = RetrieveMaxBlockGap(ctx, sdb, height+7, height+10)
+	if err != nil {
+		t.Fatalf("RetrieveMaxBlockGap: %v", err)
+	}
+	if gap != 150 || atHeight != height+7 {
+		t.Errorf("Incorrect gap. Got %d s at height %d, wanted 150 s at %d.",
+			gap, atHeight, height+7)
+	}
+
+	// No intervals beyond the seeded blocks.
+	gap, atHeight, err = RetrieveMaxBlockGap(ctx, sdb, height+11, height+20)
+	if err != nil || gap != 0 || atHeight != 0 {
+		t.Errorf("Expected no gap, got %d s at height %d (err = %v).",
+			gap, atHeight, err)
+	}
+}
+
+func TestRetrieveBlockSizeByTree(t *testing.T) {
+	sdb, rollback := openSeedDB(t)
+	defer rollback()
+
+	const height = int64(180000000)
+	insertRows(t, sdb, "transactions", "tx_hash, block_height, tree, size, is_mainchain",
+		seedRow{"testsizebytree1", height, 0, 300, true},
+		seedRow{"testsizebytree2", height, 0, 500, true},
+		seedRow{"testsizebytree3", height, 1, 250, true},
+		seedRow{"testsizebytree4", height + 1, 0, 200, true},
+		// Side chain transactions are ignored.
+		seedRow{"testsizebytree5", height + 1, 1, 1000, false})
+
+	heights, regularSizes, stakeSizes, err := RetrieveBlockSizeByTree(
+		context.Background(), sdb, height, height+10)
+	if err != nil {
+		t.Fatalf("RetrieveBlockSizeByTree: %v", err)
+	}
+	if !reflect.DeepEqual(heights, []int64{height, height + 1}) {
+		t.Fatalf("Incorrect heights %v.", heights)
+	}
+	if !reflect.DeepEqual(regularSizes, []int64{800, 200}) {
+		t.Errorf("Incorrect regular sizes. Got %v, wanted [800 200].", regularSizes)
+	}
+	if !reflect.DeepEqual(stakeSizes, []int64{250, 0}) {
+		t.Errorf("Incorrect stake sizes. Got %v, wanted [250 0].", stakeSizes)
+	}
+}
+
+func TestRetrieveTicketPoolValueAndSizeAtHeight(t *testing.T) {
+	sdb, rollback := openSeedDB(t)
+	defer rollback()
+
+	params := &chaincfg.MainNetParams
+	const height = int64(190000000)
+	maturity := int64(params.TicketMaturity)
+	expiry := int64(params.TicketExpiry)
+	insertRows(t, sdb, "tickets",
+		"tx_hash, block_hash, block_height, price, is_mainchain, spend_height",
+		seedRow{"testpoolsizeimmature", "", height - 10, 2.0, true, nil},
+		seedRow{"testpoolsizelive", "", height - maturity - 100, 3.5, true, height + 5},
+		seedRow{"testpoolsizespent", "", height - maturity - 50, 100.0, true, height},
+		seedRow{"testpoolsizeexpired", "", height - maturity - expiry, 100.0, true, nil},
+		seedRow{"testpoolsizeoldest", "", height - maturity - expiry + 1, 1.0, true, nil},
+		seedRow{"testpoolsizesidechain", "", height - 5, 100.0, false, nil},
+		seedRow{"testpoolsizemissed", "", height - maturity - 30, 7.0, true, nil})
+	// The missed ticket leaves the pool when it is missed, not when it is
+	// revoked.
+	insertRows(t, sdb, "blocks", "hash, height, is_mainchain",
+		seedRow{"testpoolsizemissblock", height - 15, true})
+	insertRows(t, sdb, "misses", "height, block_hash, candidate_block_hash, ticket_hash",
+		seedRow{height - 15, "testpoolsizemissblock", "", "testpoolsizemissed"})
+
+	tests := []struct {
+		height int64
+		size   int64
+		value  int64
+	}{
+		// The immature, live, and oldest unexpired tickets.
+		{height, 3, 650000000},
+		// Before the immature ticket was mined, the spent ticket was spent, the
+		// missed ticket was missed, and the expired ticket expired.
+		{height - 20, 5, 21150000000},
+	}
+	for _, tt := range tests {
+		size, value, err := RetrieveTicketPoolValueAndSizeAtHeight(
+			context.Background(), sdb, tt.height, params)
+		if err != nil {
+			t.Fatalf("RetrieveTicketPoolValueAndSizeAtHeight: %v", err)
+		}
+		if size != tt.size || value != tt.value {
+			t.Errorf("Incorrect pool at height %d. Got size %d and value %d, "+
+				"wanted %d and %d.", tt.height, size, value, tt.size, tt.value)
+		}
+	}
+}
+
+func TestRetrieveMissedTicketsForBlock(t *testing.T) {
+	sdb, rollback := openSeedDB(t)
+	defer rollback()
+
+	const blockHash = "testmissedticketsblock"
+	const address = "DsTestMissedTicketAddress"
+	// One missed ticket is known, the other is not in the tickets table.
+	insertRows(t, sdb, "tickets",
+		"tx_hash, block_hash, block_height, stakesubmission_address, is_mainchain",
+		seedRow{"testmissedtickets1", "", 200000000, address, true})
+	insertRows(t, sdb, "misses", "height, block_hash, candidate_block_hash, ticket_hash",
+		seedRow{200000100, blockHash, "", "testmissedtickets2"},
+		seedRow{200000100, blockHash, "", "testmissedtickets1"})
+
+	ticketHashes, addresses, err := RetrieveMissedTicketsForBlock(
+		context.Background(), sdb, blockHash)
+	if err != nil {
+		t.Fatalf("RetrieveMissedTicketsForBlock: %v", err)
+	}
+	wantHashes := []string{"testmissedtickets1", "testmissedtickets2"}
+	if !reflect.DeepEqual(ticketHashes, wantHashes) {
+		t.Errorf("Incorrect tickets. Got %v, wanted %v.", ticketHashes, wantHashes)
+	}
+	wantAddresses := []string{address, ""}
+	if !reflect.DeepEqual(addresses, wantAddresses) {
+		t.Errorf("Incorrect addresses. Got %v, wanted %v.", addresses, wantAddresses)
+	}
+}
+
+func TestRetrieveCoinbaseValuePerBlock(t *testing.T) {
+	sdb, rollback := openSeedDB(t)
+	defer rollback()
+
+	// Seed a block's coinbase and a regular transaction in the same block,
+	// and a side chain coinbase, which must not be counted.
+	const height = int64(240000000)
+	insertRows(t, sdb, "transactions",
+		"tx_hash, block_height, tree, block_index, sent, is_mainchain",
+		seedRow{"testcoinbasevalue", height, 0, 0, 1234567890, true},
+		seedRow{"testcoinbasevalueregular", height, 0, 1, int64(5e8), true},
+		seedRow{"testcoinbasevalueside", height, 0, 0, int64(3e8), false})
+
+	values, err := retrieveCoinbaseValuePerBlock(context.Background(), sdb,
+		height, height)
+	if err != nil {
+		t.Fatalf("retrieveCoinbaseValuePerBlock: %v", err)
+	}
+	if len(values.Height) != 1 || len(values.ValueF) != 1 {
+		t.Fatalf("Expected one block, got %d heights and %d values.",
+			len(values.Height), len(values.ValueF))
+	}
+	if int64(values.Height[0]) != height || values.ValueF[0] != 12.3456789 {
+		t.Errorf("Incorrect coinbase value. Got %f at height %d, wanted "+
+			"12.3456789 at height %d.", values.ValueF[0], values.Height[0], height)
+	}
+}
+
+func TestRetrieveTimeLockedTxs(t *testing.T) {
+	sdb, rollback := openSeedDB(t)
+	defer rollback()
+
+	const height = int64(270000000)
+	insertRows(t, sdb, "transactions",
+		"tx_hash, block_height, tree, block_index, lock_time, is_mainchain",
+		seedRow{"testtimelocked", height, 0, 1, 1540000000, true},
+		seedRow{"testnotlocked", height, 0, 2, 0, true},
+		seedRow{"testtimelockedheight", height, 0, 3, 300000, true},
+		seedRow{"testtimelockedside", height, 0, 1, 1540000000, false})
+
+	ctx := context.Background()
+	hashes, lockTimes, err := RetrieveTimeLockedTxs(ctx, sdb, height, height, 0)
+	if err != nil {
+		t.Fatalf("RetrieveTimeLockedTxs: %v", err)
+	}
+	wantHashes := []string{"testtimelocked", "testtimelockedheight"}
+	wantLockTimes := []int32{1540000000, 300000}
+	if !reflect.DeepEqual(hashes, wantHashes) || !reflect.DeepEqual(lockTimes, wantLockTimes) {
+		t.Errorf("Incorrect time-locked transactions. Got %v %v, wanted %v %v.",
+			hashes, lockTimes, wantHashes, wantLockTimes)
+	}
+
+	hashes, _, err = RetrieveTimeLockedTxs(ctx, sdb, height, height, 1)
+	if err != nil {
+		t.Fatalf("RetrieveTimeLockedTxs: %v", err)
+	}
+	if len(hashes) != 1 || hashes[0] != "testtimelocked" {
+		t.Errorf("Limit not applied. Got %v.", hashes)
+	}
+}
+
+func TestRetrieveValueMismatchTxs(t *testing.T) {
+	sdb, rollback := openSeedDB(t)
+	defer rollback()
+
+	const height = int64(210000000)
+	insertRows(t, sdb, "transactions", "tx_hash, block_height, tree, "+
+		"block_index, tx_type, spent, sent, fees, is_mainchain",
+		seedRow{"testvaluemismatchcoinbase", height, 0, 0, 0, 500, 600, 0, true},
+		seedRow{"testvaluemismatchok", height, 0, 1, 0, 1000, 900, 100, true},
+		seedRow{"testvaluemismatchcorrupt", height, 0, 2, 0, 1000, 900, 150, true},
+		seedRow{"testvaluemismatchvote", height, 1, 0, 2, 300, 400, 0, true})
+
+	hashes, mismatches, err := RetrieveValueMismatchTxs(context.Background(),
+		sdb, height, height)
+	if err != nil {
+		t.Fatalf("RetrieveValueMismatchTxs: %v", err)
+	}
+	if !reflect.DeepEqual(hashes, []string{"testvaluemismatchcorrupt"}) ||
+		!reflect.DeepEqual(mismatches, []int64{-50}) {
+		t.Errorf("Incorrect mismatched transactions. Got %v (%v), wanted "+
+			"[testvaluemismatchcorrupt] ([-50]).", hashes, mismatches)
+	}
+}
+
+func TestRetrieveAddressBalanceSnapshots(t *testing.T) {
+	sdb, rollback := openSeedDB(t)
+	defer rollback()
+
+	// Fund the address twice, then spend the first output.
+	const address = "DsTestBalanceSnapshots"
+	insertRows(t, sdb, "transactions",
+		"tx_hash, block_hash, block_height, is_valid, is_mainchain",
+		seedRow{"testsnapshotfund1", "testsnapshotfund1", 300000000, true, true},
+		seedRow{"testsnapshotfund2", "testsnapshotfund2", 300000010, true, true},
+		seedRow{"testsnapshotspend", "testsnapshotspend", 300000020, true, true})
+	insertAddressRows(t, sdb,
+		seedRow{address, "", "testsnapshotfund1", 0, -3300000, int64(10e8), time.Now(), true, true, 0},
+		seedRow{address, "", "testsnapshotfund2", 0, -3300001, int64(5e8), time.Now(), true, true, 0},
+		seedRow{address, "", "testsnapshotspend", 0, -3300002, int64(10e8), time.Now(), false, true, 0})
+
+	// The snapshots are returned in the requested order.
+	heights := []int64{300000025, 300000005, 300000015}
+	balances, err := RetrieveAddressBalanceSnapshots(context.Background(),
+		sdb, address, heights)
+	if err != nil {
+		t.Fatalf("RetrieveAddressBalanceSnapshots: %v", err)
+	}
+	expected := []int64{5e8, 10e8, 15e8}
+	if !reflect.DeepEqual(balances, expected) {
+		t.Errorf("Incorrect balances. Got %v, wanted %v.", balances, expected)
+	}
+}
+
+func TestRetrieveBlockFullnessPerWindow(t *testing.T) {
+	sdb, rollback := openSeedDB(t)
+	defer rollback()
+
+	// Seed a window beyond the best block with one full block and three
+	// blocks under the 80% fullness threshold. A full side chain block must
+	// not count.
+	const (
+		windowSize = int64(144)
+		height     = int64(144 * 2100000)
+		maxSize    = int64(1000000)
+	)
+	insertRows(t, sdb, "blocks", "hash, height, size, is_mainchain",
+		seedRow{"testfullness0", height, 950000, true},
+		seedRow{"testfullness1", height + 1, 800000, true},
+		seedRow{"testfullness2", height + 2, 20000, true},
+		seedRow{"testfullness3", height + 3, 500000, true},
+		seedRow{"testfullnessside", height + 3, 990000, false})
+
+	fullness, err := retrieveBlockFullnessPerWindow(context.Background(), sdb,
+		windowSize, maxSize)
+	if err != nil {
+		t.Fatalf("retrieveBlockFullnessPerWindow: %v", err)
+	}
+	for i := range fullness.Height {
+		if int64(fullness.Height[i]) != height {
+			continue
+		}
+		if fullness.ValueF[i] != 0.25 {
+			t.Errorf("Incorrect fraction of full blocks. Got %f, wanted 0.25.",
+				fullness.ValueF[i])
+		}
+		return
+	}
+	t.Errorf("Window %d not found.", height)
+}
+
+func TestRetrieveAddressTxGraph(t *testing.T) {
+	sdb, rollback := openSeedDB(t)
+	defer rollback()
+
+	// A pays B in one transaction, and B pays C in another.
+	const (
+		addrA = "DsTestTxGraphA"
+		addrB = "DsTestTxGraphB"
+		addrC = "DsTestTxGraphC"
+	)
+	insertAddressRows(t, sdb,
+		seedRow{addrA, "", "testtxgraph1", 0, -3400000, 1000, time.Now(), false, true, 0},
+		seedRow{addrB, "", "testtxgraph1", 0, -3400001, 1000, time.Now(), true, true, 0},
+		seedRow{addrB, "", "testtxgraph2", 0, -3400001, 1000, time.Now(), false, true, 0},
+		seedRow{addrC, "", "testtxgraph2", 0, -3400002, 1000, time.Now(), true, true, 0})
+
+	tests := []struct {
+		depth int
+		nodes []string
+		edges [][2]string
+	}{
+		{0, []string{addrA}, nil},
+		{1, []string{addrA, addrB}, [][2]string{{addrA, addrB}}},
+		{2, []string{addrA, addrB, addrC}, [][2]string{{addrA, addrB}, {addrB, addrC}}},
+		{5, []string{addrA, addrB, addrC}, [][2]string{{addrA, addrB}, {addrB, addrC}}},
+	}
+	for _, test := range tests {
+		nodes, edges, err := RetrieveAddressTxGraph(context.Background(), sdb,
+			addrA, test.depth)
+		if err != nil {
+			t.Fatalf("RetrieveAddressTxGraph: %v", err)
+		}
+		if !reflect.DeepEqual(nodes, test.nodes) {
+			t.Errorf("Incorrect nodes at depth %d. Got %v, wanted %v.",
+				test.depth, nodes, test.nodes)
+		}
+		if !reflect.DeepEqual(edges, test.edges) {
+			t.Errorf("Incorrect edges at depth %d. Got %v, wanted %v.",
+				test.depth, edges, test.edges)
+		}
+	}
+}
+
+func TestRetrieveTicketVoteIntervals(t *testing.T) {
+	sdb, rollback := openSeedDB(t)
+	defer rollback()
+
+	countsByInterval := func() map[uint64]uint64 {
+		intervals, err := RetrieveTicketVoteIntervals(context.Background(), sdb)
+		if err != nil {
+			t.Fatalf("RetrieveTicketVoteIntervals: %v", err)
+		}
+		counts := make(map[uint64]uint64, len(intervals.Value))
+		for i := range intervals.Value {
+			counts[intervals.Value[i]] = intervals.Count[i]
+		}
+		return counts
+	}
+	before := countsByInterval()
+
+	// Two tickets vote 300 blocks after purchase, and one 4000 blocks after.
+	// A side chain vote must not count.
+	const height = 310000000
+	seed := []struct {
+		txHash      string
+		interval    int64
+		isMainchain bool
+	}{
+		{"testvoteinterval1", 300, true},
+		{"testvoteinterval2", 300, true},
+		{"testvoteinterval3", 4000, true},
+		{"testvoteinterval4", 4000, false},
+	}
+	var ticketRows []seedRow
+	for _, s := range seed {
+		ticketRows = append(ticketRows, seedRow{s.txHash, s.txHash, height, true})
+	}
+	ticketIDs := insertRows(t, sdb, "tickets", "tx_hash, block_hash, block_height, is_mainchain",
+		ticketRows...)
+	var voteRows []seedRow
+	for i, s := range seed {
+		voteRows = append(voteRows, seedRow{height + s.interval, s.txHash, s.txHash,
+			s.txHash, s.txHash, int64(ticketIDs[i]), s.isMainchain})
+	}
+	insertRows(t, sdb, "votes", "height, tx_hash, block_hash, candidate_block_hash, "+
+		"ticket_hash, ticket_tx_db_id, is_mainchain", voteRows...)
+
+	after := countsByInterval()
+	if n := after[300] - before[300]; n != 2 {
+		t.Errorf("Incorrect number of votes 300 blocks after purchase. Got %d, wanted 2.", n)
+	}
+	if n := after[4000] - before[4000]; n != 1 {
+		t.Errorf("Incorrect number of votes 4000 blocks after purchase. Got %d, wanted 1.", n)
+	}
+}
+
+func TestRetrieveAddressMergedCreditTxns(t *testing.T) {
+	sdb, rollback := openSeedDB(t)
+	defer rollback()
+
+	// A funding transaction with two outputs paying to the address.
+	const (
+		address = "DsTestMergedCredit"
+		txHash  = "testmergedcredit"
+	)
+	blockTime := time.Date(2202, 1, 1, 0, 0, 0, 0, time.UTC)
+	insertAddressRows(t, sdb,
+		seedRow{address, "", txHash, 0, -3500000, int64(3e8), blockTime, true, true, 0},
+		seedRow{address, "", txHash, 1, -3500001, int64(4e8), blockTime, true, true, 0})
+
+	ids, rows, err := RetrieveAddressMergedCreditTxns(context.Background(), sdb,
+		address, 10, 0)
+	if err != nil {
+		t.Fatalf("RetrieveAddressMergedCreditTxns: %v", err)
+	}
+	if ids != nil {
+		t.Errorf("Merged rows should not have row IDs, got %v.", ids)
+	}
+	if len(rows) != 1 {
+		t.Fatalf("Incorrect number of merged rows. Got %d, wanted 1.", len(rows))
+	}
+	row := rows[0]
+	if row.TxHash != txHash || !row.IsFunding || row.Value != 7e8 ||
+		row.MergedCreditCount != 2 || row.MergedDebitCount != 0 {
+		t.Errorf("Incorrect merged row: %+v", row)
+	}
+}
+
+func TestRetrieveBusiestBlock(t *testing.T) {
+	sdb, rollback := openSeedDB(t)
+	defer rollback()
+
+	// Seed blocks beyond the best block. The side chain block has the most
+	// transactions but must not be returned, and the busiest block just past
+	// the end of the range is excluded.
+	const height = 320000000
+	insertRows(t, sdb, "blocks", "hash, height, numtx, is_mainchain",
+		seedRow{"testbusiest0", height, 12, true},
+		seedRow{"testbusiest1", height + 1, 40, true},
+		seedRow{"testbusiest2", height + 2, 25, true},
+		seedRow{"testbusiestside", height + 2, 90, false},
+		seedRow{"testbusiest3", height + 3, 100, true})
+
+	hash, blockHeight, txCount, err := RetrieveBusiestBlock(context.Background(),
+		sdb, height, height+2)
+	if err != nil {
+		t.Fatalf("RetrieveBusiestBlock: %v", err)
+	}
+	if hash != "testbusiest1" || blockHeight != height+1 || txCount != 40 {
+		t.Errorf("Incorrect busiest block. Got %s at %d with %d txns.",
+			hash, blockHeight, txCount)
+	}
+
+	_, _, _, err = RetrieveBusiestBlock(context.Background(), sdb,
+		height+10, height+20)
+	if err != sql.ErrNoRows {
+		t.Errorf("Expected sql.ErrNoRows for an empty range, got %v.", err)
+	}
+}
+
+func TestRetrieveAddressLargestUTXOs(t *testing.T) {
+	sdb, rollback := openSeedDB(t)
+	defer rollback()
+
+	// Seed a transaction with four outputs paying to the address. The largest
+	// is spent, and must not be returned.
+	const (
+		address = "DsTestLargestUTXOs"
+		txHash  = "testlargestutxos"
+		height  = 330000000
+	)
+	seed := []struct {
+		value   int64
+		spentBy string
+	}{
+		{2e8, ""},
+		{50e8, "testlargestutxosspend"},
+		{9e8, ""},
+		{4e8, ""},
+	}
+	insertRows(t, sdb, "transactions",
+		"tx_hash, block_hash, block_height, is_valid, is_mainchain",
+		seedRow{txHash, txHash, height, true, true})
+	var voutRows, addrRows []seedRow
+	for i, out := range seed {
+		voutRows = append(voutRows, seedRow{txHash, i, 0, out.value,
+			[]byte{byte(i)}, pq.Array([]string{address})})
+		addrRows = append(addrRows, seedRow{address, out.spentBy, txHash, i,
+			-3600000 - i, out.value, time.Now(), true, true, 0})
+	}
+	insertRows(t, sdb, "vouts",
+		"tx_hash, tx_index, tx_tree, value, pkscript, script_addresses", voutRows...)
+	insertAddressRows(t, sdb, addrRows...)
+
+	utxos, err := RetrieveAddressLargestUTXOs(context.Background(), sdb,
+		address, 2, height+9)
+	if err != nil {
+		t.Fatalf("RetrieveAddressLargestUTXOs: %v", err)
+	}
+	if len(utxos) != 2 {
+		t.Fatalf("Incorrect number of UTXOs. Got %d, wanted 2.", len(utxos))
+	}
+	for i, want := range []uint32{2, 3} {
+		utxo := utxos[i]
+		if utxo.TxnID != txHash || utxo.Vout != want || utxo.Satoshis != seed[want].value ||
+			utxo.Height != height || utxo.Confirmations != 10 {
+			t.Errorf("Incorrect UTXO %d: %+v", i, utxo)
+		}
+	}
+}
+
+func TestRetrieveAgendaStakeTurnout(t *testing.T) {
+	sdb, rollback := openSeedDB(t)
+	defer rollback()
+
+	// Params with a single deployment voting far in the future, so that the
+	// window is projected beyond the best block.
+	const agendaID = "testturnout"
+	params := chaincfg.MainNetParams
+	params.Deployments = map[uint32][]chaincfg.ConsensusDeployment{
+		5: {{
+			Vote:       chaincfg.Vote{Id: agendaID},
+			StartTime:  uint64(time.Date(2300, 1, 1, 0, 0, 0, 0, time.UTC).Unix()),
+			ExpireTime: uint64(time.Date(2300, 6, 1, 0, 0, 0, 0, time.UTC).Unix()),
+		}},
+	}
+	ctx := context.Background()
+	startHeight, endHeight, err := RetrieveAgendaWindow(ctx, sdb, agendaID, &params)
+	if err != nil {
+		t.Fatalf("RetrieveAgendaWindow: %v", err)
+	}
+	// The window spans whole rule change intervals aligned to the stake
+	// validation height.
+	interval := int64(params.RuleChangeActivationInterval)
+	if (startHeight-params.StakeValidationHeight)%interval != 0 ||
+		(endHeight+1-startHeight)%interval != 0 {
+		t.Errorf("Misaligned voting window [%d, %d].", startHeight, endHeight)
+	}
+
+	// Seed 5 votes of each choice in the first block of the window, and votes
+	// just outside of the window or in a side chain block that must not count.
+	type agendaVote struct {
+		height      int64
+		choice      dbtypes.VoteChoice
+		isMainchain bool
+	}
+	seed := []agendaVote{
+		{startHeight - 1, dbtypes.Yes, true},
+		{endHeight + 1, dbtypes.Yes, true},
+		{startHeight, dbtypes.Yes, false},
+	}
+	for i := 0; i < 5; i++ {
+		seed = append(seed, agendaVote{startHeight, dbtypes.Yes, true},
+			agendaVote{startHeight, dbtypes.Abstain, true},
+			agendaVote{startHeight, dbtypes.No, true})
+	}
+	var voteRows, agendaRows []seedRow
+	for i, v := range seed {
+		voteHash := fmt.Sprintf("testturnout%d", i)
+		voteRows = append(voteRows, seedRow{v.height, voteHash, "", "", v.isMainchain})
+		agendaRows = append(agendaRows, seedRow{agendaID, v.choice, voteHash, v.height})
+	}
+	insertRows(t, sdb, "votes",
+		"height, tx_hash, block_hash, candidate_block_hash, is_mainchain", voteRows...)
+	insertRows(t, sdb, "agendas",
+		"agenda_id, agenda_vote_choice, tx_hash, block_height", agendaRows...)
+
+	turnout, err := RetrieveAgendaStakeTurnout(ctx, sdb, agendaID, &params)
+	if err != nil {
+		t.Fatalf("RetrieveAgendaStakeTurnout: %v", err)
+	}
+	maxVotes := (endHeight - startHeight + 1) * int64(params.TicketsPerBlock)
+	if want := 15 / float64(maxVotes); turnout != want {
+		t.Errorf("Incorrect turnout. Got %v, wanted %v.", turnout, want)
+	}
+}
+
+func TestRetrieveTopAddressesByBalance(t *testing.T) {
+	sdb, rollback := openSeedDB(t)
+	defer rollback()
+
+	// Seed addresses with balances larger than the total coin supply, so that
+	// they precede all real addresses. B is funded 5e16 and spends 1e16, and
+	// D spends everything it is funded.
+	insertAddressRows(t, sdb,
+		seedRow{"DsTestRichA", "", "testrich1", 0, -3700000, int64(3e16), time.Now(), true, true, 0},
+		seedRow{"DsTestRichB", "", "testrich2", 0, -3700001, int64(5e16), time.Now(), true, true, 0},
+		seedRow{"DsTestRichB", "", "testrich3", 0, -3700002, int64(1e16), time.Now(), false, true, 0},
+		seedRow{"DsTestRichC", "", "testrich4", 0, -3700003, int64(1.5e16), time.Now(), true, true, 0},
+		seedRow{"DsTestRichC", "", "testrich5", 0, -3700004, int64(0.5e16), time.Now(), true, true, 0},
+		seedRow{"DsTestRichD", "", "testrich6", 0, -3700005, int64(9e16), time.Now(), true, true, 0},
+		seedRow{"DsTestRichD", "", "testrich7", 0, -3700006, int64(9e16), time.Now(), false, true, 0})
+
+	expected := []dbtypes.AddressBalanceRow{
+		{Address: "DsTestRichB", Balance: 4e16, NumTxns: 2},
+		{Address: "DsTestRichA", Balance: 3e16, NumTxns: 1},
+		{Address: "DsTestRichC", Balance: 2e16, NumTxns: 2},
+	}
+	pages := []struct {
+		limit, offset int
+		want          []dbtypes.AddressBalanceRow
+	}{
+		{2, 0, expected[:2]},
+		{1, 2, expected[2:]},
+	}
+	for _, page := range pages {
+		rows, err := RetrieveTopAddressesByBalance(context.Background(), sdb,
+			page.limit, page.offset)
+		if err != nil {
+			t.Fatalf("RetrieveTopAddressesByBalance: %v", err)
+		}
+		if !reflect.DeepEqual(rows, page.want) {
+			t.Errorf("Incorrect page (limit %d, offset %d). Got %v, wanted %v.",
+				page.limit, page.offset, rows, page.want)
+		}
+	}
+}
+
+func TestRetrieveBlockDifficulty(t *testing.T) {
+	sdb, rollback := openSeedDB(t)
+	defer rollback()
+
+	const height = 330000100
+	insertRows(t, sdb, "blocks", "hash, height, difficulty, is_mainchain",
+		seedRow{"testdifficultymain", height, 12345678.9, true},
+		seedRow{"testdifficultyside", height, 98765.4, false})
+
+	difficulty, err := RetrieveBlockDifficulty(context.Background(), sdb, height)
+	if err != nil {
+		t.Fatalf("RetrieveBlockDifficulty: %v", err)
+	}
+	if difficulty != 12345678.9 {
+		t.Errorf("Incorrect difficulty. Got %f, wanted %f.", difficulty, 12345678.9)
+	}
+
+	_, err = RetrieveBlockDifficulty(context.Background(), sdb, height+1)
+	if err != sql.ErrNoRows {
+		t.Errorf("Expected sql.ErrNoRows for an unknown height, got %v.", err)
+	}
+}
+
+func TestRetrieveAddressRoleCounts(t *testing.T) {
+	sdb, rollback := openSeedDB(t)
+	defer rollback()
+
+	const address = "DsTestAddressRoles"
+	// Two outputs of testroles1 pay to the address, and testroles4 only counts
+	// once despite spending two previous outputs. The invalidated testroles5
+	// is not counted.
+	insertAddressRows(t, sdb,
+		seedRow{address, "", "testroles1", 0, -3800000, 1000, time.Now(), true, true, 0},
+		seedRow{address, "", "testroles1", 1, -3800001, 1000, time.Now(), true, true, 0},
+		seedRow{address, "", "testroles2", 2, -3800002, 1000, time.Now(), true, true, 0},
+		seedRow{address, "", "testroles3", 3, -3800003, 1000, time.Now(), false, true, 0},
+		seedRow{address, "", "testroles4", 4, -3800004, 1000, time.Now(), false, true, 0},
+		seedRow{address, "", "testroles4", 5, -3800005, 1000, time.Now(), false, true, 0},
+		seedRow{address, "", "testroles5", 6, -3800006, 1000, time.Now(), false, false, 0})
+
+	asSender, asReceiver, err := RetrieveAddressRoleCounts(context.Background(),
+		sdb, address)
+	if err != nil {
+		t.Fatalf("RetrieveAddressRoleCounts: %v", err)
+	}
+	if asSender != 2 || asReceiver != 2 {
+		t.Errorf("Incorrect role counts. Got %d as sender and %d as receiver, "+
+			"wanted 2 and 2.", asSender, asReceiver)
+	}
+}
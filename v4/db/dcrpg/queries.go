@@ -10,6 +10,7 @@ import (
 	"database/sql"
 	"encoding/hex"
 	"fmt"
+	"math"
 	"math/big"
 	"strings"
 	"time"
@@ -199,21 +200,33 @@ func DeleteDuplicateMisses(db *sql.DB) (int64, error) {
 	return sqlExec(db, internal.DeleteMissesDuplicateRows, execErrPrefix)
 }
 
+// abortTx closes the prepared statement and rolls back the database
+// transaction. This is used when the context of the database transaction is
+// cancelled, in which case the transaction may have already been rolled back,
+// so sql.ErrTxDone from Rollback is not reported.
+func abortTx(dbtx *sql.Tx, stmt *sql.Stmt) {
+	_ = stmt.Close()
+	if err := dbtx.Rollback(); err != nil && err != sql.ErrTxDone {
+		log.Errorf("Rollback failed: %v", err)
+	}
+}
+
 // --- stake (votes, tickets, misses) tables ---
 
 // InsertTickets takes a slice of *dbtypes.Tx and corresponding DB row IDs for
 // transactions, extracts the tickets, and inserts the tickets into the
 // database. Outputs are a slice of DB row IDs of the inserted tickets, and an
-// error.
-func InsertTickets(db *sql.DB, dbTxns []*dbtypes.Tx, txDbIDs []uint64, checked, updateExistingRecords bool) ([]uint64, []*dbtypes.Tx, error) {
-	dbtx, err := db.Begin()
+// error. If the context is cancelled, the database transaction is rolled back
+// and the context's error is returned.
+func InsertTickets(ctx context.Context, db *sql.DB, dbTxns []*dbtypes.Tx, txDbIDs []uint64, checked, updateExistingRecords bool) ([]uint64, []*dbtypes.Tx, error) {
+	dbtx, err := db.BeginTx(ctx, nil)
 	if err != nil {
 		return nil, nil, fmt.Errorf("unable to begin database transaction: %v", err)
 	}
 
 	// Prepare ticket insert statement, optionally updating a row if it conflicts
 	// with the unique index on (tx_hash, block_hash).
-	stmt, err := dbtx.Prepare(internal.MakeTicketInsertStatement(checked, updateExistingRecords))
+	stmt, err := dbtx.PrepareContext(ctx, internal.MakeTicketInsertStatement(checked, updateExistingRecords))
 	if err != nil {
 		log.Errorf("Ticket INSERT prepare: %v", err)
 		_ = dbtx.Rollback() // try, but we want the Prepare error back
@@ -233,6 +246,11 @@ func InsertTickets(db *sql.DB, dbTxns []*dbtypes.Tx, txDbIDs []uint64, checked,
 	// Insert each ticket
 	ids := make([]uint64, 0, len(ticketTx))
 	for i, tx := range ticketTx {
+		if err = ctx.Err(); err != nil {
+			abortTx(dbtx, stmt)
+			return nil, nil, err
+		}
+
 		// Reference Vouts[0] to determine stakesubmission address and if multisig
 		var stakesubmissionAddress string
 		var isMultisig bool
@@ -251,7 +269,7 @@ func InsertTickets(db *sql.DB, dbTxns []*dbtypes.Tx, txDbIDs []uint64, checked,
 		isSplit := tx.NumVin > 1
 
 		var id uint64
-		err := stmt.QueryRow(
+		err := stmt.QueryRowContext(ctx,
 			tx.TxID, tx.BlockHash, tx.BlockHeight, ticketDbIDs[i],
 			stakesubmissionAddress, isMultisig, isSplit, tx.NumVin,
 			price, fee, dbtypes.TicketUnspent, dbtypes.PoolStatusLive,
@@ -450,11 +468,19 @@ func InsertVotes(db *sql.DB, dbTxns []*dbtypes.Tx, _ /*txDbIDs*/ []uint64, fTx *
 	_ = agendaStmt.Close()
 
 	// If the validators are available, miss accounting should be accurate.
-	if len(msgBlock.Validators) > 0 && len(ids)+len(misses) != 5 {
-		fmt.Println(misses)
-		fmt.Println(voteTxs)
-		_ = dbtx.Rollback()
-		panic(fmt.Sprintf("votes (%d) + misses (%d) != 5", len(ids), len(misses)))
+	err = checkVoteAccounting(len(msgBlock.Validators) > 0, len(ids), len(misses))
+	if err != nil {
+		voteTxHashes := make([]string, 0, len(voteTxs))
+		for _, tx := range voteTxs {
+			voteTxHashes = append(voteTxHashes, tx.TxID)
+		}
+		log.Errorf("Vote accounting failed for block %v. Validators: %v, "+
+			"votes: %v, misses: %v", msgBlock.BlockHash(), msgBlock.Validators,
+			voteTxHashes, misses)
+		if errRoll := dbtx.Rollback(); errRoll != nil {
+			log.Errorf("Rollback failed: %v", errRoll)
+		}
+		return nil, nil, nil, nil, nil, err
 	}
 
 	// Store missed tickets.
@@ -495,6 +521,16 @@ func InsertVotes(db *sql.DB, dbTxns []*dbtypes.Tx, _ /*txDbIDs*/ []uint64, fTx *
 	return ids, voteTxs, spentTicketHashes, spentTicketDbIDs, missHashMap, dbtx.Commit()
 }
 
+// checkVoteAccounting checks that the votes and misses of a block account for
+// all five of the block's validators. Without the validators, the misses are
+// unknown and there is nothing to check.
+func checkVoteAccounting(haveValidators bool, numVotes, numMisses int) error {
+	if !haveValidators || numVotes+numMisses == 5 {
+		return nil
+	}
+	return fmt.Errorf("votes (%d) + misses (%d) != 5", numVotes, numMisses)
+}
+
 // RetrieveMissedVotesInBlock gets a list of ticket hashes that were called to
 // vote in the given block, but missed their vote.
 func RetrieveMissedVotesInBlock(ctx context.Context, db *sql.DB, blockHash string) (ticketHashes []string, err error) {
@@ -518,6 +554,27 @@ func RetrieveMissedVotesInBlock(ctx context.Context, db *sql.DB, blockHash strin
 	return
 }
 
+// RetrieveMissedTicketsForBlock gets the hashes of the tickets that were called
+// to vote in the given block but missed, along with the stakesubmission
+// address of each ticket.
+func RetrieveMissedTicketsForBlock(ctx context.Context, db *sql.DB, blockHash string) (ticketHashes []string, addresses []string, err error) {
+	rows, err := db.QueryContext(ctx, internal.SelectMissesWithAddressesInBlock, blockHash)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer closeRows(rows)
+
+	for rows.Next() {
+		var hash, address string
+		if err = rows.Scan(&hash, &address); err != nil {
+			return nil, nil, err
+		}
+		ticketHashes = append(ticketHashes, hash)
+		addresses = append(addresses, address)
+	}
+	return ticketHashes, addresses, rows.Err()
+}
+
 // RetrieveAllRevokes gets for all ticket revocations the row IDs (primary
 // keys), transaction hashes, block heights. It also gets the row ID in the vins
 // table for the first input of the revocation transaction, which should
@@ -550,6 +607,53 @@ func RetrieveAllRevokes(ctx context.Context, db *sql.DB) (ids []uint64, hashes [
 	return
 }
 
+// RetrieveContestedBlocks retrieves the hashes of the mainchain blocks with
+// heights in [from, to] whose votes split on the validity of the previous
+// block, along with the numbers of votes approving and disapproving it.
+func RetrieveContestedBlocks(ctx context.Context, db *sql.DB, from, to int64) ([]string, []int16, []int16, error) {
+	rows, err := db.QueryContext(ctx, internal.SelectContestedBlocks, from, to)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	defer closeRows(rows)
+
+	var blockHashes []string
+	var approves, disapproves []int16
+	for rows.Next() {
+		var blockHash string
+		var approve, disapprove int16
+		if err = rows.Scan(&blockHash, &approve, &disapprove); err != nil {
+			return nil, nil, nil, err
+		}
+		blockHashes = append(blockHashes, blockHash)
+		approves = append(approves, approve)
+		disapproves = append(disapproves, disapprove)
+	}
+	return blockHashes, approves, disapproves, rows.Err()
+}
+
+// RetrieveMissedVotesForTicket retrieves the heights of the blocks in which the
+// specified ticket was called to vote but missed, in increasing order. Misses
+// recorded in side chain blocks are included.
+func RetrieveMissedVotesForTicket(ctx context.Context, db *sql.DB, ticketHash string) (heights []int64, err error) {
+	var rows *sql.Rows
+	rows, err = db.QueryContext(ctx, internal.SelectMissesForTicket, ticketHash)
+	if err != nil {
+		return nil, err
+	}
+	defer closeRows(rows)
+
+	for rows.Next() {
+		var height int64
+		if err = rows.Scan(&height); err != nil {
+			return nil, err
+		}
+		heights = append(heights, height)
+	}
+	err = rows.Err()
+	return
+}
+
 // RetrieveAllVotesDbIDsHeightsTicketDbIDs gets for all votes the row IDs
 // (primary keys) in the votes table, the block heights, and the row IDs in the
 // tickets table of the spent tickets. This function is used in
@@ -590,11 +694,11 @@ func retrieveWindowBlocks(ctx context.Context, db *sql.DB, windowSize int64, lim
 	for rows.Next() {
 		var difficulty float64
 		var timestamp dbtypes.TimeDef
-		var startBlock, sbits, count int64
+		var startBlock, sbits, count, fees, txSizes int64
 		var blockSizes, votes, txs, revocations, tickets uint64
 
 		err = rows.Scan(&startBlock, &difficulty, &txs, &tickets, &votes,
-			&revocations, &blockSizes, &sbits, &timestamp.T, &count)
+			&revocations, &blockSizes, &sbits, &timestamp.T, &count, &fees, &txSizes)
 		if err != nil {
 			return nil, err
 		}
@@ -615,12 +719,181 @@ func retrieveWindowBlocks(ctx context.Context, db *sql.DB, windowSize int64, lim
 			Size:          int64(blockSizes),
 			FormattedSize: humanize.Bytes(blockSizes),
 			StartTime:     timestamp,
+			FeeRate:       averageFeeRate(fees, txSizes),
 		})
 	}
 
 	return data, nil
 }
 
+// retrieveReorgsPerWindow retrieves, for each window of windowSize blocks, the
+// number of heights at which a reorganization occurred (i.e. there is an
+// orphaned block in addition to the mainchain block). The window start
+// heights are recorded in Height, and the reorg counts in Count.
+func retrieveReorgsPerWindow(ctx context.Context, db *sql.DB, windowSize int64) (*dbtypes.ChartsData, error) {
+	rows, err := db.QueryContext(ctx, internal.SelectReorgsPerWindow, windowSize)
+	if err != nil {
+		return nil, err
+	}
+	defer closeRows(rows)
+
+	items := new(dbtypes.ChartsData)
+	for rows.Next() {
+		var windowStart, count uint64
+		if err = rows.Scan(&windowStart, &count); err != nil {
+			return nil, err
+		}
+
+		items.Height = append(items.Height, windowStart)
+		items.Count = append(items.Count, count)
+	}
+	return items, rows.Err()
+}
+
+// retrieveAvgTxPerBlockPerWindow retrieves, for each window of windowSize
+// blocks, the average number of transactions per mainchain block. The window
+// start heights are recorded in Height, and the averages in ValueF.
+func retrieveAvgTxPerBlockPerWindow(ctx context.Context, db *sql.DB, windowSize int64) (*dbtypes.ChartsData, error) {
+	rows, err := db.QueryContext(ctx, internal.SelectAvgTxPerBlockPerWindow, windowSize)
+	if err != nil {
+		return nil, err
+	}
+	defer closeRows(rows)
+
+	items := new(dbtypes.ChartsData)
+	for rows.Next() {
+		var windowStart uint64
+		var avgTxs float64
+		if err = rows.Scan(&windowStart, &avgTxs); err != nil {
+			return nil, err
+		}
+
+		items.Height = append(items.Height, windowStart)
+		items.ValueF = append(items.ValueF, avgTxs)
+	}
+	return items, rows.Err()
+}
+
+// RetrieveBusiestBlock retrieves the mainchain block with the most
+// transactions in the height range [from, to]. sql.ErrNoRows is returned if
+// there are no mainchain blocks in the range.
+func RetrieveBusiestBlock(ctx context.Context, db *sql.DB, from, to int64) (hash string, height int64, txCount int64, err error) {
+	err = db.QueryRowContext(ctx, internal.SelectBusiestBlock, from, to).Scan(
+		&hash, &height, &txCount)
+	return
+}
+
+// fullBlockFraction is the fraction of the maximum block size above which a
+// block is considered full.
+const fullBlockFraction = 0.8
+
+// retrieveBlockFullnessPerWindow retrieves, for each window of windowSize
+// blocks, the fraction of mainchain blocks with a size exceeding
+// fullBlockFraction of maxSize, an indicator of congestion. The window start
+// heights are recorded in Height, and the fractions in ValueF.
+func retrieveBlockFullnessPerWindow(ctx context.Context, db *sql.DB, windowSize, maxSize int64) (*dbtypes.ChartsData, error) {
+	threshold := int64(fullBlockFraction * float64(maxSize))
+	rows, err := db.QueryContext(ctx, internal.SelectBlockFullnessPerWindow,
+		windowSize, threshold)
+	if err != nil {
+		return nil, err
+	}
+	defer closeRows(rows)
+
+	items := new(dbtypes.ChartsData)
+	for rows.Next() {
+		var windowStart uint64
+		var fraction float64
+		if err = rows.Scan(&windowStart, &fraction); err != nil {
+			return nil, err
+		}
+
+		items.Height = append(items.Height, windowStart)
+		items.ValueF = append(items.ValueF, fraction)
+	}
+	return items, rows.Err()
+}
+
+// retrieveSplitTicketsPerWindow retrieves the number of split tickets, which
+// are purchased collaboratively with multiple inputs, mined in each window of
+// windowSize blocks. Windows are identified by their first block height.
+func retrieveSplitTicketsPerWindow(ctx context.Context, db *sql.DB, windowSize int64) (*dbtypes.ChartsData, error) {
+	rows, err := db.QueryContext(ctx, internal.SelectSplitTicketsPerWindow, windowSize)
+	if err != nil {
+		return nil, err
+	}
+	defer closeRows(rows)
+
+	items := new(dbtypes.ChartsData)
+	for rows.Next() {
+		var windowStart, count uint64
+		if err = rows.Scan(&windowStart, &count); err != nil {
+			return nil, err
+		}
+
+		items.Height = append(items.Height, windowStart)
+		items.Count = append(items.Count, count)
+	}
+	return items, rows.Err()
+}
+
+// retrieveUniqueVotersPerWindow retrieves, for each window of windowSize
+// blocks, the number of distinct stake submission addresses of the tickets
+// that voted in the window. Windows are identified by their first block
+// height, recorded in Height, with the address counts in Count.
+func retrieveUniqueVotersPerWindow(ctx context.Context, db *sql.DB, windowSize int64) (*dbtypes.ChartsData, error) {
+	rows, err := db.QueryContext(ctx, internal.SelectUniqueVotersPerWindow, windowSize)
+	if err != nil {
+		return nil, err
+	}
+	defer closeRows(rows)
+
+	items := new(dbtypes.ChartsData)
+	for rows.Next() {
+		var windowStart, count uint64
+		if err = rows.Scan(&windowStart, &count); err != nil {
+			return nil, err
+		}
+
+		items.Height = append(items.Height, windowStart)
+		items.Count = append(items.Count, count)
+	}
+	return items, rows.Err()
+}
+
+// RetrieveMultisigTicketCount retrieves the number of mainchain tickets
+// purchased to multisig addresses, as is done for pooled staking.
+func RetrieveMultisigTicketCount(ctx context.Context, db *sql.DB) (count int64, err error) {
+	err = db.QueryRowContext(ctx, internal.SelectMultisigTicketCount).Scan(&count)
+	return
+}
+
+// retrieveNewVsReusedAddresses retrieves, for each mainchain block with height
+// in the range [from, to] that involves any addresses, the number of distinct
+// addresses appearing in the chain for the first time and the number of
+// addresses that were already used in a previous block. This is expensive: the
+// full history of every address in the range is scanned to determine its first
+// appearance, so keep the range small.
+func retrieveNewVsReusedAddresses(ctx context.Context, db *sql.DB, from, to int64) (heights []int64, newCounts []int64, reusedCounts []int64, err error) {
+	rows, err := db.QueryContext(ctx, internal.SelectNewVsReusedAddressesByBlock, from, to)
+	if err != nil {
+		return
+	}
+	defer closeRows(rows)
+
+	for rows.Next() {
+		var height, newCount, reusedCount int64
+		if err = rows.Scan(&height, &newCount, &reusedCount); err != nil {
+			return
+		}
+		heights = append(heights, height)
+		newCounts = append(newCounts, newCount)
+		reusedCounts = append(reusedCounts, reusedCount)
+	}
+	err = rows.Err()
+	return
+}
+
 // retrieveTimeBasedBlockListing fetches blocks in chunks based on their block
 // time using the limit and offset provided. The time-based blocks groupings
 // include but are not limited to day, week, month and year.
@@ -636,10 +909,11 @@ func retrieveTimeBasedBlockListing(ctx context.Context, db *sql.DB, timeInterval
 	for rows.Next() {
 		var startTime, endTime, indexVal dbtypes.TimeDef
 		var txs, tickets, votes, revocations, blockSizes uint64
-		var blocksCount, endBlock int64
+		var blocksCount, endBlock, fees, txSizes int64
 
 		err = rows.Scan(&indexVal.T, &endBlock, &txs, &tickets, &votes,
-			&revocations, &blockSizes, &blocksCount, &startTime.T, &endTime.T)
+			&revocations, &blockSizes, &blocksCount, &startTime.T, &endTime.T,
+			&fees, &txSizes)
 		if err != nil {
 			return nil, err
 		}
@@ -657,11 +931,22 @@ func retrieveTimeBasedBlockListing(ctx context.Context, db *sql.DB, timeInterval
 			FormattedStartTime: startTime.T.Format("2006-01-02"),
 			EndTime:            endTime,
 			FormattedEndTime:   endTime.T.Format("2006-01-02"),
+			FeeRate:            averageFeeRate(fees, txSizes),
 		})
 	}
 	return data, nil
 }
 
+// averageFeeRate computes the fee rate, in atoms/byte, given the total fees
+// and total size of a group of transactions. The rate is zero for an empty
+// group.
+func averageFeeRate(fees, size int64) float64 {
+	if size <= 0 {
+		return 0
+	}
+	return float64(fees) / float64(size)
+}
+
 // RetrieveUnspentTickets gets all unspent tickets.
 func RetrieveUnspentTickets(ctx context.Context, db *sql.DB) (ids []uint64, hashes []string, err error) {
 	var rows *sql.Rows
@@ -839,6 +1124,62 @@ func retrieveTicketsGroupedByType(ctx context.Context, db *sql.DB) (*dbtypes.Poo
 	return &entry, nil
 }
 
+// retrieveRollingAvgTicketPrice retrieves the moving average of the ticket
+// prices paid over the trailing window of windowBlocks blocks, for each block
+// in which tickets were purchased.
+func retrieveRollingAvgTicketPrice(ctx context.Context, db *sql.DB, windowBlocks int64) (*dbtypes.ChartsData, error) {
+	rows, err := db.QueryContext(ctx, internal.SelectTicketsAvgPriceByBlock)
+	if err != nil {
+		return nil, err
+	}
+	defer closeRows(rows)
+
+	var heights []uint64
+	var prices []float64
+	for rows.Next() {
+		var height uint64
+		var price float64
+		if err = rows.Scan(&height, &price); err != nil {
+			return nil, err
+		}
+		heights = append(heights, height)
+		prices = append(prices, price)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return &dbtypes.ChartsData{
+		Height: heights,
+		ValueF: rollingAverage(heights, prices, windowBlocks),
+	}, nil
+}
+
+// rollingAverage computes, for each of the per-block values with the given
+// heights (in increasing order), the average of the values from the blocks in
+// the trailing window of windowBlocks blocks ending at that block. Blocks
+// without a value do not contribute to the average. Near the start of the
+// series, where a full window is not available, the average is of the blocks
+// that are available.
+func rollingAverage(heights []uint64, values []float64, windowBlocks int64) []float64 {
+	if windowBlocks < 1 {
+		windowBlocks = 1
+	}
+	avgs := make([]float64, len(values))
+	var sum float64
+	var start int
+	for i := range values {
+		sum += values[i]
+		// Drop blocks that have fallen out of the window.
+		for int64(heights[i])-int64(heights[start]) >= windowBlocks {
+			sum -= values[start]
+			start++
+		}
+		avgs[i] = sum / float64(i-start+1)
+	}
+	return avgs
+}
+
 func retrieveTicketSpendTypePerBlock(ctx context.Context, db *sql.DB) (*dbtypes.ChartsData, error) {
 	var items = new(dbtypes.ChartsData)
 	rows, err := db.QueryContext(ctx, internal.SelectTicketSpendTypeByBlock)
@@ -862,6 +1203,30 @@ func retrieveTicketSpendTypePerBlock(ctx context.Context, db *sql.DB) (*dbtypes.
 	return items, nil
 }
 
+// RetrieveTicketVoteIntervals retrieves the distribution of the number of
+// blocks between ticket purchase and vote for the mainchain votes. The
+// intervals are recorded in Value, and the number of votes with each interval
+// in Count.
+func RetrieveTicketVoteIntervals(ctx context.Context, db *sql.DB) (*dbtypes.ChartsData, error) {
+	rows, err := db.QueryContext(ctx, internal.SelectTicketVoteIntervals)
+	if err != nil {
+		return nil, err
+	}
+	defer closeRows(rows)
+
+	items := new(dbtypes.ChartsData)
+	for rows.Next() {
+		var blocks, count uint64
+		if err = rows.Scan(&blocks, &count); err != nil {
+			return nil, err
+		}
+
+		items.Value = append(items.Value, blocks)
+		items.Count = append(items.Count, count)
+	}
+	return items, rows.Err()
+}
+
 // SetPoolStatusForTickets sets the ticket pool status for the tickets specified
 // by db row ID.
 func SetPoolStatusForTickets(db *sql.DB, ticketDbIDs []uint64, poolStatuses []dbtypes.TicketPoolStatus) (int64, error) {
@@ -946,7 +1311,9 @@ func SetPoolStatusForTicketsByHash(db *sql.DB, tickets []string,
 
 // SetSpendingForTickets sets the spend type, spend height, spending transaction
 // row IDs (in the table relevant to the spend type), and ticket pool status for
-// the given tickets specified by their db row IDs.
+// the given tickets specified by their db row IDs. Each ticket is updated with
+// a separate statement, and a warning is logged for any ticket not updated
+// exactly once. SetSpendingForTicketsBulk is preferred for large batches.
 func SetSpendingForTickets(db *sql.DB, ticketDbIDs, spendDbIDs []uint64,
 	blockHeights []int64, spendTypes []dbtypes.TicketSpendType,
 	poolStatuses []dbtypes.TicketPoolStatus) (int64, error) {
@@ -984,6 +1351,36 @@ func SetSpendingForTickets(db *sql.DB, ticketDbIDs, spendDbIDs []uint64,
 	return totalTicketsUpdated, dbtx.Commit()
 }
 
+// SetSpendingForTicketsBulk is like SetSpendingForTickets, but it updates all
+// of the tickets with a single statement that takes the input slices as
+// arrays. The total number of updated tickets is returned, but tickets that
+// were not updated are not identified.
+func SetSpendingForTicketsBulk(db *sql.DB, ticketDbIDs, spendDbIDs []uint64,
+	blockHeights []int64, spendTypes []dbtypes.TicketSpendType,
+	poolStatuses []dbtypes.TicketPoolStatus) (int64, error) {
+	numTickets := len(ticketDbIDs)
+	if len(spendDbIDs) != numTickets || len(blockHeights) != numTickets ||
+		len(spendTypes) != numTickets || len(poolStatuses) != numTickets {
+		return 0, fmt.Errorf("mismatched ticket spending slice lengths")
+	}
+
+	ticketIDs := make([]int64, numTickets)
+	spendIDs := make([]int64, numTickets)
+	types := make([]int64, numTickets)
+	statuses := make([]int64, numTickets)
+	for i := range ticketDbIDs {
+		ticketIDs[i] = int64(ticketDbIDs[i])
+		spendIDs[i] = int64(spendDbIDs[i])
+		types[i] = int64(spendTypes[i])
+		statuses[i] = int64(poolStatuses[i])
+	}
+
+	return sqlExec(db, internal.SetTicketSpendingInfoForTicketDbIDs,
+		"failed to set ticket spending info: ", pq.Array(ticketIDs),
+		pq.Array(blockHeights), pq.Array(spendIDs), pq.Array(types),
+		pq.Array(statuses))
+}
+
 // setSpendingForTickets is identical to SetSpendingForTickets except it takes a
 // database transaction that was begun and will be committed by the caller.
 func setSpendingForTickets(dbtx *sql.Tx, ticketDbIDs, spendDbIDs []uint64,
@@ -1165,93 +1562,496 @@ func RetrieveAddressSpentUnspent(ctx context.Context, db *sql.DB, address string
 	return
 }
 
-// RetrieveAddressUTXOs gets the unspent transaction outputs (UTXOs) paying to
-// the specified address. The input current block height is used to compute
-// confirmations of the located transactions.
-func RetrieveAddressUTXOs(ctx context.Context, db *sql.DB, address string, currentBlockHeight int64) ([]apitypes.AddressTxnOutput, error) {
-	stmt, err := db.Prepare(internal.SelectAddressUnspentWithTxn)
-	if err != nil {
-		log.Error(err)
-		return nil, err
+// RetrieveAddressesUsed checks which of the given addresses have any on-chain
+// activity, using a single query for the batch. The returned map has an entry
+// for every input address, with false for addresses without activity.
+func RetrieveAddressesUsed(ctx context.Context, db *sql.DB, addresses []string) (map[string]bool, error) {
+	used := make(map[string]bool, len(addresses))
+	for _, addr := range addresses {
+		used[addr] = false
 	}
 
-	rows, err := stmt.QueryContext(ctx, address)
-	// _ = stmt.Close() // or does Rows.Close() do it?
+	rows, err := db.QueryContext(ctx, internal.SelectAddressesUsed, pq.Array(addresses))
 	if err != nil {
-		log.Error(err)
 		return nil, err
 	}
 	defer closeRows(rows)
 
-	var outputs []apitypes.AddressTxnOutput
 	for rows.Next() {
-		pkScript := []byte{}
-		var blockHeight, atoms int64
-		var blocktime dbtypes.TimeDef
-		txnOutput := apitypes.AddressTxnOutput{}
-		if err = rows.Scan(&txnOutput.Address, &txnOutput.TxnID,
-			&atoms, &blockHeight, &blocktime.T, &txnOutput.Vout, &pkScript); err != nil {
-			log.Error(err)
+		var addr string
+		if err = rows.Scan(&addr); err != nil {
+			return nil, err
 		}
-		txnOutput.ScriptPubKey = hex.EncodeToString(pkScript)
-		txnOutput.Amount = dcrutil.Amount(atoms).ToCoin()
-		txnOutput.Satoshis = atoms
-		txnOutput.Height = blockHeight
-		txnOutput.Confirmations = currentBlockHeight - blockHeight + 1
-		outputs = append(outputs, txnOutput)
+		used[addr] = true
 	}
-
-	return outputs, nil
-}
-
-// RetrieveAddressTxnsOrdered will get all transactions for addresses provided
-// and return them sorted by time in descending order. It will also return a
-// short list of recently (defined as greater than recentBlockHeight) confirmed
-// transactions that can be used to validate mempool status.
-func RetrieveAddressTxnsOrdered(ctx context.Context, db *sql.DB, addresses []string, recentBlockHeight int64) (txs []string, recenttxs []string, err error) {
-	var txHash string
-	var height int64
-	var stmt *sql.Stmt
-	stmt, err = db.Prepare(internal.SelectAddressesAllTxn)
-	if err != nil {
-		return nil, nil, err
+	if err = rows.Err(); err != nil {
+		return nil, err
 	}
+	return used, nil
+}
 
-	var rows *sql.Rows
-	rows, err = stmt.QueryContext(ctx, pq.Array(addresses))
-	// _ = stmt.Close() // or does Rows.Close do it?
+// RetrieveAddressValueDenominations retrieves, for the unspent outputs of the
+// given address, the number of outputs with each distinct value. The map is
+// keyed by output value in atoms. Many outputs of the same round value may
+// indicate mixing or structured payments.
+func RetrieveAddressValueDenominations(ctx context.Context, db *sql.DB, address string) (map[int64]int64, error) {
+	rows, err := db.QueryContext(ctx, internal.SelectAddressUnspentValueCounts, address)
 	if err != nil {
-		return nil, nil, err
+		return nil, err
 	}
 	defer closeRows(rows)
 
+	denominations := make(map[int64]int64)
 	for rows.Next() {
-		err = rows.Scan(&txHash, &height)
-		if err != nil {
-			return // return what we got, plus the error
-		}
-		txs = append(txs, txHash)
-		if height > recentBlockHeight {
-			recenttxs = append(recenttxs, txHash)
+		var value, count int64
+		if err = rows.Scan(&value, &count); err != nil {
+			return nil, err
 		}
+		denominations[value] = count
 	}
-	return
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	return denominations, nil
 }
 
-// RetrieveAllAddressTxns retrieves all rows of the address table pertaining to
-// the given address.
-func RetrieveAllAddressTxns(ctx context.Context, db *sql.DB, address string) ([]uint64, []*dbtypes.AddressRow, error) {
-	rows, err := db.QueryContext(ctx, internal.SelectAddressAllByAddress, address)
+// RetrieveTxOutputAddresses retrieves the addresses paid by the outputs of the
+// transaction with the given hash, along with the corresponding output values
+// and indexes. Outputs paying to multiple addresses (e.g. multisig) contribute
+// an entry for each address.
+func RetrieveTxOutputAddresses(ctx context.Context, db *sql.DB, txHash string) (addresses []string, values []int64, voutIndexes []uint32, err error) {
+	rows, err := db.QueryContext(ctx, internal.SelectAddressesByFundingTx, txHash)
 	if err != nil {
-		return nil, nil, err
+		return
 	}
-
 	defer closeRows(rows)
 
-	return scanAddressQueryRows(rows)
-}
-
-func RetrieveAddressTxns(ctx context.Context, db *sql.DB, address string, N, offset int64) ([]uint64, []*dbtypes.AddressRow, error) {
+	for rows.Next() {
+		var addr string
+		var value int64
+		var voutIndex uint32
+		if err = rows.Scan(&addr, &value, &voutIndex); err != nil {
+			return
+		}
+		addresses = append(addresses, addr)
+		values = append(values, value)
+		voutIndexes = append(voutIndexes, voutIndex)
+	}
+	err = rows.Err()
+	return
+}
+
+// RetrieveDistinctFundedAddressesInBlock counts the distinct addresses paid by
+// the outputs of the transactions in the block with the given hash.
+func RetrieveDistinctFundedAddressesInBlock(ctx context.Context, db *sql.DB, blockHash string) (count int64, err error) {
+	err = db.QueryRowContext(ctx, internal.SelectDistinctFundedAddressesInBlock,
+		blockHash).Scan(&count)
+	return
+}
+
+// RetrieveAddressBalanceSnapshots retrieves the confirmed balance of the
+// address as of each of the given block heights. The balances are returned in
+// the same order as heights.
+func RetrieveAddressBalanceSnapshots(ctx context.Context, db *sql.DB, address string,
+	heights []int64) ([]int64, error) {
+	balances := make([]int64, len(heights))
+	if len(heights) == 0 {
+		return balances, nil
+	}
+
+	rows, err := db.QueryContext(ctx, internal.SelectAddressBalanceSnapshots,
+		address, pq.Array(heights))
+	if err != nil {
+		return nil, err
+	}
+	defer closeRows(rows)
+
+	for rows.Next() {
+		var idx, balance int64
+		if err = rows.Scan(&idx, &balance); err != nil {
+			return nil, err
+		}
+		// WITH ORDINALITY numbers the heights from 1.
+		if idx < 1 || idx > int64(len(balances)) {
+			return nil, fmt.Errorf("unexpected snapshot index %d", idx)
+		}
+		balances[idx-1] = balance
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return balances, nil
+}
+
+const (
+	// maxAddressGraphDepth is the largest number of hops from the address that
+	// RetrieveAddressTxGraph will follow.
+	maxAddressGraphDepth = 3
+	// maxAddressGraphNodes is the largest number of addresses in a graph
+	// returned by RetrieveAddressTxGraph.
+	maxAddressGraphNodes = 100
+)
+
+// RetrieveAddressTxGraph retrieves the transaction flow graph around the
+// address. The nodes are addresses, starting with the given address, and each
+// edge is a directed (sender, receiver) pair of addresses in a transaction.
+// Counterparties are followed for up to depth hops, limited to
+// maxAddressGraphDepth, and the graph is truncated at maxAddressGraphNodes
+// nodes. Edges are only included if both of their nodes are in the graph.
+func RetrieveAddressTxGraph(ctx context.Context, db *sql.DB, address string,
+	depth int) (nodes []string, edges [][2]string, err error) {
+	if depth > maxAddressGraphDepth {
+		depth = maxAddressGraphDepth
+	}
+
+	nodes = []string{address}
+	inGraph := map[string]bool{address: true}
+	haveEdge := make(map[[2]string]bool)
+	frontier := []string{address}
+	for hop := 0; hop < depth && len(frontier) > 0; hop++ {
+		var hopEdges [][2]string
+		hopEdges, err = retrieveAddressFlowEdges(ctx, db, frontier)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		// Addresses first reached in this hop are the next hop's frontier.
+		frontier = nil
+		for _, edge := range hopEdges {
+			for _, addr := range edge {
+				if !inGraph[addr] && len(nodes) < maxAddressGraphNodes {
+					inGraph[addr] = true
+					nodes = append(nodes, addr)
+					frontier = append(frontier, addr)
+				}
+			}
+			if inGraph[edge[0]] && inGraph[edge[1]] && !haveEdge[edge] {
+				haveEdge[edge] = true
+				edges = append(edges, edge)
+			}
+		}
+	}
+	return nodes, edges, nil
+}
+
+// retrieveAddressFlowEdges retrieves the distinct (sender, receiver) address
+// pairs of the transactions involving any of the addresses. The number of
+// pairs is limited so that a few very active addresses cannot return an
+// unbounded result.
+func retrieveAddressFlowEdges(ctx context.Context, db *sql.DB, addresses []string) ([][2]string, error) {
+	maxEdges := 2 * maxAddressGraphNodes * len(addresses)
+	rows, err := db.QueryContext(ctx, internal.SelectAddressFlowEdges,
+		pq.Array(addresses), maxEdges)
+	if err != nil {
+		return nil, err
+	}
+	defer closeRows(rows)
+
+	var edges [][2]string
+	for rows.Next() {
+		var edge [2]string
+		if err = rows.Scan(&edge[0], &edge[1]); err != nil {
+			return nil, err
+		}
+		edges = append(edges, edge)
+	}
+	return edges, rows.Err()
+}
+
+// RetrieveTopAddressesByBalance retrieves a page of up to limit addresses,
+// starting at offset, in the list of addresses with a positive balance ordered
+// by balance, largest first.
+func RetrieveTopAddressesByBalance(ctx context.Context, db *sql.DB, limit, offset int) ([]dbtypes.AddressBalanceRow, error) {
+	rows, err := db.QueryContext(ctx, internal.SelectAddressesByBalance, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer closeRows(rows)
+
+	var balances []dbtypes.AddressBalanceRow
+	for rows.Next() {
+		var row dbtypes.AddressBalanceRow
+		if err = rows.Scan(&row.Address, &row.Balance, &row.NumTxns); err != nil {
+			return nil, err
+		}
+		balances = append(balances, row)
+	}
+	return balances, rows.Err()
+}
+
+// RetrieveAddressImmatureBalance retrieves the combined value of the unspent
+// coinbase and stakebase outputs paying to the address that have not yet
+// reached coinbase maturity as of the block at height tipHeight.
+func RetrieveAddressImmatureBalance(ctx context.Context, db *sql.DB, address string,
+	tipHeight, coinbaseMaturity int64) (int64, error) {
+	rows, err := db.QueryContext(ctx, internal.SelectAddressUnspentCoinbaseStakebase,
+		address, tipHeight-coinbaseMaturity)
+	if err != nil {
+		return 0, err
+	}
+	defer closeRows(rows)
+
+	var values, heights []int64
+	for rows.Next() {
+		var value, height int64
+		if err = rows.Scan(&value, &height); err != nil {
+			return 0, err
+		}
+		values = append(values, value)
+		heights = append(heights, height)
+	}
+	if err = rows.Err(); err != nil {
+		return 0, err
+	}
+
+	return immatureValue(values, heights, tipHeight, coinbaseMaturity), nil
+}
+
+// immatureValue sums the values of the coinbase/stakebase outputs mined at
+// the corresponding heights that have fewer than coinbaseMaturity
+// confirmations as of the block at height tipHeight.
+func immatureValue(values, heights []int64, tipHeight, coinbaseMaturity int64) (immature int64) {
+	for i := range values {
+		if i >= len(heights) {
+			break
+		}
+		confirmations := tipHeight - heights[i] + 1
+		if confirmations < coinbaseMaturity {
+			immature += values[i]
+		}
+	}
+	return
+}
+
+// RetrievePureHolderAddresses retrieves up to limit addresses that have
+// received funds but never spent any, and that have a balance of at least
+// minBalance atoms. The addresses and their balances are ordered by balance,
+// largest first.
+func RetrievePureHolderAddresses(ctx context.Context, db *sql.DB, minBalance int64, limit int) (addresses []string, balances []int64, err error) {
+	rows, err := db.QueryContext(ctx, internal.SelectPureHolderAddresses, minBalance, limit)
+	if err != nil {
+		return
+	}
+	defer closeRows(rows)
+
+	for rows.Next() {
+		var addr string
+		var balance int64
+		if err = rows.Scan(&addr, &balance); err != nil {
+			return
+		}
+		addresses = append(addresses, addr)
+		balances = append(balances, balance)
+	}
+	err = rows.Err()
+	return
+}
+
+// RetrieveTxHasUnspentOutputs checks if the transaction with the given hash
+// has any unspent outputs, and returns the number of unspent outputs.
+func RetrieveTxHasUnspentOutputs(ctx context.Context, db *sql.DB, txHash string) (bool, int, error) {
+	var numUnspent int
+	err := db.QueryRowContext(ctx, internal.SelectTxUnspentOutputCount, txHash).Scan(&numUnspent)
+	return numUnspent > 0, numUnspent, err
+}
+
+// RetrieveAddressTxnsInTimeRange retrieves the valid mainchain addresses table
+// rows for the given address with block times in the range [start, end],
+// oldest first. Only the Address, TxHash, IsFunding, Value, and TxBlockTime
+// fields of the rows are set.
+func RetrieveAddressTxnsInTimeRange(ctx context.Context, db *sql.DB, address string,
+	start, end time.Time) ([]*dbtypes.AddressRow, error) {
+	rows, err := db.QueryContext(ctx, internal.SelectAddressTxnsInTimeRange,
+		address, start.UTC(), end.UTC())
+	if err != nil {
+		return nil, err
+	}
+	defer closeRows(rows)
+
+	var addressRows []*dbtypes.AddressRow
+	for rows.Next() {
+		addr := dbtypes.AddressRow{Address: address, ValidMainChain: true}
+		err = rows.Scan(&addr.TxHash, &addr.IsFunding, &addr.Value, &addr.TxBlockTime.T)
+		if err != nil {
+			return nil, err
+		}
+		addressRows = append(addressRows, &addr)
+	}
+	return addressRows, rows.Err()
+}
+
+// RetrieveTotalUniqueAddresses retrieves the number of distinct addresses that
+// have ever appeared in the addresses table. This scans the entire table, so
+// callers should cache the result rather than query it for every request.
+func RetrieveTotalUniqueAddresses(ctx context.Context, db *sql.DB) (count int64, err error) {
+	err = db.QueryRowContext(ctx, internal.SelectTotalUniqueAddresses).Scan(&count)
+	return
+}
+
+// RetrieveAddressRowsForExport calls fn for every valid mainchain row of the
+// addresses table for the given address, in chronological order. The rows are
+// not buffered, so addresses with very long histories may be exported with a
+// streaming writer. Iteration stops if fn returns an error, which is returned.
+func RetrieveAddressRowsForExport(ctx context.Context, db *sql.DB, address string,
+	fn func(*dbtypes.AddressExportRow) error) error {
+	rows, err := db.QueryContext(ctx, internal.SelectAddressRowsForExport, address)
+	if err != nil {
+		return err
+	}
+	defer closeRows(rows)
+
+	for rows.Next() {
+		var row dbtypes.AddressExportRow
+		err = rows.Scan(&row.TxHash, &row.TxVinVoutIndex, &row.IsFunding,
+			&row.Value, &row.BlockTime.T, &row.BlockHeight)
+		if err != nil {
+			return err
+		}
+		if err = fn(&row); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+// RetrieveMostFragmentedAddresses retrieves the N addresses with the most
+// unspent outputs, which are candidates for consolidation. The addresses and
+// their numbers of unspent outputs are ordered by the count, largest first.
+func RetrieveMostFragmentedAddresses(ctx context.Context, db *sql.DB, N int) ([]string, []int64, error) {
+	rows, err := db.QueryContext(ctx, internal.SelectAddressesMostUnspentOutputs, N)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer closeRows(rows)
+
+	var addresses []string
+	var counts []int64
+	for rows.Next() {
+		var addr string
+		var count int64
+		if err = rows.Scan(&addr, &count); err != nil {
+			return nil, nil, err
+		}
+		addresses = append(addresses, addr)
+		counts = append(counts, count)
+	}
+	return addresses, counts, rows.Err()
+}
+
+// RetrieveAddressUTXOs gets the unspent transaction outputs (UTXOs) paying to
+// the specified address. The input current block height is used to compute
+// confirmations of the located transactions.
+func RetrieveAddressUTXOs(ctx context.Context, db *sql.DB, address string, currentBlockHeight int64) ([]apitypes.AddressTxnOutput, error) {
+	stmt, err := db.Prepare(internal.SelectAddressUnspentWithTxn)
+	if err != nil {
+		log.Error(err)
+		return nil, err
+	}
+
+	rows, err := stmt.QueryContext(ctx, address)
+	// _ = stmt.Close() // or does Rows.Close() do it?
+	if err != nil {
+		log.Error(err)
+		return nil, err
+	}
+	defer closeRows(rows)
+
+	return scanAddressUTXOs(rows, currentBlockHeight), nil
+}
+
+// RetrieveAddressLargestUTXOs gets the n unspent transaction outputs (UTXOs)
+// paying to the specified address with the largest values, largest first. The
+// input current block height is used to compute confirmations of the located
+// transactions.
+func RetrieveAddressLargestUTXOs(ctx context.Context, db *sql.DB, address string, n int, currentBlockHeight int64) ([]apitypes.AddressTxnOutput, error) {
+	if n <= 0 {
+		return nil, nil
+	}
+
+	rows, err := db.QueryContext(ctx, internal.SelectAddressLargestUnspentWithTxn,
+		address, n)
+	if err != nil {
+		log.Error(err)
+		return nil, err
+	}
+	defer closeRows(rows)
+
+	return scanAddressUTXOs(rows, currentBlockHeight), nil
+}
+
+// scanAddressUTXOs scans the rows of an address UTXO query, computing
+// confirmations relative to the block at height currentBlockHeight.
+func scanAddressUTXOs(rows *sql.Rows, currentBlockHeight int64) []apitypes.AddressTxnOutput {
+	var outputs []apitypes.AddressTxnOutput
+	for rows.Next() {
+		pkScript := []byte{}
+		var blockHeight, atoms int64
+		var blocktime dbtypes.TimeDef
+		txnOutput := apitypes.AddressTxnOutput{}
+		if err := rows.Scan(&txnOutput.Address, &txnOutput.TxnID,
+			&atoms, &blockHeight, &blocktime.T, &txnOutput.Vout, &pkScript); err != nil {
+			log.Error(err)
+		}
+		txnOutput.ScriptPubKey = hex.EncodeToString(pkScript)
+		txnOutput.Amount = dcrutil.Amount(atoms).ToCoin()
+		txnOutput.Satoshis = atoms
+		txnOutput.Height = blockHeight
+		txnOutput.Confirmations = currentBlockHeight - blockHeight + 1
+		outputs = append(outputs, txnOutput)
+	}
+
+	return outputs
+}
+
+// RetrieveAddressTxnsOrdered will get all transactions for addresses provided
+// and return them sorted by time in descending order. It will also return a
+// short list of recently (defined as greater than recentBlockHeight) confirmed
+// transactions that can be used to validate mempool status.
+func RetrieveAddressTxnsOrdered(ctx context.Context, db *sql.DB, addresses []string, recentBlockHeight int64) (txs []string, recenttxs []string, err error) {
+	var txHash string
+	var height int64
+	var stmt *sql.Stmt
+	stmt, err = db.Prepare(internal.SelectAddressesAllTxn)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var rows *sql.Rows
+	rows, err = stmt.QueryContext(ctx, pq.Array(addresses))
+	// _ = stmt.Close() // or does Rows.Close do it?
+	if err != nil {
+		return nil, nil, err
+	}
+	defer closeRows(rows)
+
+	for rows.Next() {
+		err = rows.Scan(&txHash, &height)
+		if err != nil {
+			return // return what we got, plus the error
+		}
+		txs = append(txs, txHash)
+		if height > recentBlockHeight {
+			recenttxs = append(recenttxs, txHash)
+		}
+	}
+	return
+}
+
+// RetrieveAllAddressTxns retrieves all rows of the address table pertaining to
+// the given address.
+func RetrieveAllAddressTxns(ctx context.Context, db *sql.DB, address string) ([]uint64, []*dbtypes.AddressRow, error) {
+	rows, err := db.QueryContext(ctx, internal.SelectAddressAllByAddress, address)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	defer closeRows(rows)
+
+	return scanAddressQueryRows(rows)
+}
+
+func RetrieveAddressTxns(ctx context.Context, db *sql.DB, address string, N, offset int64) ([]uint64, []*dbtypes.AddressRow, error) {
 	return retrieveAddressTxns(ctx, db, address, N, offset,
 		internal.SelectAddressLimitNByAddress, false)
 }
@@ -1271,6 +2071,52 @@ func RetrieveAddressMergedDebitTxns(ctx context.Context, db *sql.DB, address str
 		internal.SelectAddressMergedDebitView, true)
 }
 
+// RetrieveAddressMergedCreditTxns retrieves the funding transactions of the
+// address, with the outputs of each transaction paying to the address merged
+// into a single row. The returned rows have their MergedCreditCount set to
+// the number of merged outputs, and no row IDs are returned.
+func RetrieveAddressMergedCreditTxns(ctx context.Context, db *sql.DB, address string, N, offset int64) ([]uint64, []*dbtypes.AddressRow, error) {
+	rows, err := db.QueryContext(ctx, internal.SelectAddressMergedCreditView,
+		address, N, offset)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer closeRows(rows)
+
+	addr, err := scanPartialAddressQueryRows(rows, address, true)
+	return nil, addr, err
+}
+
+// RetrieveAddressTxnsByType retrieves the address rows of the specified
+// transaction type (all, credit, debit, or merged debit) for the given address,
+// limited to N rows starting at offset. For AddrMergedTxnDebit, the returned
+// row IDs are nil since merged rows do not correspond to a single table row.
+func RetrieveAddressTxnsByType(ctx context.Context, db *sql.DB, address string,
+	txnType dbtypes.AddrTxnType, N, offset int64) ([]uint64, []*dbtypes.AddressRow, error) {
+	statement, isMergedDebitView, err := addressTxnsStatement(txnType)
+	if err != nil {
+		return nil, nil, err
+	}
+	return retrieveAddressTxns(ctx, db, address, N, offset, statement, isMergedDebitView)
+}
+
+// addressTxnsStatement maps the AddrTxnType to the address rows query, also
+// indicating if the query is of the merged debit view.
+func addressTxnsStatement(txnType dbtypes.AddrTxnType) (string, bool, error) {
+	switch txnType {
+	case dbtypes.AddrTxnAll:
+		return internal.SelectAddressLimitNByAddress, false, nil
+	case dbtypes.AddrTxnCredit:
+		return internal.SelectAddressCreditsLimitNByAddress, false, nil
+	case dbtypes.AddrTxnDebit:
+		return internal.SelectAddressDebitsLimitNByAddress, false, nil
+	case dbtypes.AddrMergedTxnDebit:
+		return internal.SelectAddressMergedDebitView, true, nil
+	default:
+		return "", false, fmt.Errorf("unknown AddrTxnType %v", txnType)
+	}
+}
+
 func retrieveAddressTxns(ctx context.Context, db *sql.DB, address string, N, offset int64,
 	statement string, isMergedDebitView bool) ([]uint64, []*dbtypes.AddressRow, error) {
 	rows, err := db.QueryContext(ctx, statement, address, N, offset)
@@ -1281,19 +2127,26 @@ func retrieveAddressTxns(ctx context.Context, db *sql.DB, address string, N, off
 	defer closeRows(rows)
 
 	if isMergedDebitView {
-		addr, err := scanPartialAddressQueryRows(rows, address)
+		addr, err := scanPartialAddressQueryRows(rows, address, false)
 		return nil, addr, err
 	}
 	return scanAddressQueryRows(rows)
 }
 
-func scanPartialAddressQueryRows(rows *sql.Rows, addr string) (addressRows []*dbtypes.AddressRow, err error) {
+// scanPartialAddressQueryRows scans the rows of a merged view query. The merged
+// count is stored in MergedCreditCount for funding rows, and MergedDebitCount
+// otherwise.
+func scanPartialAddressQueryRows(rows *sql.Rows, addr string, isFunding bool) (addressRows []*dbtypes.AddressRow, err error) {
 	for rows.Next() {
-		var addr = dbtypes.AddressRow{Address: addr}
+		var addr = dbtypes.AddressRow{Address: addr, IsFunding: isFunding}
 		var blockTime dbtypes.TimeDef
 
+		mergedCount := &addr.MergedDebitCount
+		if isFunding {
+			mergedCount = &addr.MergedCreditCount
+		}
 		err = rows.Scan(&addr.TxHash, &addr.ValidMainChain, &blockTime.T,
-			&addr.Value, &addr.MergedDebitCount)
+			&addr.Value, mergedCount)
 		addr.TxBlockTime = blockTime
 		if err != nil {
 			return
@@ -1372,6 +2225,24 @@ func retrieveOldestTxBlockTime(ctx context.Context, db *sql.DB, addr string) (bl
 	return
 }
 
+// RetrieveAddressActivitySpan retrieves the block times of the first and last
+// transactions involving the given address, and the number of address rows,
+// in a single query. For an address with no transactions, the times are zero
+// and txCount is 0.
+func RetrieveAddressActivitySpan(ctx context.Context, db *sql.DB, address string) (first, last dbtypes.TimeDef, txCount int64, err error) {
+	var firstTime, lastTime sql.NullTime
+	err = db.QueryRowContext(ctx, internal.SelectAddressActivitySpan, address).
+		Scan(&firstTime, &lastTime, &txCount)
+	if err == sql.ErrNoRows {
+		err = nil
+	}
+	if err != nil {
+		return
+	}
+	first, last = timeDefFromNullTime(firstTime), timeDefFromNullTime(lastTime)
+	return
+}
+
 // retrieveTxHistoryByType fetches the transaction types count for all the
 // transactions associated with a given address for the given time interval.
 // The time interval is grouping records by week, month, year, day and all.
@@ -1497,47 +2368,159 @@ func InsertVin(db *sql.DB, dbVin dbtypes.VinTxProperty, checked bool, updateOnCo
 }
 
 // InsertVins is like InsertVin, except that it operates on a slice of vin data.
-func InsertVins(db *sql.DB, dbVins dbtypes.VinTxPropertyARRAY, checked bool, updateOnConflict ...bool) ([]uint64, error) {
-	dbtx, err := db.Begin()
-	if err != nil {
-		return nil, fmt.Errorf("unable to begin database transaction: %v", err)
+// When checked=false, the vins are bulk loaded with CopyInVins. Otherwise, the
+// vins are inserted one row at a time so that conflicts may be handled. If the
+// context is cancelled, the database transaction is rolled back and the
+// context's error is returned.
+func InsertVins(ctx context.Context, db *sql.DB, dbVins dbtypes.VinTxPropertyARRAY, checked bool, updateOnConflict ...bool) ([]uint64, error) {
+	if !checked {
+		return CopyInVins(ctx, db, dbVins)
 	}
 
 	doUpsert := true
 	if len(updateOnConflict) > 0 {
 		doUpsert = updateOnConflict[0]
 	}
-	stmt, err := dbtx.Prepare(internal.MakeVinInsertStatement(checked, doUpsert))
+	return insertVinsByRow(ctx, db, dbVins, checked, doUpsert)
+}
+
+// insertVinsByRow inserts the vins in a single database transaction, executing
+// the insert statement appropriate for the conflict checking and handling
+// behavior once for each vin.
+func insertVinsByRow(ctx context.Context, db *sql.DB, dbVins dbtypes.VinTxPropertyARRAY, checked, doUpsert bool) ([]uint64, error) {
+	dbtx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("unable to begin database transaction: %v", err)
+	}
+
+	ids, err := insertVinsByRowTx(ctx, dbtx, dbVins, checked, doUpsert)
+	if err != nil {
+		if errRoll := dbtx.Rollback(); errRoll != nil && errRoll != sql.ErrTxDone {
+			log.Errorf("Rollback failed: %v", errRoll)
+		}
+		return ids, err
+	}
+
+	return ids, dbtx.Commit()
+}
+
+// insertVinsByRowTx is like insertVinsByRow, but inserts the vins in the given
+// database transaction, which the caller must commit or roll back.
+func insertVinsByRowTx(ctx context.Context, dbtx *sql.Tx, dbVins dbtypes.VinTxPropertyARRAY, checked, doUpsert bool) ([]uint64, error) {
+	stmt, err := dbtx.PrepareContext(ctx, internal.MakeVinInsertStatement(checked, doUpsert))
 	if err != nil {
 		log.Errorf("Vin INSERT prepare: %v", err)
-		_ = dbtx.Rollback() // try, but we want the Prepare error back
 		return nil, err
 	}
+	// Close prepared statement. Ignore errors as the caller will Commit or
+	// Rollback regardless.
+	defer stmt.Close()
 
 	// TODO/Question: Should we skip inserting coinbase txns, which have same PrevTxHash?
 
 	ids := make([]uint64, 0, len(dbVins))
 	for _, vin := range dbVins {
+		if err = ctx.Err(); err != nil {
+			return nil, err
+		}
+
 		var id uint64
-		err = stmt.QueryRow(vin.TxID, vin.TxIndex, vin.TxTree,
+		err = stmt.QueryRowContext(ctx, vin.TxID, vin.TxIndex, vin.TxTree,
 			vin.PrevTxHash, vin.PrevTxIndex, vin.PrevTxTree,
 			vin.ValueIn, vin.IsValid, vin.IsMainchain, vin.Time.T, vin.TxType).Scan(&id)
 		if err != nil {
-			_ = stmt.Close() // try, but we want the QueryRow error back
-			if errRoll := dbtx.Rollback(); errRoll != nil {
-				log.Errorf("Rollback failed: %v", errRoll)
-			}
 			return ids, fmt.Errorf("InsertVins INSERT exec failed: %v", err)
 		}
 		ids = append(ids, id)
 	}
 
-	// Close prepared statement. Ignore errors as we'll Commit regardless.
-	_ = stmt.Close()
+	return ids, nil
+}
+
+// CopyInVins bulk loads the vins into the vins table using COPY, returning the
+// ids of the new rows in the same order as the input vins. Since COPY cannot
+// handle conflicts, this should only be used before the unique indexes are
+// created. The ids are reserved from the vins id sequence prior to the COPY.
+// If any step fails, the database transaction is rolled back and no vins are
+// inserted. If the context is cancelled, the context's error is returned.
+func CopyInVins(ctx context.Context, db *sql.DB, dbVins dbtypes.VinTxPropertyARRAY) ([]uint64, error) {
+	if len(dbVins) == 0 {
+		return []uint64{}, nil
+	}
+
+	dbtx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("unable to begin database transaction: %v", err)
+	}
+
+	ids, err := copyInVinsTx(ctx, dbtx, dbVins)
+	if err != nil {
+		if errRoll := dbtx.Rollback(); errRoll != nil && errRoll != sql.ErrTxDone {
+			log.Errorf("Rollback failed: %v", errRoll)
+		}
+		return nil, err
+	}
 
 	return ids, dbtx.Commit()
 }
 
+// copyInVinsTx is like CopyInVins, but loads the vins in the given database
+// transaction, which the caller must commit or roll back.
+func copyInVinsTx(ctx context.Context, dbtx *sql.Tx, dbVins dbtypes.VinTxPropertyARRAY) ([]uint64, error) {
+	// Reserve the row ids.
+	ids := make([]uint64, 0, len(dbVins))
+	rows, err := dbtx.QueryContext(ctx, internal.SelectVinsNextIDs, len(dbVins))
+	if err != nil {
+		return nil, fmt.Errorf("CopyInVins id reservation failed: %v", err)
+	}
+	for rows.Next() {
+		var id uint64
+		if err = rows.Scan(&id); err != nil {
+			closeRows(rows)
+			return nil, fmt.Errorf("CopyInVins id scan failed: %v", err)
+		}
+		ids = append(ids, id)
+	}
+	closeRows(rows)
+	if err = rows.Err(); err != nil || len(ids) != len(dbVins) {
+		return nil, fmt.Errorf("CopyInVins reserved %d of %d ids: %v",
+			len(ids), len(dbVins), err)
+	}
+
+	stmt, err := dbtx.PrepareContext(ctx, internal.MakeVinCopyInStatement())
+	if err != nil {
+		log.Errorf("Vin COPY prepare: %v", err)
+		return nil, err
+	}
+
+	for i, vin := range dbVins {
+		if err = ctx.Err(); err != nil {
+			_ = stmt.Close()
+			return nil, err
+		}
+
+		_, err = stmt.ExecContext(ctx, ids[i], vin.TxID, vin.TxIndex, vin.TxTree,
+			vin.PrevTxHash, vin.PrevTxIndex, vin.PrevTxTree,
+			vin.ValueIn, vin.IsValid, vin.IsMainchain, vin.Time.T, vin.TxType)
+		if err != nil {
+			_ = stmt.Close() // try, but we want the Exec error back
+			return nil, fmt.Errorf("CopyInVins COPY exec failed: %v", err)
+		}
+	}
+
+	// Flush the buffered data.
+	if _, err = stmt.ExecContext(ctx); err != nil {
+		_ = stmt.Close()
+		return nil, fmt.Errorf("CopyInVins COPY flush failed: %v", err)
+	}
+
+	if err = stmt.Close(); err != nil {
+		return nil, fmt.Errorf("CopyInVins COPY close failed: %v", err)
+	}
+
+	return ids, nil
+}
+
 // InsertVout either inserts, attempts to insert, or upserts the given vout data
 // into the vouts table. If checked=false, an unconditional insert as attempted,
 // which may result in a violation of a unique index constraint (error). If
@@ -1563,10 +2546,11 @@ func InsertVout(db *sql.DB, dbVout *dbtypes.Vout, checked bool, updateOnConflict
 }
 
 // InsertVouts is like InsertVout, except that it operates on a slice of vout
-// data.
-func InsertVouts(db *sql.DB, dbVouts []*dbtypes.Vout, checked bool, updateOnConflict ...bool) ([]uint64, []dbtypes.AddressRow, error) {
+// data. If the context is cancelled, the database transaction is rolled back and
+// the context's error is returned.
+func InsertVouts(ctx context.Context, db *sql.DB, dbVouts []*dbtypes.Vout, checked bool, updateOnConflict ...bool) ([]uint64, []dbtypes.AddressRow, error) {
 	// All inserts in atomic DB transaction
-	dbtx, err := db.Begin()
+	dbtx, err := db.BeginTx(ctx, nil)
 	if err != nil {
 		return nil, nil, fmt.Errorf("unable to begin database transaction: %v", err)
 	}
@@ -1575,7 +2559,7 @@ func InsertVouts(db *sql.DB, dbVouts []*dbtypes.Vout, checked bool, updateOnConf
 	if len(updateOnConflict) > 0 {
 		doUpsert = updateOnConflict[0]
 	}
-	stmt, err := dbtx.Prepare(internal.MakeVoutInsertStatement(checked, doUpsert))
+	stmt, err := dbtx.PrepareContext(ctx, internal.MakeVoutInsertStatement(checked, doUpsert))
 	if err != nil {
 		log.Errorf("Vout INSERT prepare: %v", err)
 		_ = dbtx.Rollback() // try, but we want the Prepare error back
@@ -1585,8 +2569,13 @@ func InsertVouts(db *sql.DB, dbVouts []*dbtypes.Vout, checked bool, updateOnConf
 	addressRows := make([]dbtypes.AddressRow, 0, len(dbVouts)) // may grow with multisig
 	ids := make([]uint64, 0, len(dbVouts))
 	for _, vout := range dbVouts {
+		if err = ctx.Err(); err != nil {
+			abortTx(dbtx, stmt)
+			return nil, nil, err
+		}
+
 		var id uint64
-		err = stmt.QueryRow(
+		err = stmt.QueryRowContext(ctx,
 			vout.TxHash, vout.TxIndex, vout.TxTree, vout.Value, vout.Version,
 			vout.ScriptPubKey, vout.ScriptPubKeyData.ReqSigs,
 			vout.ScriptPubKeyData.Type,
@@ -1647,6 +2636,16 @@ func RetrieveVoutValue(ctx context.Context, db *sql.DB, txHash string, voutIndex
 	return
 }
 
+// RetrieveUnspendableValue retrieves the total value, in atoms, locked in
+// provably-unspendable outputs of valid mainchain transactions. Such coins are
+// effectively burned and are not part of the circulating supply. OP_RETURN
+// (nulldata) outputs are typically zero-value, but non-standard burn scripts
+// may carry value.
+func RetrieveUnspendableValue(ctx context.Context, db *sql.DB) (value int64, err error) {
+	err = db.QueryRowContext(ctx, internal.SelectUnspendableValue).Scan(&value)
+	return
+}
+
 func RetrieveVoutValues(ctx context.Context, db *sql.DB, txHash string) (values []uint64, txInds []uint32, txTrees []int8, err error) {
 	var rows *sql.Rows
 	rows, err = db.QueryContext(ctx, internal.RetrieveVoutValues, txHash)
@@ -1673,26 +2672,46 @@ func RetrieveVoutValues(ctx context.Context, db *sql.DB, txHash string) (values
 }
 
 // RetrieveAllVinDbIDs gets every row ID (the primary keys) for the vins table.
-// This function is used in UpdateSpendingInfoInAllAddresses, so it should not
-// be subject to timeouts.
-func RetrieveAllVinDbIDs(db *sql.DB) (vinDbIDs []uint64, err error) {
-	var rows *sql.Rows
-	rows, err = db.Query(internal.SelectVinIDsALL)
+// This loads every ID into memory, so RetrieveVinDbIDsRange should be preferred
+// for large tables.
+func RetrieveAllVinDbIDs(ctx context.Context, db *sql.DB) ([]uint64, error) {
+	return RetrieveVinDbIDsRange(ctx, db, 0, math.MaxInt64)
+}
+
+// RetrieveVinDbIDsRange gets the row IDs of the vins table in the inclusive
+// range [minID, maxID], in ascending order. This is used in
+// UpdateSpendingInfoInAllAddresses to process the vins table in batches, so the
+// context should not be subject to timeouts in that case.
+func RetrieveVinDbIDsRange(ctx context.Context, db *sql.DB, minID, maxID uint64) ([]uint64, error) {
+	if maxID > math.MaxInt64 {
+		maxID = math.MaxInt64
+	}
+	if minID > maxID {
+		return nil, nil
+	}
+
+	rows, err := db.QueryContext(ctx, internal.SelectVinIDsRange, minID, maxID)
 	if err != nil {
-		return
+		return nil, err
 	}
 	defer closeRows(rows)
 
+	var vinDbIDs []uint64
 	for rows.Next() {
 		var id uint64
-		err = rows.Scan(&id)
-		if err != nil {
-			break
+		if err = rows.Scan(&id); err != nil {
+			return nil, err
 		}
-
 		vinDbIDs = append(vinDbIDs, id)
 	}
 
+	return vinDbIDs, rows.Err()
+}
+
+// RetrieveMaxVinDbID gets the largest row ID in the vins table, or 0 if the
+// table is empty.
+func RetrieveMaxVinDbID(ctx context.Context, db *sql.DB) (maxID uint64, err error) {
+	err = db.QueryRowContext(ctx, internal.SelectMaxVinID).Scan(&maxID)
 	return
 }
 
@@ -1738,29 +2757,46 @@ func RetrieveFundingTxByVinDbID(ctx context.Context, db *sql.DB, vinDbID uint64)
 	return
 }
 
-// TODO: this does not appear correct.
-func RetrieveFundingTxsByTx(db *sql.DB, txHash string) ([]uint64, []*dbtypes.Tx, error) {
-	var ids []uint64
-	var txs []*dbtypes.Tx
-	rows, err := db.Query(internal.SelectFundingTxsByTx, txHash)
+// RetrieveFundingTxsByTx retrieves the previous (funding) transactions spent
+// by the inputs of the transaction with the given hash. The returned ids are
+// the vins table row IDs of the spending inputs, each corresponding to the
+// funding transaction at the same index in txs. Inputs with no funding
+// transaction in the database, such as stakebase inputs, are omitted.
+func RetrieveFundingTxsByTx(ctx context.Context, db *sql.DB, txHash string) ([]uint64, []*dbtypes.Tx, error) {
+	rows, err := db.QueryContext(ctx, internal.SelectFundingTxsByTx, txHash)
 	if err != nil {
-		return ids, txs, err
+		return nil, nil, err
 	}
 	defer closeRows(rows)
 
+	var ids []uint64
+	var txs []*dbtypes.Tx
 	for rows.Next() {
 		var id uint64
 		var tx dbtypes.Tx
-		err = rows.Scan(&id, &tx)
+		var vinids, voutids dbtypes.UInt64Array
+		var blockTime sql.NullTime
+		err = rows.Scan(&id,
+			&tx.BlockHash, &tx.BlockHeight, &blockTime, &tx.Time.T,
+			&tx.TxType, &tx.Version, &tx.Tree, &tx.TxID, &tx.BlockIndex,
+			&tx.Locktime, &tx.Expiry, &tx.Size, &tx.Spent, &tx.Sent,
+			&tx.Fees, &tx.NumVin, &vinids, &tx.NumVout, &voutids,
+			&tx.IsValidBlock, &tx.IsMainchainBlock)
 		if err != nil {
-			break
+			return nil, nil, err
 		}
 
+		tx.BlockTime = timeDefFromNullTime(blockTime)
+		tx.VinDbIds = vinids
+		tx.VoutDbIds = voutids
+
 		ids = append(ids, id)
 		txs = append(txs, &tx)
 	}
-
-	return ids, txs, err
+	if err = rows.Err(); err != nil {
+		return nil, nil, err
+	}
+	return ids, txs, nil
 }
 
 // RetrieveSpendingTxByVinID gets the spending transaction input (hash, vin
@@ -1885,22 +2921,57 @@ func RetrieveVoutsByIDs(ctx context.Context, db *sql.DB, voutDbIDs []uint64) ([]
 		if err != nil {
 			return nil, err
 		}
-		// Parse the addresses array
-		replacer := strings.NewReplacer("{", "", "}", "")
-		addresses = replacer.Replace(addresses)
 
 		vout.ScriptPubKeyData.ReqSigs = reqSigs
 		vout.ScriptPubKeyData.Type = scriptType
-		// If there are no addresses, the Addresses should be nil or length
-		// zero. However, strings.Split will return [""] if addresses is "".
-		// If that is the case, leave it as a nil slice.
-		if len(addresses) > 0 {
-			vout.ScriptPubKeyData.Addresses = strings.Split(addresses, ",")
-		}
+		vout.ScriptPubKeyData.Addresses = parseScriptAddresses(addresses)
 	}
 	return vouts, nil
 }
 
+// RetrieveVoutsByTxHash retrieves all of the vouts of the transaction with the
+// given hash, ordered by output index. If the transaction is in several blocks,
+// the vouts of the transaction in a mainchain and valid block are preferred.
+func RetrieveVoutsByTxHash(ctx context.Context, db *sql.DB, txHash string) ([]dbtypes.Vout, error) {
+	rows, err := db.QueryContext(ctx, internal.SelectVoutsByTxHash, txHash)
+	if err != nil {
+		return nil, err
+	}
+	defer closeRows(rows)
+
+	var vouts []dbtypes.Vout
+	for rows.Next() {
+		var vout dbtypes.Vout
+		var reqSigs uint32
+		var scriptType, addresses string
+		err = rows.Scan(&vout.TxHash, &vout.TxIndex, &vout.TxTree, &vout.Value,
+			&vout.Version, &vout.ScriptPubKey, &reqSigs, &scriptType, &addresses)
+		if err != nil {
+			return nil, err
+		}
+
+		vout.ScriptPubKeyData.ReqSigs = reqSigs
+		vout.ScriptPubKeyData.Type = scriptType
+		vout.ScriptPubKeyData.Addresses = parseScriptAddresses(addresses)
+		vouts = append(vouts, vout)
+	}
+	return vouts, rows.Err()
+}
+
+// parseScriptAddresses parses the text form of the script_addresses array of
+// a vouts row, e.g. {addr1,addr2}.
+func parseScriptAddresses(addresses string) []string {
+	replacer := strings.NewReplacer("{", "", "}", "")
+	addresses = replacer.Replace(addresses)
+	// If there are no addresses, the Addresses should be nil or length zero.
+	// However, strings.Split will return [""] if addresses is "". If that is
+	// the case, leave it as a nil slice.
+	if len(addresses) == 0 {
+		return nil
+	}
+	return strings.Split(addresses, ",")
+}
+
 // SetSpendingForVinDbIDs updates rows of the addresses table with spending
 // information from the rows of the vins table specified by vinDbIDs. This does
 // not insert the spending transaction into the addresses table.
@@ -2144,66 +3215,258 @@ func retrieveCoinSupply(ctx context.Context, db *sql.DB) (*dbtypes.ChartsData, e
 	return items, nil
 }
 
-// --- agendas table ---
+// --- agendas table ---
+
+// retrieveAgendaVoteChoices retrieves for the specified agenda the vote counts
+// for each choice and the total number of votes. The interval size is either a
+// single block or a day, as specified by byType, where a value of 1 indicates a
+// block and 0 indicates a day-long interval. For day intervals, the counts
+// accumulate over time (cumulative sum), whereas for block intervals the counts
+// are just for the block. The total length of time over all intervals always
+// spans the locked-in period of the agenda.
+func retrieveAgendaVoteChoices(ctx context.Context, db *sql.DB, agendaID string, byType int) (*dbtypes.AgendaVoteChoices, error) {
+	// Query with block or day interval size
+	var query = internal.SelectAgendasAgendaVotesByTime
+	if byType == 1 {
+		query = internal.SelectAgendasAgendaVotesByHeight
+	}
+
+	rows, err := db.QueryContext(ctx, query, dbtypes.Yes, dbtypes.Abstain, dbtypes.No,
+		agendaID)
+	if err != nil {
+		return nil, err
+	}
+	defer closeRows(rows)
+
+	// Sum abstain, yes, no, and total votes
+	var a, y, n, t uint64
+	totalVotes := new(dbtypes.AgendaVoteChoices)
+	for rows.Next() {
+		var blockTime dbtypes.TimeDef
+		var abstain, yes, no, total, height uint64
+		if byType == 0 {
+			err = rows.Scan(&blockTime.T, &yes, &abstain, &no, &total)
+		} else {
+			err = rows.Scan(&height, &yes, &abstain, &no, &total)
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		// For day intervals, counts are cumulative
+		if byType == 0 {
+			a += abstain
+			y += yes
+			n += no
+			t += total
+			totalVotes.Time = append(totalVotes.Time, blockTime)
+		} else {
+			a = abstain
+			y = yes
+			n = no
+			t = total
+			totalVotes.Height = append(totalVotes.Height, height)
+		}
+
+		totalVotes.Abstain = append(totalVotes.Abstain, a)
+		totalVotes.Yes = append(totalVotes.Yes, y)
+		totalVotes.No = append(totalVotes.No, n)
+		totalVotes.Total = append(totalVotes.Total, t)
+	}
+
+	return totalVotes, nil
+}
+
+// RetrieveAgendaVotingProgress computes the progress of the specified agenda
+// in the rule change interval containing the block at currentHeight. The
+// number of blocks remaining in the interval, the current approval (the
+// fraction of non-abstaining votes that are yes), and whether the approval
+// meets the activation threshold are returned. Quorum is not considered.
+func RetrieveAgendaVotingProgress(ctx context.Context, db *sql.DB, agendaID string,
+	params *chaincfg.Params, currentHeight int64) (blocksRemaining int64, currentApproval float64, willPass bool, err error) {
+	windowStart := ruleChangeIntervalStart(currentHeight, params)
+
+	var yes, no int64
+	err = db.QueryRowContext(ctx, internal.SelectAgendaVoteTallyInRange,
+		dbtypes.Yes, dbtypes.No, agendaID, windowStart, currentHeight).Scan(&yes, &no)
+	if err != nil {
+		return
+	}
+
+	blocksRemaining, currentApproval, willPass = agendaVotingProgress(yes, no,
+		currentHeight, params)
+	return
+}
+
+// agendaVotingProgress computes the blocks remaining in the rule change
+// interval containing the block at currentHeight, the approval given the yes
+// and no vote counts, and whether the approval meets the activation threshold
+// of params.RuleChangeActivationMultiplier/Divisor.
+func agendaVotingProgress(yes, no, currentHeight int64, params *chaincfg.Params) (blocksRemaining int64, approval float64, willPass bool) {
+	interval := int64(params.RuleChangeActivationInterval)
+	blocksRemaining = ruleChangeIntervalStart(currentHeight, params) + interval -
+		1 - currentHeight
+
+	if yes+no == 0 {
+		return
+	}
+	approval = float64(yes) / float64(yes+no)
+	threshold := float64(params.RuleChangeActivationMultiplier) /
+		float64(params.RuleChangeActivationDivisor)
+	willPass = approval >= threshold
+	return
+}
+
+// ruleChangeIntervalStart computes the height of the first block of the rule
+// change interval containing the block at the given height. As in dcrd's
+// stake version and threshold state calculations, intervals are aligned to
+// params.StakeValidationHeight, so they begin at StakeValidationHeight plus a
+// multiple of params.RuleChangeActivationInterval. No votes are cast before
+// StakeValidationHeight, so for lower heights the first voting interval, which
+// starts at StakeValidationHeight, is used.
+func ruleChangeIntervalStart(height int64, params *chaincfg.Params) int64 {
+	if height < params.StakeValidationHeight {
+		return params.StakeValidationHeight
+	}
+	interval := int64(params.RuleChangeActivationInterval)
+	return height - (height-params.StakeValidationHeight)%interval
+}
+
+// RetrieveAgendaWindow computes the heights of the first and last blocks of the
+// voting window of the specified agenda. Voting begins with the first rule
+// change interval starting after the deployment's start time, and ends with the
+// rule change interval in which the expire time is reached. Heights for times
+// after the best block are projected using params.TargetTimePerBlock.
+func RetrieveAgendaWindow(ctx context.Context, db *sql.DB, agendaID string,
+	params *chaincfg.Params) (startHeight, endHeight int64, err error) {
+	deployment := agendaDeployment(agendaID, params)
+	if deployment == nil {
+		err = fmt.Errorf("unknown agenda %q", agendaID)
+		return
+	}
+
+	startBlock, err := retrieveHeightAtTime(ctx, db,
+		time.Unix(int64(deployment.StartTime), 0), params)
+	if err != nil {
+		return
+	}
+	endBlock, err := retrieveHeightAtTime(ctx, db,
+		time.Unix(int64(deployment.ExpireTime), 0), params)
+	if err != nil {
+		return
+	}
+
+	startHeight, endHeight = agendaWindow(startBlock, endBlock, params)
+	return
+}
+
+// RetrieveAgendaStakeTurnout computes the turnout for the specified agenda as
+// the fraction of the votes that could have been cast during the agenda's
+// voting window, params.TicketsPerBlock per block, that voted on the agenda
+// with any choice.
+func RetrieveAgendaStakeTurnout(ctx context.Context, db *sql.DB, agendaID string,
+	params *chaincfg.Params) (float64, error) {
+	startHeight, endHeight, err := RetrieveAgendaWindow(ctx, db, agendaID, params)
+	if err != nil {
+		return 0, err
+	}
+
+	var votes int64
+	err = db.QueryRowContext(ctx, internal.SelectAgendaVoteCountInRange,
+		agendaID, startHeight, endHeight).Scan(&votes)
+	if err != nil {
+		return 0, err
+	}
+
+	maxVotes := (endHeight - startHeight + 1) * int64(params.TicketsPerBlock)
+	if maxVotes <= 0 {
+		return 0, nil
+	}
+	return float64(votes) / float64(maxVotes), nil
+}
+
+// agendaDeployment finds the consensus deployment for the specified agenda in
+// any vote version. nil is returned if there is no such agenda.
+func agendaDeployment(agendaID string, params *chaincfg.Params) *chaincfg.ConsensusDeployment {
+	for _, deployments := range params.Deployments {
+		for i := range deployments {
+			if deployments[i].Vote.Id == agendaID {
+				return &deployments[i]
+			}
+		}
+	}
+	return nil
+}
+
+// agendaWindow computes the voting window given the heights of the first
+// blocks at the start and expire times of an agenda. The window spans whole
+// rule change intervals, which are aligned to params.StakeValidationHeight.
+func agendaWindow(startBlock, endBlock int64, params *chaincfg.Params) (startHeight, endHeight int64) {
+	interval := int64(params.RuleChangeActivationInterval)
+	startHeight = ruleChangeIntervalStart(startBlock, params)
+	if startHeight < startBlock {
+		startHeight += interval
+	}
+	endHeight = ruleChangeIntervalStart(endBlock, params) + interval - 1
+	return
+}
+
+// retrieveHeightAtTime gets the height of the first mainchain block with a time
+// no earlier than t. If t is after the best block, the height is projected
+// from the best block using params.TargetTimePerBlock.
+func retrieveHeightAtTime(ctx context.Context, db *sql.DB, t time.Time, params *chaincfg.Params) (height int64, err error) {
+	err = db.QueryRowContext(ctx, internal.SelectFirstBlockAtTime, t.UTC()).Scan(&height)
+	if err != sql.ErrNoRows {
+		return
+	}
+
+	var bestTime time.Time
+	err = db.QueryRowContext(ctx, internal.SelectBestBlockHeightTime).Scan(&height, &bestTime)
+	if err != nil {
+		return
+	}
+	height = projectHeightAtTime(height, bestTime, t, params.TargetTimePerBlock)
+	return
+}
+
+// projectHeightAtTime estimates the height of the first block at or after t,
+// given the best block's height and time and the target time per block.
+func projectHeightAtTime(bestHeight int64, bestTime, t time.Time, targetTimePerBlock time.Duration) int64 {
+	if !t.After(bestTime) {
+		return bestHeight
+	}
+	elapsed := t.Sub(bestTime)
+	return bestHeight + int64((elapsed+targetTimePerBlock-1)/targetTimePerBlock)
+}
 
-// retrieveAgendaVoteChoices retrieves for the specified agenda the vote counts
-// for each choice and the total number of votes. The interval size is either a
-// single block or a day, as specified by byType, where a value of 1 indicates a
-// block and 0 indicates a day-long interval. For day intervals, the counts
-// accumulate over time (cumulative sum), whereas for block intervals the counts
-// are just for the block. The total length of time over all intervals always
-// spans the locked-in period of the agenda.
-func retrieveAgendaVoteChoices(ctx context.Context, db *sql.DB, agendaID string, byType int) (*dbtypes.AgendaVoteChoices, error) {
-	// Query with block or day interval size
-	var query = internal.SelectAgendasAgendaVotesByTime
-	if byType == 1 {
-		query = internal.SelectAgendasAgendaVotesByHeight
+// RetrieveBlocksBySignaling retrieves the heights of the mainchain blocks in
+// the range [from, to], and for each block whether its votes predominantly
+// signaled yes (more yes than no votes) on the specified agenda. Blocks below
+// the stake validation height contain no votes and are not included.
+func RetrieveBlocksBySignaling(ctx context.Context, db *sql.DB, agendaID string,
+	params *chaincfg.Params, from, to int64) (heights []int64, signaledYes []bool, err error) {
+	if from < params.StakeValidationHeight {
+		from = params.StakeValidationHeight
 	}
 
-	rows, err := db.QueryContext(ctx, query, dbtypes.Yes, dbtypes.Abstain, dbtypes.No,
-		agendaID)
+	rows, err := db.QueryContext(ctx, internal.SelectAgendaVoteTallyByBlock,
+		dbtypes.Yes, dbtypes.No, agendaID, from, to)
 	if err != nil {
-		return nil, err
+		return
 	}
 	defer closeRows(rows)
 
-	// Sum abstain, yes, no, and total votes
-	var a, y, n, t uint64
-	totalVotes := new(dbtypes.AgendaVoteChoices)
 	for rows.Next() {
-		var blockTime dbtypes.TimeDef
-		var abstain, yes, no, total, height uint64
-		if byType == 0 {
-			err = rows.Scan(&blockTime.T, &yes, &abstain, &no, &total)
-		} else {
-			err = rows.Scan(&height, &yes, &abstain, &no, &total)
-		}
-		if err != nil {
-			return nil, err
-		}
-
-		// For day intervals, counts are cumulative
-		if byType == 0 {
-			a += abstain
-			y += yes
-			n += no
-			t += total
-			totalVotes.Time = append(totalVotes.Time, blockTime)
-		} else {
-			a = abstain
-			y = yes
-			n = no
-			t = total
-			totalVotes.Height = append(totalVotes.Height, height)
+		var height, yes, no int64
+		if err = rows.Scan(&height, &yes, &no); err != nil {
+			return
 		}
-
-		totalVotes.Abstain = append(totalVotes.Abstain, a)
-		totalVotes.Yes = append(totalVotes.Yes, y)
-		totalVotes.No = append(totalVotes.No, n)
-		totalVotes.Total = append(totalVotes.Total, t)
+		heights = append(heights, height)
+		signaledYes = append(signaledYes, yes > no)
 	}
+	err = rows.Err()
 
-	return totalVotes, nil
+	return
 }
 
 // --- transactions table ---
@@ -2221,13 +3484,16 @@ func InsertTx(db *sql.DB, dbTx *dbtypes.Tx, checked, updateExistingRecords bool)
 	return id, err
 }
 
-func InsertTxns(db *sql.DB, dbTxns []*dbtypes.Tx, checked, updateExistingRecords bool) ([]uint64, error) {
-	dbtx, err := db.Begin()
+// InsertTxns inserts the transactions in a single database transaction,
+// returning the row IDs. If the context is cancelled, the database transaction
+// is rolled back and the context's error is returned.
+func InsertTxns(ctx context.Context, db *sql.DB, dbTxns []*dbtypes.Tx, checked, updateExistingRecords bool) ([]uint64, error) {
+	dbtx, err := db.BeginTx(ctx, nil)
 	if err != nil {
 		return nil, fmt.Errorf("unable to begin database transaction: %v", err)
 	}
 
-	stmt, err := dbtx.Prepare(internal.MakeTxInsertStatement(checked, updateExistingRecords))
+	stmt, err := dbtx.PrepareContext(ctx, internal.MakeTxInsertStatement(checked, updateExistingRecords))
 	if err != nil {
 		log.Errorf("Transaction INSERT prepare: %v", err)
 		_ = dbtx.Rollback() // try, but we want the Prepare error back
@@ -2236,8 +3502,13 @@ func InsertTxns(db *sql.DB, dbTxns []*dbtypes.Tx, checked, updateExistingRecords
 
 	ids := make([]uint64, 0, len(dbTxns))
 	for _, tx := range dbTxns {
+		if err = ctx.Err(); err != nil {
+			abortTx(dbtx, stmt)
+			return nil, err
+		}
+
 		var id uint64
-		err := stmt.QueryRow(
+		err := stmt.QueryRowContext(ctx,
 			tx.BlockHash, tx.BlockHeight, tx.BlockTime.T, tx.Time.T,
 			tx.TxType, tx.Version, tx.Tree, tx.TxID, tx.BlockIndex,
 			tx.Locktime, tx.Expiry, tx.Size, tx.Spent, tx.Sent, tx.Fees,
@@ -2263,6 +3534,192 @@ func InsertTxns(db *sql.DB, dbTxns []*dbtypes.Tx, checked, updateExistingRecords
 	return ids, dbtx.Commit()
 }
 
+// RetrieveTxsByVersion retrieves the hashes of up to limit mainchain
+// transactions with the given transaction version, mined in blocks with heights
+// in the range [from, to].
+func RetrieveTxsByVersion(ctx context.Context, db *sql.DB, version int32, from, to int64, limit int) (txHashes []string, err error) {
+	rows, err := db.QueryContext(ctx, internal.SelectTxsByVersionInHeightRange,
+		version, from, to, limit)
+	if err != nil {
+		return
+	}
+	defer closeRows(rows)
+
+	for rows.Next() {
+		var txHash string
+		if err = rows.Scan(&txHash); err != nil {
+			return
+		}
+		txHashes = append(txHashes, txHash)
+	}
+	err = rows.Err()
+	return
+}
+
+// RetrieveConsolidationTxs retrieves the hashes and input counts of up to limit
+// mainchain transactions, in blocks with heights in [from, to], that
+// consolidate at least minInputs inputs into a single output. The transactions
+// with the most inputs are first.
+func RetrieveConsolidationTxs(ctx context.Context, db *sql.DB, minInputs int32, from, to int64, limit int) ([]string, []int32, error) {
+	rows, err := db.QueryContext(ctx, internal.SelectConsolidationTxs,
+		minInputs, from, to, limit)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer closeRows(rows)
+
+	var txHashes []string
+	var numVins []int32
+	for rows.Next() {
+		var txHash string
+		var numVin int32
+		if err = rows.Scan(&txHash, &numVin); err != nil {
+			return nil, nil, err
+		}
+		txHashes = append(txHashes, txHash)
+		numVins = append(numVins, numVin)
+	}
+	return txHashes, numVins, rows.Err()
+}
+
+// maxRecentTxs is the largest number of transactions that RetrieveRecentTxs
+// will return.
+const maxRecentTxs = 1000
+
+// RetrieveRecentTxs retrieves the hashes, block heights, and block times of the
+// most recent mainchain transactions, newest first. At most maxRecentTxs are
+// returned regardless of limit.
+func RetrieveRecentTxs(ctx context.Context, db *sql.DB, limit int) ([]string, []int64, []dbtypes.TimeDef, error) {
+	if limit <= 0 {
+		return nil, nil, nil, nil
+	}
+	if limit > maxRecentTxs {
+		limit = maxRecentTxs
+	}
+
+	rows, err := db.QueryContext(ctx, internal.SelectRecentTxs, limit)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	defer closeRows(rows)
+
+	var txHashes []string
+	var heights []int64
+	var blockTimes []dbtypes.TimeDef
+	for rows.Next() {
+		var txHash string
+		var height int64
+		var blockTime sql.NullTime
+		if err = rows.Scan(&txHash, &height, &blockTime); err != nil {
+			return nil, nil, nil, err
+		}
+		txHashes = append(txHashes, txHash)
+		heights = append(heights, height)
+		blockTimes = append(blockTimes, timeDefFromNullTime(blockTime))
+	}
+	return txHashes, heights, blockTimes, rows.Err()
+}
+
+// valueMismatchTolerance is the largest difference, in atoms, between a
+// transaction's fees and its input amount less its output amount that is not
+// reported by RetrieveValueMismatchTxs.
+const valueMismatchTolerance = 0
+
+// RetrieveValueMismatchTxs retrieves the hashes of the mainchain transactions
+// in blocks with heights in the range [from, to] for which the fees do not
+// equal the total input amount less the total output amount, and the
+// differences in atoms. Such transactions indicate corrupt data, such as input
+// amounts incorrectly reported by the node. Coinbase and vote transactions are
+// not checked.
+func RetrieveValueMismatchTxs(ctx context.Context, db *sql.DB, from, to int64) ([]string, []int64, error) {
+	rows, err := db.QueryContext(ctx, internal.SelectValueMismatchTxs, from, to,
+		valueMismatchTolerance)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer closeRows(rows)
+
+	var txHashes []string
+	var mismatches []int64
+	for rows.Next() {
+		var txHash string
+		var mismatch int64
+		if err = rows.Scan(&txHash, &mismatch); err != nil {
+			return nil, nil, err
+		}
+		txHashes = append(txHashes, txHash)
+		mismatches = append(mismatches, mismatch)
+	}
+	return txHashes, mismatches, rows.Err()
+}
+
+// RetrieveTimeLockedTxs retrieves the hashes and lock times of the mainchain
+// transactions with a nonzero lock time in blocks with heights in the range
+// [from, to], in block order. At most limit transactions are returned, unless
+// limit is not positive, in which case there is no limit.
+func RetrieveTimeLockedTxs(ctx context.Context, db *sql.DB, from, to int64, limit int) ([]string, []int32, error) {
+	maxTxs := sql.NullInt64{Int64: int64(limit), Valid: limit > 0}
+	rows, err := db.QueryContext(ctx, internal.SelectTimeLockedTxs, from, to, maxTxs)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer closeRows(rows)
+
+	var txHashes []string
+	var lockTimes []int32
+	for rows.Next() {
+		var txHash string
+		var lockTime int32
+		if err = rows.Scan(&txHash, &lockTime); err != nil {
+			return nil, nil, err
+		}
+		txHashes = append(txHashes, txHash)
+		lockTimes = append(lockTimes, lockTime)
+	}
+	return txHashes, lockTimes, rows.Err()
+}
+
+// RetrieveBlockSizeByTree retrieves, for each mainchain block with heights in
+// the range [from, to], the total serialized size in bytes of the regular and
+// stake tree transactions. Blocks without transactions in the transactions
+// table are omitted.
+func RetrieveBlockSizeByTree(ctx context.Context, db *sql.DB, from, to int64) (heights []int64, regularSizes []int64, stakeSizes []int64, err error) {
+	rows, err := db.QueryContext(ctx, internal.SelectBlockSizeByTree, from, to)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	defer closeRows(rows)
+
+	for rows.Next() {
+		var height, regularSize, stakeSize int64
+		if err = rows.Scan(&height, &regularSize, &stakeSize); err != nil {
+			return nil, nil, nil, err
+		}
+		heights = append(heights, height)
+		regularSizes = append(regularSizes, regularSize)
+		stakeSizes = append(stakeSizes, stakeSize)
+	}
+	return heights, regularSizes, stakeSizes, rows.Err()
+}
+
+// RetrieveMaxFeeTx retrieves the hash, fee (in atoms), and block height of the
+// mainchain transaction that paid the largest fee. Transactions with negative
+// fees or fees exceeding their total input amount are ignored.
+func RetrieveMaxFeeTx(ctx context.Context, db *sql.DB) (txHash string, fee int64, height int64, err error) {
+	err = db.QueryRowContext(ctx, internal.SelectMaxFeeTx).Scan(&txHash, &fee, &height)
+	return
+}
+
+// timeDefFromNullTime converts a scanned, possibly NULL, block_time to a
+// TimeDef. A NULL time, as for transactions not yet in a block, gives the zero
+// time.
+func timeDefFromNullTime(t sql.NullTime) dbtypes.TimeDef {
+	if !t.Valid {
+		return dbtypes.TimeDef{}
+	}
+	return dbtypes.TimeDef{T: t.Time}
+}
+
 // RetrieveDbTxByHash retrieves a row of the transactions table corresponding to
 // the given transaction hash. Transactions in valid and mainchain blocks are
 // chosen first. This function is used by FillAddressTransactions, an important
@@ -2271,12 +3728,14 @@ func RetrieveDbTxByHash(ctx context.Context, db *sql.DB, txHash string) (id uint
 	dbTx = new(dbtypes.Tx)
 	vinDbIDs := dbtypes.UInt64Array(dbTx.VinDbIds)
 	voutDbIDs := dbtypes.UInt64Array(dbTx.VoutDbIds)
+	var blockTime sql.NullTime
 	err = db.QueryRowContext(ctx, internal.SelectFullTxByHash, txHash).Scan(&id,
-		&dbTx.BlockHash, &dbTx.BlockHeight, &dbTx.BlockTime.T, &dbTx.Time.T,
+		&dbTx.BlockHash, &dbTx.BlockHeight, &blockTime, &dbTx.Time.T,
 		&dbTx.TxType, &dbTx.Version, &dbTx.Tree, &dbTx.TxID, &dbTx.BlockIndex,
 		&dbTx.Locktime, &dbTx.Expiry, &dbTx.Size, &dbTx.Spent, &dbTx.Sent,
 		&dbTx.Fees, &dbTx.NumVin, &vinDbIDs, &dbTx.NumVout, &voutDbIDs,
 		&dbTx.IsValidBlock, &dbTx.IsMainchainBlock)
+	dbTx.BlockTime = timeDefFromNullTime(blockTime)
 	dbTx.VinDbIds = vinDbIDs
 	dbTx.VoutDbIds = voutDbIDs
 	return
@@ -2292,11 +3751,13 @@ func RetrieveFullTxByHash(ctx context.Context, db *sql.DB, txHash string) (id ui
 	numVin int32, vinDbIDs []int64, numVout int32, voutDbIDs []int64,
 	isValidBlock, isMainchainBlock bool, err error) {
 	var hash string
+	var nullBlockTime sql.NullTime
 	err = db.QueryRowContext(ctx, internal.SelectFullTxByHash, txHash).Scan(&id, &blockHash,
-		&blockHeight, &blockTime.T, &timeVal.T, &txType, &version, &tree,
+		&blockHeight, &nullBlockTime, &timeVal.T, &txType, &version, &tree,
 		&hash, &blockInd, &lockTime, &expiry, &size, &spent, &sent, &fees,
 		&numVin, &vinDbIDs, &numVout, &voutDbIDs,
 		&isValidBlock, &isMainchainBlock)
+	blockTime = timeDefFromNullTime(nullBlockTime)
 	return
 }
 
@@ -2315,11 +3776,12 @@ func RetrieveDbTxsByHash(ctx context.Context, db *sql.DB, txHash string) (ids []
 		var id uint64
 		var dbTx dbtypes.Tx
 		var vinids, voutids dbtypes.UInt64Array
+		var blockTime sql.NullTime
 		// vinDbIDs := dbtypes.UInt64Array(dbTx.VinDbIds)
 		// voutDbIDs := dbtypes.UInt64Array(dbTx.VoutDbIds)
 
 		err = rows.Scan(&id,
-			&dbTx.BlockHash, &dbTx.BlockHeight, &dbTx.BlockTime.T, &dbTx.Time.T,
+			&dbTx.BlockHash, &dbTx.BlockHeight, &blockTime, &dbTx.Time.T,
 			&dbTx.TxType, &dbTx.Version, &dbTx.Tree, &dbTx.TxID, &dbTx.BlockIndex,
 			&dbTx.Locktime, &dbTx.Expiry, &dbTx.Size, &dbTx.Spent, &dbTx.Sent,
 			&dbTx.Fees, &dbTx.NumVin, &vinids, &dbTx.NumVout, &voutids,
@@ -2328,6 +3790,7 @@ func RetrieveDbTxsByHash(ctx context.Context, db *sql.DB, txHash string) (ids []
 			break
 		}
 
+		dbTx.BlockTime = timeDefFromNullTime(blockTime)
 		dbTx.VinDbIds = vinids
 		dbTx.VoutDbIds = voutids
 
@@ -2404,74 +3867,234 @@ func RetrieveTxByHash(ctx context.Context, db *sql.DB, txHash string) (id uint64
 	return
 }
 
-func RetrieveTxBlockTimeByHash(ctx context.Context, db *sql.DB, txHash string) (blockTime dbtypes.TimeDef, err error) {
-	err = db.QueryRowContext(ctx, internal.SelectTxBlockTimeByHash, txHash).Scan(&blockTime.T)
-	return
+func RetrieveTxBlockTimeByHash(ctx context.Context, db *sql.DB, txHash string) (blockTime dbtypes.TimeDef, err error) {
+	var nullBlockTime sql.NullTime
+	err = db.QueryRowContext(ctx, internal.SelectTxBlockTimeByHash, txHash).Scan(&nullBlockTime)
+	blockTime = timeDefFromNullTime(nullBlockTime)
+	return
+}
+
+// This is used by update functions, so care should be taken to not timeout in
+// these cases.
+func RetrieveTxsByBlockHash(ctx context.Context, db *sql.DB, blockHash string) (ids []uint64, txs []string,
+	blockInds []uint32, trees []int8, blockTimes []dbtypes.TimeDef, err error) {
+	return retrieveTxsByBlock(ctx, db, internal.SelectTxsByBlockHash, blockHash)
+}
+
+// RetrieveTxsByBlockHeight is like RetrieveTxsByBlockHash, but for the block at
+// the given height. Only the mainchain block at that height is considered, so
+// no transactions are returned if a height has only side chain blocks.
+func RetrieveTxsByBlockHeight(ctx context.Context, db *sql.DB, height int64) (ids []uint64, txs []string,
+	blockInds []uint32, trees []int8, blockTimes []dbtypes.TimeDef, err error) {
+	return retrieveTxsByBlock(ctx, db, internal.SelectTxsByBlockHeight, height)
+}
+
+func retrieveTxsByBlock(ctx context.Context, db *sql.DB, stmt string, block interface{}) (ids []uint64, txs []string,
+	blockInds []uint32, trees []int8, blockTimes []dbtypes.TimeDef, err error) {
+	var rows *sql.Rows
+	rows, err = db.QueryContext(ctx, stmt, block)
+	if err != nil {
+		return
+	}
+	defer closeRows(rows)
+
+	for rows.Next() {
+		var id uint64
+		var blockTime sql.NullTime
+		var tx string
+		var bind uint32
+		var tree int8
+		err = rows.Scan(&id, &tx, &bind, &tree, &blockTime)
+		if err != nil {
+			break
+		}
+
+		ids = append(ids, id)
+		txs = append(txs, tx)
+		blockInds = append(blockInds, bind)
+		trees = append(trees, tree)
+		blockTimes = append(blockTimes, timeDefFromNullTime(blockTime))
+	}
+
+	return
+}
+
+// RetrieveTxnsBlocks retrieves for the specified transaction hash the following
+// data for each block containing the transactions: block_hash, block_index,
+// is_valid, is_mainchain.
+func RetrieveTxnsBlocks(ctx context.Context, db *sql.DB, txHash string) (blockHashes []string, blockHeights, blockIndexes []uint32, areValid, areMainchain []bool, err error) {
+	var rows *sql.Rows
+	rows, err = db.QueryContext(ctx, internal.SelectTxsBlocks, txHash)
+	if err != nil {
+		return
+	}
+	defer closeRows(rows)
+
+	for rows.Next() {
+		var hash string
+		var height, idx uint32
+		var isValid, isMainchain bool
+		err = rows.Scan(&height, &hash, &idx, &isValid, &isMainchain)
+		if err != nil {
+			break
+		}
+
+		blockHeights = append(blockHeights, height)
+		blockHashes = append(blockHashes, hash)
+		blockIndexes = append(blockIndexes, idx)
+		areValid = append(areValid, isValid)
+		areMainchain = append(areMainchain, isMainchain)
+	}
+	return
+}
+
+func retrieveTxPerDay(ctx context.Context, db *sql.DB) (*dbtypes.ChartsData, error) {
+	rows, err := db.QueryContext(ctx, internal.SelectTxsPerDay)
+	if err != nil {
+		return nil, err
+	}
+	defer closeRows(rows)
+
+	items := new(dbtypes.ChartsData)
+	for rows.Next() {
+		var blockTime dbtypes.TimeDef
+		var count uint64
+		err = rows.Scan(&blockTime.T, &count)
+		if err != nil {
+			return nil, err
+		}
+
+		items.Time = append(items.Time, blockTime)
+		items.Count = append(items.Count, count)
+	}
+	return items, nil
+}
+
+// retrieveBlockGapSeries retrieves, for each mainchain block with height in
+// the range [from, to], the number of seconds since the previous block. The
+// block preceding the range is queried so the first block in the range also
+// gets a gap. The genesis block has no predecessor, and is thus omitted from
+// the series if included in the range.
+func retrieveBlockGapSeries(ctx context.Context, db *sql.DB, from, to int64) (*dbtypes.ChartsData, error) {
+	rows, err := db.QueryContext(ctx, internal.SelectBlockTimesByHeightRange, from, to)
+	if err != nil {
+		return nil, err
+	}
+	defer closeRows(rows)
+
+	var heights []uint64
+	var times []dbtypes.TimeDef
+	for rows.Next() {
+		var height uint64
+		var blockTime dbtypes.TimeDef
+		if err = rows.Scan(&height, &blockTime.T); err != nil {
+			return nil, err
+		}
+		heights = append(heights, height)
+		times = append(times, blockTime)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return blockGaps(heights, times), nil
+}
+
+// retrieveCoinbaseValuePerBlock retrieves the total output value of the
+// coinbase transaction of each mainchain block with height in the range [from,
+// to]. This is the subsidy and fees actually paid to the miner, which may be
+// less than the theoretical subsidy. The block heights are recorded in Height,
+// and the values in DCR in ValueF.
+func retrieveCoinbaseValuePerBlock(ctx context.Context, db *sql.DB, from, to int64) (*dbtypes.ChartsData, error) {
+	rows, err := db.QueryContext(ctx, internal.SelectCoinbaseValuePerBlock, from, to)
+	if err != nil {
+		return nil, err
+	}
+	defer closeRows(rows)
+
+	items := new(dbtypes.ChartsData)
+	for rows.Next() {
+		var height uint64
+		var value int64
+		if err = rows.Scan(&height, &value); err != nil {
+			return nil, err
+		}
+
+		items.Height = append(items.Height, height)
+		items.ValueF = append(items.ValueF, dcrutil.Amount(value).ToCoin())
+	}
+	return items, rows.Err()
+}
+
+// blockGaps computes the time in seconds between consecutive blocks with the
+// given heights and times, which must be ordered by height. The gap for each
+// block is recorded in ValueF, keyed by the block's Height and Time. Since the
+// first block has no predecessor, it is not included in the output. A gap may
+// be negative since block timestamps are not strictly increasing.
+func blockGaps(heights []uint64, times []dbtypes.TimeDef) *dbtypes.ChartsData {
+	gaps := new(dbtypes.ChartsData)
+	for i := 1; i < len(heights) && i < len(times); i++ {
+		gap := times[i].T.Sub(times[i-1].T).Seconds()
+		gaps.Height = append(gaps.Height, heights[i])
+		gaps.Time = append(gaps.Time, times[i])
+		gaps.ValueF = append(gaps.ValueF, gap)
+	}
+	return gaps
 }
 
-// This is used by update functions, so care should be taken to not timeout in
-// these cases.
-func RetrieveTxsByBlockHash(ctx context.Context, db *sql.DB, blockHash string) (ids []uint64, txs []string,
-	blockInds []uint32, trees []int8, blockTimes []dbtypes.TimeDef, err error) {
-	var rows *sql.Rows
-	rows, err = db.QueryContext(ctx, internal.SelectTxsByBlockHash, blockHash)
+// retrieveFeesPerDay retrieves the total transaction fees, in coins, paid each
+// day by mainchain transactions.
+func retrieveFeesPerDay(ctx context.Context, db *sql.DB) (*dbtypes.ChartsData, error) {
+	rows, err := db.QueryContext(ctx, internal.SelectFeesPerDay)
 	if err != nil {
-		return
+		return nil, err
 	}
 	defer closeRows(rows)
 
+	items := new(dbtypes.ChartsData)
 	for rows.Next() {
-		var id uint64
 		var blockTime dbtypes.TimeDef
-		var tx string
-		var bind uint32
-		var tree int8
-		err = rows.Scan(&id, &tx, &bind, &tree, &blockTime.T)
+		var fees int64
+		err = rows.Scan(&blockTime.T, &fees)
 		if err != nil {
-			break
+			return nil, err
 		}
 
-		ids = append(ids, id)
-		txs = append(txs, tx)
-		blockInds = append(blockInds, bind)
-		trees = append(trees, tree)
-		blockTimes = append(blockTimes, blockTime)
+		items.Time = append(items.Time, blockTime)
+		items.ValueF = append(items.ValueF, dcrutil.Amount(fees).ToCoin())
 	}
-
-	return
+	return items, nil
 }
 
-// RetrieveTxnsBlocks retrieves for the specified transaction hash the following
-// data for each block containing the transactions: block_hash, block_index,
-// is_valid, is_mainchain.
-func RetrieveTxnsBlocks(ctx context.Context, db *sql.DB, txHash string) (blockHashes []string, blockHeights, blockIndexes []uint32, areValid, areMainchain []bool, err error) {
-	var rows *sql.Rows
-	rows, err = db.QueryContext(ctx, internal.SelectTxsBlocks, txHash)
+// retrieveFeeRatePerDay retrieves the mean transaction fee rate, in DCR/kB,
+// of the mainchain transactions mined each day, excluding coinbase
+// transactions.
+func retrieveFeeRatePerDay(ctx context.Context, db *sql.DB) (*dbtypes.ChartsData, error) {
+	rows, err := db.QueryContext(ctx, internal.SelectFeeRatePerDay)
 	if err != nil {
-		return
+		return nil, err
 	}
 	defer closeRows(rows)
 
+	items := new(dbtypes.ChartsData)
 	for rows.Next() {
-		var hash string
-		var height, idx uint32
-		var isValid, isMainchain bool
-		err = rows.Scan(&height, &hash, &idx, &isValid, &isMainchain)
+		var blockTime dbtypes.TimeDef
+		var atomsPerByte float64
+		err = rows.Scan(&blockTime.T, &atomsPerByte)
 		if err != nil {
-			break
+			return nil, err
 		}
 
-		blockHeights = append(blockHeights, height)
-		blockHashes = append(blockHashes, hash)
-		blockIndexes = append(blockIndexes, idx)
-		areValid = append(areValid, isValid)
-		areMainchain = append(areMainchain, isMainchain)
+		items.Time = append(items.Time, blockTime)
+		items.ValueF = append(items.ValueF, atomsPerByte*1000/dcrutil.AtomsPerCoin)
 	}
-	return
+	return items, rows.Err()
 }
 
-func retrieveTxPerDay(ctx context.Context, db *sql.DB) (*dbtypes.ChartsData, error) {
-	rows, err := db.QueryContext(ctx, internal.SelectTxsPerDay)
+// retrieveRevocationsPerDay retrieves the number of mainchain revocation
+// transactions per day.
+func retrieveRevocationsPerDay(ctx context.Context, db *sql.DB) (*dbtypes.ChartsData, error) {
+	rows, err := db.QueryContext(ctx, internal.SelectRevokesPerDay)
 	if err != nil {
 		return nil, err
 	}
@@ -2489,7 +4112,7 @@ func retrieveTxPerDay(ctx context.Context, db *sql.DB) (*dbtypes.ChartsData, err
 		items.Time = append(items.Time, blockTime)
 		items.Count = append(items.Count, count)
 	}
-	return items, nil
+	return items, rows.Err()
 }
 
 func retrieveTicketByOutputCount(ctx context.Context, db *sql.DB, dataType outputCountType) (*dbtypes.ChartsData, error) {
@@ -2525,8 +4148,13 @@ func retrieveTicketByOutputCount(ctx context.Context, db *sql.DB, dataType outpu
 }
 
 // retrieveChainWork assembles both block-by-block chainwork data
-// and a rolling average for network hashrate data.
-func retrieveChainWork(db *sql.DB) (*dbtypes.ChartsData, *dbtypes.ChartsData, error) {
+// and a rolling average for network hashrate data. The hashrate is averaged
+// over averagingLength blocks, which must be positive.
+func retrieveChainWork(db *sql.DB, averagingLength int) (*dbtypes.ChartsData, *dbtypes.ChartsData, error) {
+	if averagingLength < 1 {
+		return nil, nil, fmt.Errorf("invalid hashrate averaging length %d", averagingLength)
+	}
+
 	// Grab all chainwork points in rows of (time, chainwork).
 	rows, err := db.Query(internal.SelectChainWork)
 	if err != nil {
@@ -2534,13 +4162,34 @@ func retrieveChainWork(db *sql.DB) (*dbtypes.ChartsData, *dbtypes.ChartsData, er
 	}
 	defer closeRows(rows)
 
-	// Assemble chainwork and hashrate simultaneously.
+	var blockTimes []time.Time
+	var workHexes []string
+	for rows.Next() {
+		var blocktime dbtypes.TimeDef
+		var workhex string
+		if err = rows.Scan(&blocktime.T, &workhex); err != nil {
+			return nil, nil, err
+		}
+		blockTimes = append(blockTimes, blocktime.T)
+		workHexes = append(workHexes, workhex)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, nil, err
+	}
+
+	workdata, hashrates := chainWorkSeries(blockTimes, workHexes, averagingLength)
+	return workdata, hashrates, nil
+}
+
+// chainWorkSeries assembles chainwork and hashrate simultaneously from the
+// chainwork hex strings of consecutive blocks. The hashrate at each block is
+// the work done over the preceding averagingLength blocks divided by the time
+// taken, so the first averagingLength blocks have no hashrate.
+func chainWorkSeries(blockTimes []time.Time, workHexes []string, averagingLength int) (*dbtypes.ChartsData, *dbtypes.ChartsData) {
 	// Chainwork is stored as a 32-byte hex string, so in order to
 	// do math, math/big types are used.
 	workdata := new(dbtypes.ChartsData)
 	hashrates := new(dbtypes.ChartsData)
-	var blocktime dbtypes.TimeDef
-	var workhex string
 
 	// In order to store these large values as uint64, they are represented
 	// as exahash (10^18) for work, and terahash/s (10^12) for hashrate.
@@ -2552,58 +4201,47 @@ func retrieveChainWork(db *sql.DB) (*dbtypes.ChartsData, *dbtypes.ChartsData, er
 		work *big.Int
 		time time.Time
 	}
-	// How many blocks to average across for hashrate.
-	// 120 is the default returned by the RPC method `getnetworkhashps`.
-	var averagingLength int = 120
-	// points is used as circular storage.
-	points := make([]chainWorkPt, averagingLength)
-	var thisPt, lastPt chainWorkPt
-	var idx, workingIdx, lastIdx int
-	for rows.Next() {
-		// Get the chainwork.
-		err = rows.Scan(&blocktime.T, &workhex)
-		if err != nil {
-			return nil, nil, err
-		}
-		bigwork := new(big.Int)
-		exawork := new(big.Int)
-		bigwork, ok := bigwork.SetString(workhex, 16)
+	// points is used as circular storage for the current point and the
+	// averagingLength points before it.
+	points := make([]chainWorkPt, averagingLength+1)
+	for idx, workhex := range workHexes {
+		bigwork, ok := new(big.Int).SetString(workhex, 16)
 		if !ok {
 			log.Errorf("Failed to make big.Int from chainwork %s", workhex)
 			break
 		}
-		exawork.Set(bigwork)
-		exawork.Div(bigwork, bigExa)
+		exawork := new(big.Int).Div(bigwork, bigExa)
 		if !exawork.IsUint64() {
 			log.Errorf("Failed to make uint64 from chainwork %s", workhex)
 			break
 		}
 		workdata.ChainWork = append(workdata.ChainWork, exawork.Uint64())
-		workdata.Time = append(workdata.Time, blocktime)
-
-		workingIdx = idx % averagingLength
-		points[workingIdx] = chainWorkPt{bigwork, blocktime.T}
-		if idx >= averagingLength {
-			// lastIdx is actually the point averagingLength blocks ago.
-			lastIdx = (workingIdx + 1) % averagingLength
-			lastPt = points[lastIdx]
-			thisPt = points[workingIdx]
-			diff := new(big.Int)
-			diff.Set(thisPt.work)
-			diff.Sub(diff, lastPt.work)
-			rate := diff.Div(diff, big.NewInt(int64(thisPt.time.Sub(lastPt.time).Seconds())))
-			rate.Div(rate, bigTera)
-			if !rate.IsUint64() {
-				log.Errorf("Failed to make uint64 from rate")
-				break
-			}
-			tDef := dbtypes.TimeDef{T: thisPt.time}
-			hashrates.Time = append(hashrates.Time, tDef)
-			hashrates.NetHash = append(hashrates.NetHash, rate.Uint64())
+		workdata.Time = append(workdata.Time, dbtypes.TimeDef{T: blockTimes[idx]})
+
+		workingIdx := idx % len(points)
+		points[workingIdx] = chainWorkPt{bigwork, blockTimes[idx]}
+		if idx < averagingLength {
+			continue
+		}
+		// The oldest stored point is the one averagingLength blocks ago.
+		lastPt := points[(idx+1)%len(points)]
+		thisPt := points[workingIdx]
+		seconds := int64(thisPt.time.Sub(lastPt.time).Seconds())
+		if seconds <= 0 {
+			// Block timestamps need not increase, but a rate is undefined.
+			continue
 		}
-		idx += 1
+		rate := new(big.Int).Sub(thisPt.work, lastPt.work)
+		rate.Div(rate, big.NewInt(seconds))
+		rate.Div(rate, bigTera)
+		if !rate.IsUint64() {
+			log.Errorf("Failed to make uint64 from rate")
+			break
+		}
+		hashrates.Time = append(hashrates.Time, dbtypes.TimeDef{T: thisPt.time})
+		hashrates.NetHash = append(hashrates.NetHash, rate.Uint64())
 	}
-	return workdata, hashrates, nil
+	return workdata, hashrates
 }
 
 // --- blocks and block_chain tables ---
@@ -2639,6 +4277,48 @@ func RetrieveBestBlockHeight(ctx context.Context, db *sql.DB) (height uint64, ha
 	return
 }
 
+// RetrieveBestBlockSummary retrieves the basic data, including difficulty, of
+// the best mainchain block.
+func RetrieveBestBlockSummary(ctx context.Context, db *sql.DB) (*dbtypes.BlockDataBasic, error) {
+	_, hash, _, err := RetrieveBestBlockHeight(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+
+	var block dbtypes.BlockDataBasic
+	var blockTime dbtypes.TimeDef
+	err = db.QueryRowContext(ctx, internal.SelectBlockSummaryByHash, hash).Scan(
+		&block.Hash, &block.Height, &block.Size, &blockTime.T, &block.NumTx,
+		&block.Difficulty)
+	if err != nil {
+		return nil, err
+	}
+	block.Time = blockTime
+	return &block, nil
+}
+
+// RetrieveBlockSubsidyComponents retrieves the number of votes in the mainchain
+// block at the given height, and computes the block's PoW, PoS (the total for
+// all votes), and developer subsidies from the network parameters.
+func RetrieveBlockSubsidyComponents(ctx context.Context, db *sql.DB, height int64,
+	params *chaincfg.Params) (work, stake, dev int64, err error) {
+	var voters uint16
+	err = db.QueryRowContext(ctx, internal.SelectBlockVotersByHeight, height).Scan(&voters)
+	if err != nil {
+		return
+	}
+	work, stake, dev = blockSubsidyComponents(height, voters, params)
+	return
+}
+
+// blockSubsidyComponents computes the PoW, PoS, and developer subsidies of a
+// block at the given height with the given number of votes. The PoS subsidy is
+// the total for all votes in the block.
+func blockSubsidyComponents(height int64, voters uint16, params *chaincfg.Params) (work, stake, dev int64) {
+	work, stakePerVote, dev := txhelpers.RewardsAtBlock(height, voters, params)
+	return work, stakePerVote * int64(voters), dev
+}
+
 // RetrieveBestBlockHeightAny gets the best block height, including side chains.
 func RetrieveBestBlockHeightAny(ctx context.Context, db *sql.DB) (height uint64, hash string, id uint64, err error) {
 	err = db.QueryRowContext(ctx, internal.RetrieveBestBlockHeightAny).Scan(&id, &hash, &height)
@@ -2666,6 +4346,198 @@ func RetrieveBlockVoteCount(ctx context.Context, db *sql.DB, hash string) (numVo
 	return
 }
 
+// RetrieveBlockSBits gets the stake difficulty (ticket price) in atoms of the
+// mainchain block at the given height (be sure to check error against
+// sql.ErrNoRows!).
+func RetrieveBlockSBits(ctx context.Context, db *sql.DB, height int64) (sbits int64, err error) {
+	err = db.QueryRowContext(ctx, internal.SelectBlockSBitsByHeight, height).Scan(&sbits)
+	return
+}
+
+// RetrieveBlockDifficulty gets the proof-of-work difficulty of the mainchain
+// block at the given height (be sure to check error against sql.ErrNoRows!).
+func RetrieveBlockDifficulty(ctx context.Context, db *sql.DB, height int64) (difficulty float64, err error) {
+	err = db.QueryRowContext(ctx, internal.SelectBlockDifficultyByHeight, height).Scan(&difficulty)
+	return
+}
+
+// RetrieveCumulativeTxCountAtHeight gets the total number of transactions in
+// the mainchain blocks up to and including the block at the given height. This
+// is an aggregate over the entire chain, so the result is best cached.
+func RetrieveCumulativeTxCountAtHeight(ctx context.Context, db *sql.DB, height int64) (count int64, err error) {
+	err = db.QueryRowContext(ctx, internal.SelectCumulativeTxCountAtHeight, height).Scan(&count)
+	return
+}
+
+// RetrieveAddressStakingActivity checks if the address has purchased tickets,
+// that is, if it is the stake submission address of any mainchain ticket. The
+// number of such tickets and the number of votes they cast are also returned.
+func RetrieveAddressStakingActivity(ctx context.Context, db *sql.DB, address string) (hasStaked bool, ticketCount int64, voteCount int64, err error) {
+	err = db.QueryRowContext(ctx, internal.SelectAddressStakingActivity,
+		address).Scan(&ticketCount, &voteCount)
+	hasStaked = ticketCount > 0
+	return
+}
+
+// retrieveTicketPoolInRange computes the number and total value (in atoms) of
+// the mainchain tickets mined in the height range [oldest, newest] that were
+// still in the pool as of the mainchain block at the given height. The
+// pool_status column, which reflects only the current status of a ticket, is
+// not used. Instead, a ticket's spend height and any mainchain misses determine
+// if it left the pool by the given height.
+func retrieveTicketPoolInRange(ctx context.Context, db *sql.DB, height, oldest,
+	newest int64) (size int64, value int64, err error) {
+	var price float64
+	err = db.QueryRowContext(ctx, internal.SelectTicketPoolValueAndSizeAtHeight,
+		height, oldest, newest).Scan(&price, &size)
+	if err != nil {
+		return
+	}
+	amt, err := dcrutil.NewAmount(price)
+	value = int64(amt)
+	return
+}
+
+// RetrieveAddressRoleCounts counts the mainchain transactions in which the
+// address is a sender, by spending a previous output paying to the address, and
+// in which it is a receiver, by being paid by an output. A transaction may count
+// toward both.
+func RetrieveAddressRoleCounts(ctx context.Context, db *sql.DB, address string) (asSender, asReceiver int64, err error) {
+	var rows *sql.Rows
+	rows, err = db.QueryContext(ctx, internal.SelectAddressRoleCounts, address)
+	if err != nil {
+		return
+	}
+	defer closeRows(rows)
+
+	for rows.Next() {
+		var isFunding bool
+		var count int64
+		if err = rows.Scan(&isFunding, &count); err != nil {
+			return
+		}
+		if isFunding {
+			asReceiver = count
+		} else {
+			asSender = count
+		}
+	}
+	err = rows.Err()
+	return
+}
+
+// RetrieveTicketPoolAtHeight computes the value (in coins) and size of the
+// live ticket pool as of the mainchain block at the given height. Tickets are
+// live once mature and until they are spent, missed, or expire. Zero values
+// are returned for heights before tickets could mature.
+func RetrieveTicketPoolAtHeight(ctx context.Context, db *sql.DB, height int64,
+	params *chaincfg.Params) (poolValue float64, poolSize int64, err error) {
+	if height < params.StakeEnabledHeight {
+		return
+	}
+	newestLive := height - int64(params.TicketMaturity)
+	oldestLive := newestLive - int64(params.TicketExpiry) + 1
+	poolSize, value, err := retrieveTicketPoolInRange(ctx, db, height,
+		oldestLive, newestLive)
+	poolValue = dcrutil.Amount(value).ToCoin()
+	return
+}
+
+// RetrieveTicketPoolValueAndSizeAtHeight computes the number and total value
+// (in atoms) of the live and immature tickets as of the mainchain block at the
+// given height. A ticket is counted from the block in which it was mined until
+// it is spent (voted or revoked), missed, or expires, without regard to
+// maturity.
+func RetrieveTicketPoolValueAndSizeAtHeight(ctx context.Context, db *sql.DB, height int64,
+	params *chaincfg.Params) (size int64, value int64, err error) {
+	oldest := height - int64(params.TicketMaturity) - int64(params.TicketExpiry) + 1
+	return retrieveTicketPoolInRange(ctx, db, height, oldest, height)
+}
+
+// RetrieveLiveTicketAgeDistribution retrieves the distribution of the ages, in
+// blocks since purchase as of currentHeight, of the live tickets. The ages are
+// divided into the specified number of equal width buckets spanning the range
+// of observed ages. The lower age bound of each bucket and the number of
+// tickets in each bucket are returned. Note that immature tickets also have
+// live pool status and are included.
+func RetrieveLiveTicketAgeDistribution(ctx context.Context, db *sql.DB, currentHeight int64, buckets int) ([]int64, []int64, error) {
+	if buckets < 1 {
+		return nil, nil, fmt.Errorf("invalid number of buckets %d", buckets)
+	}
+
+	rows, err := db.QueryContext(ctx, internal.SelectLiveTicketAgeBuckets,
+		currentHeight, dbtypes.PoolStatusLive, buckets)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer closeRows(rows)
+
+	var ageBounds, counts []int64
+	for rows.Next() {
+		var bucket int
+		var lo, hi, count int64
+		if err = rows.Scan(&bucket, &lo, &hi, &count); err != nil {
+			return nil, nil, err
+		}
+		if ageBounds == nil {
+			ageBounds = ageBucketBounds(lo, hi, buckets)
+			counts = make([]int64, buckets)
+		}
+		counts[bucket-1] = count
+	}
+	if err = rows.Err(); err != nil {
+		return nil, nil, err
+	}
+
+	return ageBounds, counts, nil
+}
+
+// retrieveSpendDepthHistogram computes a histogram of the number of blocks that
+// outputs remained unspent before being spent by inputs in blocks with heights
+// in [from, to]. The lower bounds of the equal width depth buckets and the
+// number of spent outputs in each are returned.
+func retrieveSpendDepthHistogram(ctx context.Context, db *sql.DB, from, to int64, buckets int) ([]int64, []int64, error) {
+	if buckets < 1 {
+		return nil, nil, fmt.Errorf("invalid number of buckets %d", buckets)
+	}
+
+	rows, err := db.QueryContext(ctx, internal.SelectSpendDepthBuckets, from, to, buckets)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer closeRows(rows)
+
+	var depthBounds, counts []int64
+	for rows.Next() {
+		var bucket int
+		var lo, hi, count int64
+		if err = rows.Scan(&bucket, &lo, &hi, &count); err != nil {
+			return nil, nil, err
+		}
+		if depthBounds == nil {
+			depthBounds = ageBucketBounds(lo, hi, buckets)
+			counts = make([]int64, buckets)
+		}
+		counts[bucket-1] = count
+	}
+	if err = rows.Err(); err != nil {
+		return nil, nil, err
+	}
+
+	return depthBounds, counts, nil
+}
+
+// ageBucketBounds computes the lower bounds of the buckets of equal width
+// spanning [lo, hi), as assigned by PostgreSQL's width_bucket.
+func ageBucketBounds(lo, hi int64, buckets int) []int64 {
+	bounds := make([]int64, buckets)
+	width := float64(hi-lo) / float64(buckets)
+	for i := range bounds {
+		bounds[i] = lo + int64(math.Ceil(float64(i)*width))
+	}
+	return bounds
+}
+
 // RetrieveBlocksHashesAll retrieve the hash of every block in the blocks table,
 // ordered by their row ID.
 func RetrieveBlocksHashesAll(ctx context.Context, db *sql.DB) ([]string, error) {
@@ -2741,6 +4613,32 @@ func RetrieveSideChainTips(ctx context.Context, db *sql.DB) (blocks []*dbtypes.B
 	return
 }
 
+// RetrieveSideChainDepths retrieves the hash of each known side chain tip
+// block, and the number of consecutive side chain blocks ending with the tip,
+// counting back to the last common block with the main chain. The tips are
+// ordered by decreasing depth.
+func RetrieveSideChainDepths(ctx context.Context, db *sql.DB) (tips []string, depths []int64, err error) {
+	var rows *sql.Rows
+	rows, err = db.QueryContext(ctx, internal.SelectSideChainDepths)
+	if err != nil {
+		return
+	}
+	defer closeRows(rows)
+
+	for rows.Next() {
+		var tip string
+		var depth int64
+		if err = rows.Scan(&tip, &depth); err != nil {
+			return
+		}
+
+		tips = append(tips, tip)
+		depths = append(depths, depth)
+	}
+	err = rows.Err()
+	return
+}
+
 // RetrieveDisapprovedBlocks retrieves the block chain status for all blocks
 // that had their regular transactions invalidated by stakeholder disapproval.
 func RetrieveDisapprovedBlocks(ctx context.Context, db *sql.DB) (blocks []*dbtypes.BlockStatus, err error) {
@@ -2822,6 +4720,97 @@ func RetrieveBlockSummaryByTimeRange(ctx context.Context, db *sql.DB, minTime, m
 	return blocks, nil
 }
 
+// RetrieveBlockTimeQuantiles retrieves the median, 90th and 99th percentile
+// times, in seconds, between consecutive mainchain blocks for the blocks with
+// heights in the range [from, to]. The percentiles are interpolated between
+// the observed intervals.
+func RetrieveBlockTimeQuantiles(ctx context.Context, db *sql.DB, from, to int64) (p50, p90, p99 float64, err error) {
+	err = db.QueryRowContext(ctx, internal.SelectBlockTimeQuantiles, from, to).Scan(&p50, &p90, &p99)
+	return
+}
+
+// RetrieveMaxBlockGap retrieves the longest time, in seconds, between
+// consecutive mainchain blocks for the blocks with heights in the range [from,
+// to], and the height of the block that ended the interval. Both are zero if
+// there are no intervals in the range.
+func RetrieveMaxBlockGap(ctx context.Context, db *sql.DB, from, to int64) (gapSeconds int64, atHeight int64, err error) {
+	err = db.QueryRowContext(ctx, internal.SelectMaxBlockGap, from, to).Scan(&gapSeconds, &atHeight)
+	if err == sql.ErrNoRows {
+		err = nil
+	}
+	return
+}
+
+// RetrieveBlockSummaryByHeightRange retrieves the basic data of the mainchain
+// blocks with heights in the range [minHeight, maxHeight], ordered by height
+// from highest to lowest. A limit of 0 returns all blocks in the range.
+func RetrieveBlockSummaryByHeightRange(ctx context.Context, db *sql.DB, minHeight, maxHeight int64, limit int) ([]dbtypes.BlockDataBasic, error) {
+	var stmt *sql.Stmt
+	var rows *sql.Rows
+	var err error
+
+	if limit == 0 {
+		stmt, err = db.Prepare(internal.SelectBlockByHeightRangeSQLNoLimit)
+		if err != nil {
+			return nil, err
+		}
+		rows, err = stmt.QueryContext(ctx, minHeight, maxHeight)
+	} else {
+		stmt, err = db.Prepare(internal.SelectBlockByHeightRangeSQL)
+		if err != nil {
+			return nil, err
+		}
+		rows, err = stmt.QueryContext(ctx, minHeight, maxHeight, limit)
+	}
+	defer stmt.Close()
+
+	if err != nil {
+		log.Error(err)
+		return nil, err
+	}
+	defer closeRows(rows)
+
+	var blocks []dbtypes.BlockDataBasic
+	for rows.Next() {
+		var dbBlock dbtypes.BlockDataBasic
+		var blockTime dbtypes.TimeDef
+		if err = rows.Scan(&dbBlock.Hash, &dbBlock.Height, &dbBlock.Size, &blockTime.T, &dbBlock.NumTx); err != nil {
+			log.Errorf("Unable to scan for block fields: %v", err)
+			return nil, err
+		}
+		dbBlock.Time = blockTime
+		blocks = append(blocks, dbBlock)
+	}
+	return blocks, rows.Err()
+}
+
+// RetrieveBlocksPage retrieves the summaries of up to limit mainchain blocks,
+// highest first, after skipping the offset highest blocks. An empty slice is
+// returned if offset is beyond the chain tip.
+func RetrieveBlocksPage(ctx context.Context, db *sql.DB, offset, limit int) ([]dbtypes.BlockDataBasic, error) {
+	if offset < 0 || limit < 0 {
+		return nil, fmt.Errorf("invalid offset (%d) or limit (%d)", offset, limit)
+	}
+
+	rows, err := db.QueryContext(ctx, internal.SelectBlocksPage, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer closeRows(rows)
+
+	blocks := []dbtypes.BlockDataBasic{}
+	for rows.Next() {
+		var dbBlock dbtypes.BlockDataBasic
+		var blockTime dbtypes.TimeDef
+		if err = rows.Scan(&dbBlock.Hash, &dbBlock.Height, &dbBlock.Size, &blockTime.T, &dbBlock.NumTx); err != nil {
+			return nil, err
+		}
+		dbBlock.Time = blockTime
+		blocks = append(blocks, dbBlock)
+	}
+	return blocks, rows.Err()
+}
+
 // RetrieveTicketsPriceByHeight fetches the ticket price and its timestamp that
 // are used to display the ticket price variation on ticket price chart. These
 // data are fetched at an interval of chaincfg.Params.StakeDiffWindowSize.
@@ -3023,27 +5012,19 @@ func UpdateLastBlockValid(db *sql.DB, blockDbID uint64, isValid bool) error {
 
 // UpdateLastVins updates the is_valid and is_mainchain columns in the vins
 // table for all of the transactions in the block specified by the given block
-// hash.
+// hash. The vins of every transaction in the block are updated with a single
+// statement. No deadline or cancellation is used since UpdateLastVins needs to
+// complete to ensure DB integrity.
 func UpdateLastVins(db *sql.DB, blockHash string, isValid, isMainchain bool) error {
-	// Retrieve the hash for every transaction in this block. A context with no
-	// deadline or cancellation function is used since this UpdateLastVins needs
-	// to complete to ensure DB integrity.
-	_, txs, _, trees, timestamps, err := RetrieveTxsByBlockHash(context.Background(), db, blockHash)
+	n, err := sqlExec(db, internal.SetIsValidIsMainchainByBlockHash,
+		"failed to update last vins tx validity: ", isValid, isMainchain,
+		blockHash)
 	if err != nil {
 		return err
 	}
 
-	for i, txHash := range txs {
-		n, err := sqlExec(db, internal.SetIsValidIsMainchainByTxHash,
-			"failed to update last vins tx validity: ", isValid, isMainchain,
-			txHash, timestamps[i].T, trees[i])
-		if err != nil {
-			return err
-		}
-
-		if n < 1 {
-			return fmt.Errorf(" failed to update at least 1 row")
-		}
+	if n < 1 {
+		return fmt.Errorf(" failed to update at least 1 row")
 	}
 
 	return nil
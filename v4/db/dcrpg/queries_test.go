@@ -0,0 +1,436 @@
+package dcrpg
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/decred/dcrd/chaincfg"
+	"github.com/decred/hcData/v4/db/dbtypes"
+	"github.com/decred/hcData/v4/db/dcrpg/internal"
+)
+
+func TestBlockGaps(t *testing.T) {
+	start := time.Unix(1454954400, 0).UTC()
+	heights := []uint64{99, 100, 101, 102}
+	times := []dbtypes.TimeDef{
+		{T: start},
+		{T: start.Add(300 * time.Second)},
+		{T: start.Add(1500 * time.Second)},
+		{T: start.Add(1490 * time.Second)},
+	}
+
+	gaps := blockGaps(heights, times)
+
+	// The first block has no predecessor and is omitted.
+	wantHeights := []uint64{100, 101, 102}
+	wantGaps := []float64{300, 1200, -10}
+	if !reflect.DeepEqual(gaps.Height, wantHeights) {
+		t.Errorf("Incorrect heights. Got %v, wanted %v.", gaps.Height, wantHeights)
+	}
+	if !reflect.DeepEqual(gaps.ValueF, wantGaps) {
+		t.Errorf("Incorrect gaps. Got %v, wanted %v.", gaps.ValueF, wantGaps)
+	}
+	if !reflect.DeepEqual(gaps.Time, times[1:]) {
+		t.Errorf("Incorrect times. Got %v, wanted %v.", gaps.Time, times[1:])
+	}
+
+	// A single block yields no gaps.
+	gaps = blockGaps(heights[:1], times[:1])
+	if len(gaps.ValueF) != 0 {
+		t.Errorf("Expected no gaps for a single block, got %v.", gaps.ValueF)
+	}
+}
+
+func TestImmatureValue(t *testing.T) {
+	const (
+		tipHeight        = int64(1000)
+		coinbaseMaturity = int64(256)
+	)
+	// An output mined in the tip block, one just short of maturity, and one
+	// that has just reached maturity.
+	values := []int64{5e8, 3e8, 1e8}
+	heights := []int64{1000, 746, 745}
+
+	immature := immatureValue(values, heights, tipHeight, coinbaseMaturity)
+	if want := int64(8e8); immature != want {
+		t.Errorf("Incorrect immature value. Got %d, wanted %d.", immature, want)
+	}
+
+	// The spendable portion excludes the immature outputs.
+	var total int64
+	for _, v := range values {
+		total += v
+	}
+	if spendable, want := total-immature, int64(1e8); spendable != want {
+		t.Errorf("Incorrect spendable value. Got %d, wanted %d.", spendable, want)
+	}
+}
+
+func TestAgendaVotingProgress(t *testing.T) {
+	params := &chaincfg.MainNetParams
+	interval := int64(params.RuleChangeActivationInterval)
+	svh := params.StakeValidationHeight
+	// 64 blocks into a rule change interval, which is aligned to the stake
+	// validation height rather than to a multiple of the interval.
+	height := svh + 30*interval + 64
+
+	tests := []struct {
+		yes, no      int64
+		wantApproval float64
+		wantPass     bool
+	}{
+		{80, 20, 0.8, true},
+		{75, 25, 0.75, true}, // exactly the 3/4 threshold
+		{70, 30, 0.7, false},
+		{0, 0, 0, false},
+	}
+	for _, tt := range tests {
+		remaining, approval, pass := agendaVotingProgress(tt.yes, tt.no, height, params)
+		if remaining != interval-65 {
+			t.Errorf("Incorrect blocks remaining. Got %d, wanted %d.", remaining, interval-65)
+		}
+		if approval != tt.wantApproval {
+			t.Errorf("Incorrect approval. Got %f, wanted %f.", approval, tt.wantApproval)
+		}
+		if pass != tt.wantPass {
+			t.Errorf("Incorrect projection for %d yes, %d no. Got %v, wanted %v.",
+				tt.yes, tt.no, pass, tt.wantPass)
+		}
+	}
+
+	heights := []struct {
+		height, wantStart, wantRemaining int64
+	}{
+		// The first and last blocks of an interval.
+		{svh + 31*interval, svh + 31*interval, interval - 1},
+		{svh + 32*interval - 1, svh + 31*interval, 0},
+		// Heights below the stake validation height are in the first voting
+		// interval, which starts at the stake validation height.
+		{0, svh, svh + interval - 1},
+		{svh - 1, svh, interval},
+		{svh, svh, interval - 1},
+	}
+	for _, tt := range heights {
+		if start := ruleChangeIntervalStart(tt.height, params); start != tt.wantStart {
+			t.Errorf("Incorrect interval start at height %d. Got %d, wanted %d.",
+				tt.height, start, tt.wantStart)
+		}
+		if remaining, _, _ := agendaVotingProgress(1, 0, tt.height, params); remaining != tt.wantRemaining {
+			t.Errorf("Incorrect blocks remaining at height %d. Got %d, wanted %d.",
+				tt.height, remaining, tt.wantRemaining)
+		}
+	}
+}
+
+func TestAddressTxnsStatement(t *testing.T) {
+	tests := []struct {
+		txnType    dbtypes.AddrTxnType
+		statement  string
+		mergedView bool
+		wantErr    bool
+	}{
+		{dbtypes.AddrTxnAll, internal.SelectAddressLimitNByAddress, false, false},
+		{dbtypes.AddrTxnCredit, internal.SelectAddressCreditsLimitNByAddress, false, false},
+		{dbtypes.AddrTxnDebit, internal.SelectAddressDebitsLimitNByAddress, false, false},
+		{dbtypes.AddrMergedTxnDebit, internal.SelectAddressMergedDebitView, true, false},
+		{dbtypes.AddrTxnUnknown, "", false, true},
+		{dbtypes.AddrTxnType(99), "", false, true},
+	}
+	for _, tt := range tests {
+		statement, mergedView, err := addressTxnsStatement(tt.txnType)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%v: unexpected error state: %v", tt.txnType, err)
+			continue
+		}
+		if statement != tt.statement {
+			t.Errorf("%v: incorrect statement. Got %q, wanted %q.",
+				tt.txnType, statement, tt.statement)
+		}
+		if mergedView != tt.mergedView {
+			t.Errorf("%v: incorrect merged view flag. Got %v, wanted %v.",
+				tt.txnType, mergedView, tt.mergedView)
+		}
+	}
+}
+
+func TestCheckVoteAccounting(t *testing.T) {
+	tests := []struct {
+		haveValidators      bool
+		numVotes, numMisses int
+		wantErr             bool
+	}{
+		{true, 5, 0, false},
+		{true, 3, 2, false},
+		{true, 4, 0, true},
+		{true, 5, 1, true},
+		{false, 3, 0, false},
+	}
+	for i, test := range tests {
+		err := checkVoteAccounting(test.haveValidators, test.numVotes, test.numMisses)
+		if (err != nil) != test.wantErr {
+			t.Errorf("Test %d: expected error %v, got %v.", i, test.wantErr, err)
+		}
+	}
+}
+
+func TestTxCountCache(t *testing.T) {
+	cache := newTxCountCache()
+	if _, ok := cache.get("blockA"); ok {
+		t.Fatal("Empty cache returned a count.")
+	}
+	cache.set("blockA", 1234)
+	if count, ok := cache.get("blockA"); !ok || count != 1234 {
+		t.Errorf("Incorrect cached count. Got %d (%v), wanted 1234.", count, ok)
+	}
+	if _, ok := cache.get("blockB"); ok {
+		t.Error("Cache returned a count for another block.")
+	}
+
+	// A full cache is cleared before storing a new count.
+	for i := 1; i < txCountCacheSize; i++ {
+		cache.set(fmt.Sprintf("block%d", i), int64(i))
+	}
+	cache.set("blockB", 5678)
+	if _, ok := cache.get("blockA"); ok {
+		t.Error("Full cache not cleared.")
+	}
+	if count, ok := cache.get("blockB"); !ok || count != 5678 {
+		t.Errorf("Incorrect cached count. Got %d (%v), wanted 5678.", count, ok)
+	}
+}
+
+func TestRollingAverage(t *testing.T) {
+	// Height 5 has no tickets, so the window ending at height 6 only covers
+	// heights 4 and 6.
+	heights := []uint64{1, 2, 3, 4, 6}
+	prices := []float64{10, 20, 30, 40, 60}
+
+	avgs := rollingAverage(heights, prices, 3)
+
+	// The first two blocks average over the partial initial window.
+	want := []float64{10, 15, 20, 30, 50}
+	if !reflect.DeepEqual(avgs, want) {
+		t.Errorf("Incorrect rolling average. Got %v, wanted %v.", avgs, want)
+	}
+
+	// A window of one block returns the input prices.
+	if avgs = rollingAverage(heights, prices, 1); !reflect.DeepEqual(avgs, prices) {
+		t.Errorf("Incorrect rolling average for single block window. Got %v, wanted %v.",
+			avgs, prices)
+	}
+}
+
+func TestAgeBucketBounds(t *testing.T) {
+	tests := []struct {
+		lo, hi  int64
+		buckets int
+		want    []int64
+	}{
+		{0, 10, 2, []int64{0, 5}},
+		{0, 10, 3, []int64{0, 4, 7}},
+		{256, 300, 4, []int64{256, 267, 278, 289}},
+		{7, 8, 1, []int64{7}},
+	}
+	for _, tt := range tests {
+		bounds := ageBucketBounds(tt.lo, tt.hi, tt.buckets)
+		if !reflect.DeepEqual(bounds, tt.want) {
+			t.Errorf("ageBucketBounds(%d, %d, %d) = %v, wanted %v.",
+				tt.lo, tt.hi, tt.buckets, bounds, tt.want)
+		}
+	}
+}
+
+func TestChainWorkSeries(t *testing.T) {
+	// Each block adds 100 TH of work and takes 10 seconds, except for block 4,
+	// which takes 40 seconds.
+	start := time.Unix(1454954400, 0).UTC()
+	var blockTimes []time.Time
+	var workHexes []string
+	var work int64
+	blockTime := start
+	for i := 0; i < 7; i++ {
+		if i > 0 {
+			work += 100e12
+			if i == 4 {
+				blockTime = blockTime.Add(40 * time.Second)
+			} else {
+				blockTime = blockTime.Add(10 * time.Second)
+			}
+		}
+		blockTimes = append(blockTimes, blockTime)
+		workHexes = append(workHexes, fmt.Sprintf("%x", work))
+	}
+
+	workdata, hashrates := chainWorkSeries(blockTimes, workHexes, 3)
+	if len(workdata.ChainWork) != len(workHexes) {
+		t.Fatalf("Incorrect number of chainwork points. Got %d, wanted %d.",
+			len(workdata.ChainWork), len(workHexes))
+	}
+
+	// Over 3 blocks, 300 TH of work is done in 30 seconds, or in 60 seconds
+	// when the window includes block 4.
+	wantRates := []uint64{10, 5, 5, 5}
+	if !reflect.DeepEqual(hashrates.NetHash, wantRates) {
+		t.Errorf("Incorrect hashrates. Got %v, wanted %v.", hashrates.NetHash, wantRates)
+	}
+	for i, tDef := range hashrates.Time {
+		if !tDef.T.Equal(blockTimes[i+3]) {
+			t.Errorf("Incorrect time for hashrate %d. Got %v, wanted %v.",
+				i, tDef.T, blockTimes[i+3])
+		}
+	}
+}
+
+func TestAgendaWindow(t *testing.T) {
+	// Params with a single deployment.
+	params := chaincfg.MainNetParams
+	params.Deployments = map[uint32][]chaincfg.ConsensusDeployment{
+		5: {{
+			Vote:       chaincfg.Vote{Id: "testagenda"},
+			StartTime:  1500000000,
+			ExpireTime: 1600000000,
+		}},
+	}
+	deployment := agendaDeployment("testagenda", &params)
+	if deployment == nil || deployment.StartTime != 1500000000 {
+		t.Fatalf("Failed to find the test agenda: %+v", deployment)
+	}
+	if agendaDeployment("nosuchagenda", &params) != nil {
+		t.Error("Found an unknown agenda.")
+	}
+	// Unknown agendas are rejected without querying the database.
+	if _, _, err := RetrieveAgendaWindow(context.Background(), nil, "nosuchagenda", &params); err == nil {
+		t.Error("Expected an error for an unknown agenda.")
+	}
+
+	interval := int64(params.RuleChangeActivationInterval)
+	svh := params.StakeValidationHeight
+	tests := []struct {
+		startBlock, endBlock int64
+		wantStart, wantEnd   int64
+	}{
+		// Voting starts at the next interval and runs through the interval
+		// in which the agenda expires. Intervals begin at the stake
+		// validation height plus a multiple of the interval.
+		{svh + 3*interval + 10, svh + 7*interval + 20, svh + 4*interval, svh + 8*interval - 1},
+		// Times reached on an interval boundary.
+		{svh + 4*interval, svh + 8*interval, svh + 4*interval, svh + 9*interval - 1},
+		// Multiples of the interval are not boundaries, since the mainnet
+		// stake validation height is less than one interval.
+		{4 * interval, 8 * interval, svh + 4*interval, svh + 8*interval - 1},
+		// No voting before the stake validation height.
+		{10, svh - 1, svh, svh + interval - 1},
+	}
+	for _, tt := range tests {
+		start, end := agendaWindow(tt.startBlock, tt.endBlock, &params)
+		if start != tt.wantStart || end != tt.wantEnd {
+			t.Errorf("agendaWindow(%d, %d) = [%d, %d], wanted [%d, %d].",
+				tt.startBlock, tt.endBlock, start, end, tt.wantStart, tt.wantEnd)
+		}
+	}
+}
+
+func TestProjectHeightAtTime(t *testing.T) {
+	bestTime := time.Unix(1500000000, 0)
+	target := 5 * time.Minute
+	tests := []struct {
+		t          time.Time
+		wantHeight int64
+	}{
+		{bestTime.Add(-time.Hour), 1000},
+		{bestTime.Add(time.Hour), 1012},
+		{bestTime.Add(time.Hour + time.Second), 1013},
+	}
+	for _, tt := range tests {
+		if height := projectHeightAtTime(1000, bestTime, tt.t, target); height != tt.wantHeight {
+			t.Errorf("Incorrect projected height at %v. Got %d, wanted %d.",
+				tt.t, height, tt.wantHeight)
+		}
+	}
+}
+
+func TestAverageFeeRate(t *testing.T) {
+	if rate := averageFeeRate(3000, 750); rate != 4 {
+		t.Errorf("Incorrect fee rate. Got %f, wanted 4.", rate)
+	}
+	if rate := averageFeeRate(0, 0); rate != 0 {
+		t.Errorf("Incorrect fee rate without transactions. Got %f, wanted 0.", rate)
+	}
+}
+
+func TestBlockSubsidyComponents(t *testing.T) {
+	params := &chaincfg.MainNetParams
+
+	// With all votes, the subsidy is split 6/3/1 between PoW, PoS and the
+	// developer fund, up to rounding.
+	const height = 300000
+	work, stake, dev := blockSubsidyComponents(height, params.TicketsPerBlock, params)
+	total := work + stake + dev
+	for _, c := range []struct {
+		name              string
+		value, proportion int64
+	}{
+		{"PoW", work, 6},
+		{"PoS", stake, 3},
+		{"dev", dev, 1},
+	} {
+		expected := total * c.proportion / 10
+		if diff := c.value - expected; diff > 5 || diff < -5 {
+			t.Errorf("Incorrect %s subsidy. Got %d, wanted %d.", c.name, c.value, expected)
+		}
+	}
+
+	// With fewer votes, the subsidies are reduced proportionally.
+	work3, stake3, dev3 := blockSubsidyComponents(height, 3, params)
+	if work3 != work*3/5 || dev3 != dev*3/5 || stake3 != stake/5*3 {
+		t.Errorf("Incorrect subsidies with 3 votes. Got %d/%d/%d, wanted %d/%d/%d.",
+			work3, stake3, dev3, work*3/5, stake/5*3, dev*3/5)
+	}
+
+	// There is no PoS subsidy before stake validation.
+	if _, stake, _ = blockSubsidyComponents(params.StakeValidationHeight-1, 0, params); stake != 0 {
+		t.Errorf("Unexpected PoS subsidy %d before stake validation height.", stake)
+	}
+}
+
+func TestRichListCache(t *testing.T) {
+	cache := newRichListCache()
+	page := richListPage{limit: 10, offset: 20}
+	rows := []dbtypes.AddressBalanceRow{{Address: "DsA", Balance: 5e8, NumTxns: 2}}
+	now := time.Unix(1540000000, 0)
+
+	if _, ok := cache.get(100, page, now); ok {
+		t.Fatal("Empty cache returned a page.")
+	}
+	cache.set(100, page, rows, now)
+
+	tests := []struct {
+		height int64
+		page   richListPage
+		now    time.Time
+		hit    bool
+	}{
+		{100, page, now.Add(richListCacheTTL), true},
+		{100, richListPage{limit: 10, offset: 30}, now, false},
+		{100, page, now.Add(richListCacheTTL + time.Second), false},
+		{101, page, now, false},
+	}
+	for i, test := range tests {
+		got, ok := cache.get(test.height, test.page, test.now)
+		if ok != test.hit {
+			t.Errorf("Test %d: expected cache hit %v, got %v.", i, test.hit, ok)
+		}
+		if ok && !reflect.DeepEqual(got, rows) {
+			t.Errorf("Test %d: incorrect cached page %v.", i, got)
+		}
+	}
+
+	// Storing a page for a new height evicts the pages for the old height.
+	cache.set(101, richListPage{limit: 5}, nil, now)
+	if _, ok := cache.get(100, page, now); ok {
+		t.Error("Page for an old height not evicted.")
+	}
+}
@@ -44,6 +44,7 @@ type wiredDB struct {
 	sDB              *stakedb.StakeDatabase
 	waitChan         chan chainhash.Hash
 	updateStatusSync bool
+	prefetchWorkers  int
 }
 
 func newWiredDB(DB *DB, statusC chan uint32, cl *rpcclient.Client,
@@ -54,6 +55,7 @@ func newWiredDB(DB *DB, statusC chan uint32, cl *rpcclient.Client,
 		client:           cl,
 		params:           p,
 		updateStatusSync: updateStatusDuringSync,
+		prefetchWorkers:  defaultBlockPrefetchWorkers,
 	}
 
 	var err error
@@ -110,6 +112,17 @@ func InitWiredDB(dbInfo *DBInfo, statusC chan uint32, cl *rpcclient.Client,
 	return wDB, cleanup, err
 }
 
+// SetBlockPrefetchWorkers sets the number of blocks fetched concurrently ahead
+// of the block being connected during a resync. Values less than 1 are
+// ignored.
+func (db *wiredDB) SetBlockPrefetchWorkers(workers int) {
+	if workers < 1 {
+		log.Warnf("Ignoring invalid block prefetch worker count %d.", workers)
+		return
+	}
+	db.prefetchWorkers = workers
+}
+
 func (db *wiredDB) NewStakeDBChainMonitor(ctx context.Context, wg *sync.WaitGroup,
 	blockChan chan *chainhash.Hash, reorgChan chan *txhelpers.ReorgData) *stakedb.ChainMonitor {
 	return db.sDB.NewChainMonitor(ctx, wg, blockChan, reorgChan)
@@ -1490,6 +1503,13 @@ func (db *wiredDB) UnconfirmedTxnsForAddress(address string) (*txhelpers.Address
 	return rpcutils.UnconfirmedTxnsForAddress(db.client, address, db.params)
 }
 
+// UnconfirmedTxnsForAddresses is like UnconfirmedTxnsForAddress, but for
+// several addresses, scanning the mempool only once. The returned map has the
+// mempool inputs/outputs associated with each of the addresses.
+func (db *wiredDB) UnconfirmedTxnsForAddresses(addresses []string) (map[string]*txhelpers.AddressOutpoints, error) {
+	return rpcutils.UnconfirmedTxnsForAddresses(db.client, addresses, db.params)
+}
+
 // GetMepool gets all transactions from the mempool for explorer and adds the
 // total out for all the txs and vote info for the votes. The returned slice
 // will be nil if the GetRawMempoolVerbose RPC fails. A zero-length non-nil
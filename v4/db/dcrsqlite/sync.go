@@ -12,6 +12,7 @@ import (
 
 	"github.com/decred/dcrd/chaincfg/chainhash"
 	"github.com/decred/dcrd/dcrutil"
+	"github.com/decred/dcrd/wire"
 	apitypes "github.com/decred/hcData/v4/api/types"
 	"github.com/decred/hcData/v4/blockdata"
 	"github.com/decred/hcData/v4/db/dbtypes"
@@ -23,6 +24,10 @@ import (
 const (
 	rescanLogBlockChunk      = 1000
 	InitialLoadSyncStatusMsg = "(Lite Mode) Syncing stake and base DBs..."
+
+	// defaultBlockPrefetchWorkers is the default number of blocks fetched
+	// concurrently ahead of the block being connected in resyncDB.
+	defaultBlockPrefetchWorkers = 10
 )
 
 // DBHeights returns the best block heights of: SQLite database tables (block
@@ -196,6 +201,18 @@ func (db *wiredDB) resyncDB(ctx context.Context, blockGetter rpcutils.BlockGette
 		}
 	}
 
+	// Prefetch the blocks that are not relayed by an external
+	// MasterBlockGetter. Blocks beyond the initial best block are fetched one
+	// at a time in the loop below.
+	prefetchEnd := height
+	if !master && fetchToHeight-1 < prefetchEnd {
+		prefetchEnd = fetchToHeight - 1
+	}
+	prefetchCtx, cancelPrefetch := context.WithCancel(ctx)
+	defer cancelPrefetch()
+	prefetched := prefetchBlocks(prefetchCtx, db.client, startHeight,
+		prefetchEnd, db.prefetchWorkers)
+
 	timeStart := time.Now()
 	for i := startHeight; i <= height; i++ {
 		// check for quit signal
@@ -214,7 +231,17 @@ func (db *wiredDB) resyncDB(ctx context.Context, blockGetter rpcutils.BlockGette
 		if master || i < fetchToHeight {
 			// Not coordinating with blockGetter for this block
 			var h *chainhash.Hash
-			block, h, err = db.getBlock(i)
+			if i <= prefetchEnd {
+				pb, ok := <-prefetched
+				if !ok {
+					// The prefetch channel is only closed early on quit.
+					log.Infof("Rescan cancelled at height %d.", i)
+					return i - 1, nil
+				}
+				block, h, err = pb.block, pb.hash, pb.err
+			} else {
+				block, h, err = db.getBlock(i)
+			}
 			if err != nil {
 				return i - 1, fmt.Errorf("getBlock failed (%d): %v", i, err)
 			}
@@ -240,9 +267,6 @@ func (db *wiredDB) resyncDB(ctx context.Context, blockGetter rpcutils.BlockGette
 		// SQLite height, except when SQLite is empty since stakedb always has
 		// genesis, as enforced by the rewinding code in this function.
 		if i > stakeDBHeight {
-		//	if i != int64(db.sDB.Height()+1) {
-	//			panic(fmt.Sprintf("about to connect the wrong block: %d, %d", i, db.sDB.Height()))
-	//		}
 			if err = db.sDB.ConnectBlock(block); err != nil {
 				return i - 1, err
 			}
@@ -397,12 +421,23 @@ func (db *wiredDB) resyncDB(ctx context.Context, blockGetter rpcutils.BlockGette
 }
 
 func (db *wiredDB) getBlock(ind int64) (*dcrutil.Block, *chainhash.Hash, error) {
-	blockhash, err := db.client.GetBlockHash(ind)
+	return fetchBlock(db.client, ind)
+}
+
+// blockFetcher is the subset of the node's RPC client methods used to fetch
+// blocks by height.
+type blockFetcher interface {
+	GetBlockHash(blockHeight int64) (*chainhash.Hash, error)
+	GetBlock(blockHash *chainhash.Hash) (*wire.MsgBlock, error)
+}
+
+func fetchBlock(client blockFetcher, ind int64) (*dcrutil.Block, *chainhash.Hash, error) {
+	blockhash, err := client.GetBlockHash(ind)
 	if err != nil {
 		return nil, nil, fmt.Errorf("GetBlockHash(%d) failed: %v", ind, err)
 	}
 
-	msgBlock, err := db.client.GetBlock(blockhash)
+	msgBlock, err := client.GetBlock(blockhash)
 	if err != nil {
 		return nil, blockhash,
 			fmt.Errorf("GetBlock failed (%s): %v", blockhash, err)
@@ -412,6 +447,73 @@ func (db *wiredDB) getBlock(ind int64) (*dcrutil.Block, *chainhash.Hash, error)
 	return block, blockhash, nil
 }
 
+// prefetchedBlock is a block fetched by prefetchBlocks, or the error
+// encountered while fetching it.
+type prefetchedBlock struct {
+	height int64
+	block  *dcrutil.Block
+	hash   *chainhash.Hash
+	err    error
+}
+
+// prefetchBlocks fetches the blocks with heights in [start, end] with up to
+// workers concurrent requests, and sends them on the returned channel in
+// strictly increasing height order regardless of the order in which the
+// requests complete. At most workers blocks are fetched ahead of the receiver.
+// The channel is closed after the block at height end or a failed fetch is
+// sent, or when ctx is cancelled.
+func prefetchBlocks(ctx context.Context, client blockFetcher, start, end int64, workers int) <-chan *prefetchedBlock {
+	if workers < 1 {
+		workers = 1
+	}
+	ctx, cancel := context.WithCancel(ctx)
+
+	// Each height gets its own result channel, queued in height order. The
+	// queue capacity and the result being awaited by the sender goroutine
+	// limit the number of fetches in progress to workers.
+	queue := make(chan chan *prefetchedBlock, workers-1)
+	go func() {
+		defer close(queue)
+		for h := start; h <= end; h++ {
+			result := make(chan *prefetchedBlock, 1)
+			select {
+			case queue <- result:
+			case <-ctx.Done():
+				return
+			}
+			go func(h int64) {
+				block, hash, err := fetchBlock(client, h)
+				result <- &prefetchedBlock{h, block, hash, err}
+			}(h)
+		}
+	}()
+
+	blocks := make(chan *prefetchedBlock)
+	go func() {
+		defer close(blocks)
+		// Stop the queueing goroutine if returning early.
+		defer cancel()
+		for result := range queue {
+			var pb *prefetchedBlock
+			select {
+			case pb = <-result:
+			case <-ctx.Done():
+				return
+			}
+			select {
+			case blocks <- pb:
+			case <-ctx.Done():
+				return
+			}
+			if pb.err != nil {
+				return
+			}
+		}
+	}()
+
+	return blocks
+}
+
 // ImportSideChains imports all side chains. Similar to pgblockchain.MissingSideChainBlocks
 // plus the rest from main.go
 func (db *wiredDB) ImportSideChains(collector *blockdata.Collector) error {
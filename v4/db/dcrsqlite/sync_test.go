@@ -0,0 +1,103 @@
+package dcrsqlite
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/decred/dcrd/chaincfg/chainhash"
+	"github.com/decred/dcrd/wire"
+)
+
+// reverseFetcher is a blockFetcher for which fetches of lower blocks take
+// longer, so that concurrent fetches complete in reverse height order.
+type reverseFetcher struct {
+	end           int64
+	failAt        int64
+	delayPerBlock time.Duration
+}
+
+func (f *reverseFetcher) GetBlockHash(blockHeight int64) (*chainhash.Hash, error) {
+	if blockHeight == f.failAt {
+		return nil, fmt.Errorf("no block at height %d", blockHeight)
+	}
+	var hash chainhash.Hash
+	binary.LittleEndian.PutUint64(hash[:], uint64(blockHeight))
+	return &hash, nil
+}
+
+func (f *reverseFetcher) GetBlock(blockHash *chainhash.Hash) (*wire.MsgBlock, error) {
+	height := int64(binary.LittleEndian.Uint64(blockHash[:]))
+	time.Sleep(time.Duration(f.end-height) * f.delayPerBlock)
+	return &wire.MsgBlock{
+		Header: wire.BlockHeader{Height: uint32(height)},
+	}, nil
+}
+
+func TestPrefetchBlocksOrder(t *testing.T) {
+	const start, end = 5, 40
+	client := &reverseFetcher{end: end, failAt: -1, delayPerBlock: time.Millisecond}
+
+	blocks := prefetchBlocks(context.Background(), client, start, end, 10)
+
+	// Connect the blocks, requiring each to follow the previous one.
+	next := int64(start)
+	for pb := range blocks {
+		if pb.err != nil {
+			t.Fatalf("fetch failed at height %d: %v", pb.height, pb.err)
+		}
+		if pb.height != next || int64(pb.block.Height()) != next {
+			t.Fatalf("Block connected out of order. Got %d (block %d), wanted %d.",
+				pb.height, pb.block.Height(), next)
+		}
+		next++
+	}
+	if next != end+1 {
+		t.Errorf("Blocks stopped at height %d, wanted %d.", next-1, end)
+	}
+}
+
+func TestPrefetchBlocksError(t *testing.T) {
+	const start, end, failAt = 0, 20, 7
+	client := &reverseFetcher{end: end, failAt: failAt}
+
+	blocks := prefetchBlocks(context.Background(), client, start, end, 4)
+
+	var last *prefetchedBlock
+	for pb := range blocks {
+		last = pb
+	}
+	if last == nil || last.err == nil || last.height != failAt {
+		t.Fatalf("Expected the last block to be the failed fetch at height %d, got %+v.",
+			failAt, last)
+	}
+}
+
+func TestPrefetchBlocksCancel(t *testing.T) {
+	client := &reverseFetcher{end: 1000, failAt: -1}
+	ctx, cancel := context.WithCancel(context.Background())
+
+	blocks := prefetchBlocks(ctx, client, 0, 1000, 4)
+	if pb := <-blocks; pb == nil || pb.height != 0 {
+		t.Fatalf("Expected block 0, got %+v.", pb)
+	}
+	cancel()
+
+	// The channel must be closed without delivering the remaining blocks.
+	timeout := time.After(5 * time.Second)
+	for {
+		select {
+		case pb, ok := <-blocks:
+			if !ok {
+				return
+			}
+			if pb.height == 1000 {
+				t.Fatal("All blocks were delivered despite cancellation.")
+			}
+		case <-timeout:
+			t.Fatal("Block channel not closed after cancellation.")
+		}
+	}
+}
@@ -79,6 +79,7 @@ type explorerDataSource interface {
 	BlockMissedVotes(blockHash string) ([]string, error)
 	GetPgChartsData() (map[string]*dbtypes.ChartsData, error)
 	TicketsPriceByHeight() (*dbtypes.ChartsData, error)
+	RollingAvgTicketPrice(windowBlocks int64) (*dbtypes.ChartsData, error)
 	SideChainBlocks() ([]*dbtypes.BlockStatus, error)
 	DisapprovedBlocks() ([]*dbtypes.BlockStatus, error)
 	BlockStatus(hash string) (dbtypes.BlockStatus, error)
@@ -379,7 +380,7 @@ func (exp *explorerUI) StartSyncingStatusMonitor() {
 			if !exp.DisplaySyncStatusPage() {
 				timer.Stop()
 			}
-			exp.wsHub.HubRelay <- sigSyncStatus
+			exp.wsHub.HubRelay <- SigSyncStatus
 		}
 	}()
 }
@@ -395,7 +396,7 @@ func (exp *explorerUI) SetDisplaySyncStatusPage(displayStatus bool) {
 	if !displayStatus {
 		// Send the one last signal so that the websocket can send the final
 		// confirmation that syncing is done and home page auto reload should happen.
-		exp.wsHub.HubRelay <- sigSyncStatus
+		exp.wsHub.HubRelay <- SigSyncStatus
 	}
 	exp.displaySyncStatusPage.Store(displayStatus)
 }
@@ -407,6 +408,17 @@ func (exp *explorerUI) Height() int64 {
 	return exp.pageData.BlockInfo.Height
 }
 
+// TicketMaturityRemaining returns the number of blocks after currentHeight
+// until a ticket purchased at purchaseHeight becomes eligible to vote, which is
+// 0 once the ticket is mature.
+func (exp *explorerUI) TicketMaturityRemaining(purchaseHeight, currentHeight int64) int64 {
+	remaining := purchaseHeight + int64(exp.ChainParams.TicketMaturity) - currentHeight
+	if remaining < 0 {
+		return 0
+	}
+	return remaining
+}
+
 // prePopulateChartsData should run in the background the first time the system
 // is initialized and when new blocks are added.
 func (exp *explorerUI) prePopulateChartsData() {
@@ -440,6 +452,18 @@ func (exp *explorerUI) prePopulateChartsData() {
 		return
 	}
 
+	rollingAvgPrice, err := exp.explorerSource.RollingAvgTicketPrice(
+		exp.ChainParams.StakeDiffWindowSize)
+	if dbtypes.IsTimeoutErr(err) {
+		log.Warnf("RollingAvgTicketPrice DB timeout: %v", err)
+		return
+	}
+	if err != nil {
+		log.Errorf("Invalid rolling average ticket price data found: %v", err)
+		return
+	}
+	pgData["ticket-price-rolling-avg"] = rollingAvgPrice
+
 	log.Debugf("Retrieving charts data from base DB.")
 	sqliteData, err := exp.blockData.GetSqliteChartsData()
 	if err != nil {
@@ -557,9 +581,9 @@ func (exp *explorerUI) Store(blockData *blockdata.BlockData, msgBlock *wire.MsgB
 	// block Store(), and do not hang forever in a goroutine waiting to send.
 	go func() {
 		select {
-		case exp.wsHub.HubRelay <- sigNewBlock:
+		case exp.wsHub.HubRelay <- SigNewBlock:
 		case <-time.After(time.Second * 10):
-			log.Errorf("sigNewBlock send failed: Timeout waiting for WebsocketHub.")
+			log.Errorf("SigNewBlock send failed: Timeout waiting for WebsocketHub.")
 		}
 	}()
 
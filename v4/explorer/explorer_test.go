@@ -1,9 +1,13 @@
 package explorer
 
 import (
+	"encoding/json"
+	"net/http/httptest"
+	"reflect"
 	"testing"
 
 	"github.com/decred/dcrd/chaincfg"
+	"github.com/decred/hcData/v4/txhelpers"
 )
 
 func TestTestNet3Name(t *testing.T) {
@@ -26,3 +30,197 @@ func TestSimNetName(t *testing.T) {
 		t.Errorf(`Net name not "Simnet": %s`, netName)
 	}
 }
+
+func TestVoteBitsBreakdown(t *testing.T) {
+	// Two agendas: one using bits 1-2 and one using bits 3-4.
+	agendaA := chaincfg.Vote{
+		Id:   "agendaA",
+		Mask: 0x0006,
+		Choices: []chaincfg.Choice{
+			{Id: "abstain", IsAbstain: true, Bits: 0x0000},
+			{Id: "no", IsNo: true, Bits: 0x0002},
+			{Id: "yes", Bits: 0x0004},
+		},
+	}
+	agendaB := chaincfg.Vote{
+		Id:   "agendaB",
+		Mask: 0x0018,
+		Choices: []chaincfg.Choice{
+			{Id: "abstain", IsAbstain: true, Bits: 0x0000},
+			{Id: "no", IsNo: true, Bits: 0x0008},
+			{Id: "yes", Bits: 0x0010},
+		},
+	}
+
+	// Block valid, yes on agendaA, no on agendaB.
+	voteBits := uint16(0x0001 | 0x0004 | 0x0008)
+	choices := []*txhelpers.VoteChoice{
+		{ID: agendaA.Id, Mask: agendaA.Mask, ChoiceIdx: 2, Choice: &agendaA.Choices[2]},
+		{ID: agendaB.Id, Mask: agendaB.Mask, ChoiceIdx: 1, Choice: &agendaB.Choices[1]},
+	}
+
+	bits := voteBitsBreakdown(voteBits, choices)
+	if len(bits) != 16 {
+		t.Fatalf("Expected 16 vote bits, got %d.", len(bits))
+	}
+
+	expected := map[uint]VoteBit{
+		0: {Position: 0, Set: true, Item: "validity", Choice: "yes"},
+		1: {Position: 1, Set: false, Item: "agendaA", Choice: "yes"},
+		2: {Position: 2, Set: true, Item: "agendaA", Choice: "yes"},
+		3: {Position: 3, Set: true, Item: "agendaB", Choice: "no"},
+		4: {Position: 4, Set: false, Item: "agendaB", Choice: "no"},
+		5: {Position: 5},
+	}
+	for pos, want := range expected {
+		if bits[pos] != want {
+			t.Errorf("Incorrect vote bit %d. Got %+v, expected %+v.", pos, bits[pos], want)
+		}
+	}
+}
+
+func TestFeeRateHistogram(t *testing.T) {
+	bounds := []float64{10, 100}
+	regular := []MempoolTx{
+		{Fees: 0.00001, Size: 200}, // 5 atoms/B
+		{Fees: 0.0001, Size: 1000}, // 10 atoms/B, on a bound
+		{Fees: 0.0005, Size: 250},  // 200 atoms/B
+		{Fees: 0, Size: 300},       // no fee
+		{Fees: 0.0001, Size: 0},    // no size, not counted
+	}
+	votes := []MempoolTx{
+		{Fees: 0, Size: 150},
+	}
+
+	buckets := feeRateHistogram(bounds, regular, votes)
+	expected := []FeeRateBucket{
+		{MinFeeRate: 0, MaxFeeRate: 10, Count: 3, TotalSize: 650},
+		{MinFeeRate: 10, MaxFeeRate: 100, Count: 1, TotalSize: 1000},
+		{MinFeeRate: 100, MaxFeeRate: 0, Count: 1, TotalSize: 250},
+	}
+	if !reflect.DeepEqual(buckets, expected) {
+		t.Errorf("Incorrect histogram. Got %+v, wanted %+v.", buckets, expected)
+	}
+}
+
+func TestRetrieveMempoolTxCountAndSize(t *testing.T) {
+	mpi := &MempoolInfo{
+		Transactions: []MempoolTx{
+			{Fees: 0.00001, Size: 200},
+			{Fees: 0.0005, Size: 250},
+		},
+		Tickets: []MempoolTx{
+			{Fees: 0.0001, Size: 300},
+		},
+		Votes: []MempoolTx{
+			{Fees: 0, Size: 150},
+		},
+	}
+
+	count, totalSize, totalFees := mpi.RetrieveMempoolTxCountAndSize()
+	if count != 4 {
+		t.Errorf("Incorrect count. Got %d, wanted 4.", count)
+	}
+	if totalSize != 900 {
+		t.Errorf("Incorrect total size. Got %d, wanted 900.", totalSize)
+	}
+	if totalFees != 61000 {
+		t.Errorf("Incorrect total fees. Got %d, wanted 61000.", totalFees)
+	}
+
+	count, totalSize, totalFees = new(MempoolInfo).RetrieveMempoolTxCountAndSize()
+	if count != 0 || totalSize != 0 || totalFees != 0 {
+		t.Errorf("Expected zero aggregates for an empty mempool, got %d, %d, %d.",
+			count, totalSize, totalFees)
+	}
+}
+
+func TestParseFeeRateBounds(t *testing.T) {
+	bounds, err := parseFeeRateBounds("1, 10,100.5")
+	if err != nil {
+		t.Fatalf("parseFeeRateBounds: %v", err)
+	}
+	if !reflect.DeepEqual(bounds, []float64{1, 10, 100.5}) {
+		t.Errorf("Incorrect bounds: %v", bounds)
+	}
+
+	for _, invalid := range []string{"10,1", "0,1", "1,,2", "abc", "-1"} {
+		if _, err = parseFeeRateBounds(invalid); err == nil {
+			t.Errorf("Expected an error for bounds %q.", invalid)
+		}
+	}
+}
+
+func TestExplorerSyncStatus(t *testing.T) {
+	exp := &explorerUI{pageData: &pageData{}}
+
+	getStatus := func() ExplorerSyncStatus {
+		w := httptest.NewRecorder()
+		exp.ExplorerSyncStatus(w, httptest.NewRequest("GET", "/syncstatus", nil))
+		if w.Code != 200 {
+			t.Fatalf("Status code %d, wanted 200.", w.Code)
+		}
+		var status ExplorerSyncStatus
+		if err := json.Unmarshal(w.Body.Bytes(), &status); err != nil {
+			t.Fatalf("Failed to decode sync status %s: %v", w.Body.String(), err)
+		}
+		return status
+	}
+
+	// Syncing, with no block data yet.
+	exp.SetDisplaySyncStatusPage(true)
+	progress := []SyncStatusInfo{{PercentComplete: 40, BarMsg: "Syncing blocks",
+		ProgressBarID: "pb"}}
+	blockchainSyncStatus.Lock()
+	blockchainSyncStatus.ProgressBars = progress
+	blockchainSyncStatus.Unlock()
+	defer func() {
+		blockchainSyncStatus.Lock()
+		blockchainSyncStatus.ProgressBars = nil
+		blockchainSyncStatus.Unlock()
+	}()
+
+	status := getStatus()
+	if !status.Syncing || status.Height != 0 {
+		t.Errorf("Incorrect sync status while syncing: %+v", status)
+	}
+	if !reflect.DeepEqual(status.Progress, progress) {
+		t.Errorf("Incorrect progress. Got %+v, wanted %+v.", status.Progress, progress)
+	}
+
+	// Synced. SetDisplaySyncStatusPage(false) would signal the websocket hub.
+	exp.displaySyncStatusPage.Store(false)
+	exp.pageData.BlockInfo = &BlockInfo{BlockBasic: &BlockBasic{Height: 300000}}
+	blockchainSyncStatus.Lock()
+	blockchainSyncStatus.ProgressBars = nil
+	blockchainSyncStatus.Unlock()
+
+	status = getStatus()
+	if status.Syncing || status.Height != 300000 || status.Progress == nil ||
+		len(status.Progress) != 0 {
+		t.Errorf("Incorrect sync status after syncing: %+v", status)
+	}
+}
+
+func TestTicketMaturityRemaining(t *testing.T) {
+	exp := &explorerUI{ChainParams: &chaincfg.MainNetParams}
+	maturity := int64(chaincfg.MainNetParams.TicketMaturity)
+	const purchaseHeight = 300000
+	tests := []struct {
+		currentHeight int64
+		remaining     int64
+	}{
+		{purchaseHeight, maturity},
+		{purchaseHeight + 1, maturity - 1},
+		{purchaseHeight + maturity - 1, 1},
+		{purchaseHeight + maturity, 0},
+		{purchaseHeight + maturity + 1000, 0},
+	}
+	for _, tt := range tests {
+		remaining := exp.TicketMaturityRemaining(purchaseHeight, tt.currentHeight)
+		if remaining != tt.remaining {
+			t.Errorf("TicketMaturityRemaining(%d, %d) = %d, wanted %d.",
+				purchaseHeight, tt.currentHeight, remaining, tt.remaining)
+		}
+	}
+}
@@ -629,6 +629,55 @@ func (exp *explorerUI) Mempool(w http.ResponseWriter, r *http.Request) {
 	io.WriteString(w, str)
 }
 
+// MempoolFeeRates is the handler for the "/mempool/feerates" path. It responds
+// with a JSON histogram of the fee rates, in atoms/byte, of the mempool
+// transactions. The optional "bounds" URL query parameter is a comma-separated
+// list of increasing bucket bounds that replaces DefaultFeeRateBounds.
+func (exp *explorerUI) MempoolFeeRates(w http.ResponseWriter, r *http.Request) {
+	bounds := DefaultFeeRateBounds
+	if boundsStr := r.URL.Query().Get("bounds"); boundsStr != "" {
+		var err error
+		bounds, err = parseFeeRateBounds(boundsStr)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
+	data, err := json.Marshal(exp.MempoolData.FeeRateHistogram(bounds))
+	if err != nil {
+		log.Errorf("Failed to encode fee rate histogram: %v", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError),
+			http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	w.Write(data)
+}
+
+// MempoolCounts is the handler for the "/mempool/counts" path. It responds with
+// the JSON-encoded number, total size, and total fees of the mempool
+// transactions, for widgets that do not need the transactions themselves.
+func (exp *explorerUI) MempoolCounts(w http.ResponseWriter, r *http.Request) {
+	var counts MempoolCounts
+	counts.Count, counts.TotalSize, counts.TotalFees =
+		exp.MempoolData.RetrieveMempoolTxCountAndSize()
+
+	data, err := json.Marshal(counts)
+	if err != nil {
+		log.Errorf("Failed to encode mempool counts: %v", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError),
+			http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	w.Write(data)
+}
+
 // Ticketpool is the page handler for the "/ticketpool" path.
 func (exp *explorerUI) Ticketpool(w http.ResponseWriter, r *http.Request) {
 	if exp.liteMode {
@@ -906,6 +955,10 @@ func (exp *explorerUI) TxPage(w http.ResponseWriter, r *http.Request) {
 			float64(tx.TicketInfo.TicketMaturity))
 		tx.TicketInfo.TimeTillMaturity = ((float64(exp.ChainParams.TicketMaturity) -
 			float64(tx.Confirmations)) / float64(exp.ChainParams.TicketMaturity)) * maturityInHours
+		if tx.Confirmations > 0 {
+			tx.TicketInfo.BlocksTillMaturity = exp.TicketMaturityRemaining(
+				tx.BlockHeight, exp.Height())
+		}
 		ticketExpiryBlocksLeft := int64(exp.ChainParams.TicketExpiry) - blocksLive
 		tx.TicketInfo.TicketExpiryDaysLeft = (float64(ticketExpiryBlocksLeft) /
 			float64(exp.ChainParams.TicketExpiry)) * expirationInDays
@@ -1669,6 +1722,42 @@ func (exp *explorerUI) HandleApiRequestsOnSync(w http.ResponseWriter, r *http.Re
 	io.WriteString(w, str)
 }
 
+// ExplorerSyncStatus is the handler for the "/syncstatus" path. It responds
+// with the explorer's sync state as JSON, including while the sync status page
+// is active, so that load balancers can route requests away from a syncing
+// node.
+func (exp *explorerUI) ExplorerSyncStatus(w http.ResponseWriter, r *http.Request) {
+	data, err := json.Marshal(exp.getExplorerSyncStatus())
+	if err != nil {
+		log.Errorf("Failed to encode sync status: %v", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError),
+			http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	w.Write(data)
+}
+
+// getExplorerSyncStatus gathers the current sync state of the explorer.
+func (exp *explorerUI) getExplorerSyncStatus() *ExplorerSyncStatus {
+	// Copy the progress bars, which also gives an empty list rather than null
+	// when no sync is in progress.
+	status := &ExplorerSyncStatus{
+		Syncing:  exp.DisplaySyncStatusPage(),
+		Progress: append([]SyncStatusInfo{}, SyncStatus()...),
+	}
+
+	// The block data is not yet set during the initial sync.
+	exp.pageData.RLock()
+	if exp.pageData.BlockInfo != nil {
+		status.Height = exp.pageData.BlockInfo.Height
+	}
+	exp.pageData.RUnlock()
+	return status
+}
+
 // StatsPage is the page handler for the "/stats" path
 func (exp *explorerUI) StatsPage(w http.ResponseWriter, r *http.Request) {
 	// Get current PoW difficulty.
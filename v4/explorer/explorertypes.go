@@ -145,6 +145,7 @@ func (t *TxInfo) IsVote() bool {
 type TicketInfo struct {
 	TicketMaturity       int64
 	TimeTillMaturity     float64 // Time before a particular ticket reaches maturity, in hours
+	BlocksTillMaturity   int64   // Blocks before a particular ticket is eligible to vote
 	PoolStatus           string
 	SpendStatus          string
 	TicketPoolSize       int64   // Total number of ticket in the pool
@@ -175,6 +176,59 @@ type VoteInfo struct {
 	ForLastBlock       bool                    `json:"last_block"`
 }
 
+// VoteBit describes the meaning of a single bit of a vote's vote bits. Item is
+// "validity" for the block validity bit (bit 0), the agenda ID for bits in an
+// agenda's mask, or empty for unassigned bits. For agenda bits, Choice is the
+// ID of the agenda choice expressed by the vote.
+type VoteBit struct {
+	Position uint   `json:"position"`
+	Set      bool   `json:"set"`
+	Item     string `json:"item,omitempty"`
+	Choice   string `json:"choice,omitempty"`
+}
+
+// BitsBreakdown decodes each of the vote's 16 vote bits, indicating which are
+// set and which vote item (block validity or agenda) each bit belongs to.
+func (v *VoteInfo) BitsBreakdown() []VoteBit {
+	return voteBitsBreakdown(v.Bits, v.Choices)
+}
+
+// voteBitsBreakdown maps each bit of the vote bits to the block validity flag
+// or the agenda whose mask includes the bit, as given by the vote choices from
+// txhelpers.SSGenVoteChoices.
+func voteBitsBreakdown(voteBits uint16, choices []*txhelpers.VoteChoice) []VoteBit {
+	bits := make([]VoteBit, 16)
+	for i := range bits {
+		bits[i].Position = uint(i)
+		bits[i].Set = voteBits&(1<<uint(i)) != 0
+	}
+
+	// Bit 0 is the previous block validity flag.
+	bits[0].Item = "validity"
+	if bits[0].Set {
+		bits[0].Choice = "yes"
+	} else {
+		bits[0].Choice = "no"
+	}
+
+	for _, vc := range choices {
+		if vc == nil {
+			continue
+		}
+		var choiceID string
+		if vc.Choice != nil {
+			choiceID = vc.Choice.Id
+		}
+		for i := range bits {
+			if vc.Mask&(1<<uint(i)) != 0 {
+				bits[i].Item = vc.ID
+				bits[i].Choice = choiceID
+			}
+		}
+	}
+	return bits
+}
+
 // BlockValidation models data about a vote's decision on a block
 type BlockValidation struct {
 	Hash     string `json:"hash"`
@@ -379,6 +433,24 @@ type MempoolTx struct {
 	VoteInfo  *VoteInfo      `json:"vote_info,omitempty"`
 }
 
+// FeeRateBucket is a fee rate range of a mempool fee rate histogram, with the
+// number and total size of the mempool transactions paying a fee rate in the
+// range. MaxFeeRate is zero for the last, unbounded, bucket.
+type FeeRateBucket struct {
+	MinFeeRate float64 `json:"min_fee_rate"`
+	MaxFeeRate float64 `json:"max_fee_rate,omitempty"`
+	Count      int     `json:"count"`
+	TotalSize  int64   `json:"total_size"`
+}
+
+// MempoolCounts is the number, total size in bytes, and total fees in atoms of
+// the mempool transactions.
+type MempoolCounts struct {
+	Count     int   `json:"count"`
+	TotalSize int64 `json:"total_size"`
+	TotalFees int64 `json:"total_fees"`
+}
+
 // NewMempoolTx models data sent from the notification handler
 type NewMempoolTx struct {
 	Time int64
@@ -562,6 +634,15 @@ type SyncStatusInfo struct {
 	ProgressBarID string `json:"progress_bar_id"`
 }
 
+// ExplorerSyncStatus is the machine-readable sync state of the explorer.
+// Syncing is true while the sync status page is served in place of the
+// explorer pages, and Height is the height of the explorer's current block.
+type ExplorerSyncStatus struct {
+	Syncing  bool             `json:"syncing"`
+	Height   int64            `json:"height"`
+	Progress []SyncStatusInfo `json:"progress"`
+}
+
 // SyncStatus defines a thread-safe way to read the sync status updates
 func SyncStatus() []SyncStatusInfo {
 	blockchainSyncStatus.RLock()
@@ -5,7 +5,11 @@
 package explorer
 
 import (
+	"fmt"
+	"math"
 	"sort"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/decred/dcrd/blockchain"
@@ -18,6 +22,92 @@ import (
 // be stored in MempoolData.LatestTransactions.
 const NumLatestMempoolTxns = 5
 
+// DefaultFeeRateBounds are the default lower bounds, in atoms/byte, of the
+// mempool fee rate histogram buckets after the first bucket, which starts at
+// zero.
+var DefaultFeeRateBounds = []float64{10, 50, 100, 200, 500, 1000, 5000}
+
+// FeeRateHistogram buckets the current mempool transactions by fee rate in
+// atoms/byte. See feeRateHistogram.
+func (mpi *MempoolInfo) FeeRateHistogram(bounds []float64) []FeeRateBucket {
+	mpi.RLock()
+	defer mpi.RUnlock()
+	txLists := [][]MempoolTx{mpi.Transactions, mpi.Tickets, mpi.Votes, mpi.Revocations}
+	return feeRateHistogram(bounds, txLists...)
+}
+
+// RetrieveMempoolTxCountAndSize computes the number, total size in bytes, and
+// total fees in atoms of the current mempool transactions of all types. Unlike
+// a copy of MempoolInfo, this does not duplicate the transaction slices.
+func (mpi *MempoolInfo) RetrieveMempoolTxCountAndSize() (count int, totalSize int64, totalFees int64) {
+	mpi.RLock()
+	defer mpi.RUnlock()
+	txLists := [][]MempoolTx{mpi.Transactions, mpi.Tickets, mpi.Votes, mpi.Revocations}
+	for _, txs := range txLists {
+		count += len(txs)
+		for i := range txs {
+			totalSize += int64(txs[i].Size)
+			totalFees += int64(math.Round(txs[i].Fees * 1e8))
+		}
+	}
+	return
+}
+
+// feeRateHistogram buckets the transactions by fee rate in atoms/byte. The
+// first bucket is [0, bounds[0]), the next are [bounds[i-1], bounds[i]), and the
+// last is [bounds[len(bounds)-1], inf). The bounds must be increasing. The fee
+// rate of a transaction without a size is undefined, so such transactions are
+// not counted.
+func feeRateHistogram(bounds []float64, txLists ...[]MempoolTx) []FeeRateBucket {
+	buckets := make([]FeeRateBucket, len(bounds)+1)
+	for i, bound := range bounds {
+		buckets[i].MaxFeeRate = bound
+		buckets[i+1].MinFeeRate = bound
+	}
+
+	for _, txs := range txLists {
+		for i := range txs {
+			tx := &txs[i]
+			if tx.Size <= 0 {
+				continue
+			}
+			var feeRate float64
+			if tx.Fees > 0 {
+				feeRate = math.Round(tx.Fees*1e8) / float64(tx.Size)
+			}
+			// The bucket index is the number of bounds not above the rate.
+			b := sort.Search(len(bounds), func(i int) bool {
+				return bounds[i] > feeRate
+			})
+			buckets[b].Count++
+			buckets[b].TotalSize += int64(tx.Size)
+		}
+	}
+	return buckets
+}
+
+// parseFeeRateBounds parses a comma-separated list of increasing, positive
+// fee rate bucket bounds.
+func parseFeeRateBounds(boundsStr string) ([]float64, error) {
+	const maxBounds = 50
+	strs := strings.Split(boundsStr, ",")
+	if len(strs) > maxBounds {
+		return nil, fmt.Errorf("at most %d bounds may be specified", maxBounds)
+	}
+	bounds := make([]float64, 0, len(strs))
+	for _, str := range strs {
+		bound, err := strconv.ParseFloat(strings.TrimSpace(str), 64)
+		if err != nil || bound <= 0 || math.IsInf(bound, 0) {
+			return nil, fmt.Errorf("invalid fee rate bound %q", str)
+		}
+		if len(bounds) > 0 && bound <= bounds[len(bounds)-1] {
+			return nil, fmt.Errorf("fee rate bounds must be increasing")
+		}
+		bounds = append(bounds, bound)
+	}
+	return bounds, nil
+}
+
 func (exp *explorerUI) mempoolMonitor(txChan chan *NewMempoolTx) {
 	// Get the initial best block hash, height, and time.
 	lastBlockHash, lastBlockHeight, lastBlockTime := exp.storeMempoolInfo()
@@ -38,7 +128,7 @@ func (exp *explorerUI) mempoolMonitor(txChan chan *NewMempoolTx) {
 		// A tx with an empty hex is the new block signal.
 		if ntx.Hex == "" {
 			lastBlockHash, lastBlockHeight, lastBlockTime = exp.storeMempoolInfo()
-			exp.wsHub.HubRelay <- sigMempoolUpdate
+			exp.wsHub.HubRelay <- SigMempoolUpdate
 			continue
 		}
 
@@ -170,7 +260,7 @@ func (exp *explorerUI) mempoolMonitor(txChan chan *NewMempoolTx) {
 		exp.MempoolData.Unlock()
 
 		// Broadcast the new transaction
-		exp.wsHub.HubRelay <- sigNewTx
+		exp.wsHub.HubRelay <- SigNewTx
 		exp.wsHub.NewTxChan <- &tx
 	}
 }
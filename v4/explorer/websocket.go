@@ -21,12 +21,19 @@ const (
 	bufferTickerInterval = 5
 	newTxBufferSize      = 5
 	clientSignalSize     = 5
+)
+
+// HubSignal is an event relayed by a websocket hub to its clients.
+type HubSignal int
 
-	sigNewBlock hubSignal = iota
-	sigMempoolUpdate
-	sigPingAndUserCount
-	sigNewTx
-	sigSyncStatus
+// The events relayed by the explorer's WebsocketHub. The Insight API's
+// websocket hub relays SigNewBlock and SigMempoolUpdate too.
+const (
+	SigNewBlock HubSignal = iota
+	SigMempoolUpdate
+	SigPingAndUserCount
+	SigNewTx
+	SigSyncStatus
 )
 
 // WebSocketMessage represents the JSON object used to send and received typed
@@ -37,12 +44,12 @@ type WebSocketMessage struct {
 }
 
 // Event type field for an SSE event
-var eventIDs = map[hubSignal]string{
-	sigNewBlock:         "newblock",
-	sigMempoolUpdate:    "mempool",
-	sigPingAndUserCount: "ping",
-	sigNewTx:            "newtx",
-	sigSyncStatus:       "blockchainSync",
+var eventIDs = map[HubSignal]string{
+	SigNewBlock:         "newblock",
+	SigMempoolUpdate:    "mempool",
+	SigPingAndUserCount: "ping",
+	SigNewTx:            "newtx",
+	SigSyncStatus:       "blockchainSync",
 }
 
 // WebsocketHub and its event loop manage all websocket client connections.
@@ -52,7 +59,7 @@ type WebsocketHub struct {
 	clients          map[*hubSpoke]*client
 	Register         chan *clientHubSpoke
 	Unregister       chan *hubSpoke
-	HubRelay         chan hubSignal
+	HubRelay         chan HubSignal
 	NewTxChan        chan *MempoolTx
 	newTxBuffer      []*MempoolTx
 	bufferMtx        *sync.Mutex
@@ -66,8 +73,7 @@ type client struct {
 	newTxs []*MempoolTx
 }
 
-type hubSignal int
-type hubSpoke chan hubSignal
+type hubSpoke chan HubSignal
 
 // NewWebsocketHub creates a new WebsocketHub
 func NewWebsocketHub() *WebsocketHub {
@@ -75,7 +81,7 @@ func NewWebsocketHub() *WebsocketHub {
 		clients:          make(map[*hubSpoke]*client),
 		Register:         make(chan *clientHubSpoke),
 		Unregister:       make(chan *hubSpoke),
-		HubRelay:         make(chan hubSignal),
+		HubRelay:         make(chan HubSignal),
 		NewTxChan:        make(chan *MempoolTx),
 		newTxBuffer:      make([]*MempoolTx, 0, newTxBufferSize),
 		bufferTickerChan: make(chan int, clientSignalSize),
@@ -141,7 +147,7 @@ func (wsh *WebsocketHub) pingClients() chan<- struct{} {
 		for {
 			select {
 			case <-ticker.C:
-				wsh.HubRelay <- sigPingAndUserCount
+				wsh.HubRelay <- SigPingAndUserCount
 			case _, ok := <-stopPing:
 				if !ok {
 					log.Errorf("Do not send on stopPing channel, only close it.")
@@ -190,29 +196,29 @@ func (wsh *WebsocketHub) run() {
 			clientsCount := len(wsh.clients)
 
 			switch hubSignal {
-			case sigNewBlock:
+			case SigNewBlock:
 				// Do not log when explorer update status is active.
 				if !SyncExplorerUpdateStatus() {
 					log.Infof("Signaling new block to %d websocket clients.", clientsCount)
 				}
-			case sigPingAndUserCount:
+			case SigPingAndUserCount:
 				log.Tracef("Signaling ping/user count to %d websocket clients.", clientsCount)
-			case sigMempoolUpdate:
+			case SigMempoolUpdate:
 				if clientsCount > 0 {
 					log.Infof("Signaling mempool update to %d websocket clients.", clientsCount)
 				}
-			case sigNewTx:
+			case SigNewTx:
 				newtx = <-wsh.NewTxChan
 				log.Tracef("Received new tx %s", newtx.Hash)
 				wsh.MaybeSendTxns(newtx)
-			case sigSyncStatus:
+			case SigSyncStatus:
 			default:
 				log.Errorf("Unknown hub signal: %v", hubSignal)
 				break events
 			}
 			for client := range wsh.clients {
 				// Don't signal the client on new tx, another case handles that
-				if hubSignal == sigNewTx {
+				if hubSignal == SigNewTx {
 					break
 				}
 				// Signal or unregister the client
@@ -259,7 +265,7 @@ func (wsh *WebsocketHub) run() {
 				client.newTxs = txs
 				client.Unlock()
 				select {
-				case *signal <- sigNewTx:
+				case *signal <- SigNewTx:
 				default:
 					wsh.unregisterClient(signal)
 				}
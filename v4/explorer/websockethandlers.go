@@ -221,7 +221,7 @@ func (exp *explorerUI) RootWebsocket(w http.ResponseWriter, r *http.Request) {
 				buff := new(bytes.Buffer)
 				enc := json.NewEncoder(buff)
 				switch sig {
-				case sigNewBlock:
+				case SigNewBlock:
 					exp.pageData.RLock()
 					enc.Encode(WebsocketBlock{
 						Block: exp.pageData.BlockInfo,
@@ -230,20 +230,20 @@ func (exp *explorerUI) RootWebsocket(w http.ResponseWriter, r *http.Request) {
 					exp.pageData.RUnlock()
 
 					webData.Message = buff.String()
-				case sigMempoolUpdate:
+				case SigMempoolUpdate:
 					exp.MempoolData.RLock()
 					enc.Encode(exp.MempoolData.MempoolShort)
 					exp.MempoolData.RUnlock()
 					webData.Message = buff.String()
-				case sigPingAndUserCount:
+				case SigPingAndUserCount:
 					// ping and send user count
 					webData.Message = strconv.Itoa(exp.wsHub.NumClients())
-				case sigNewTx:
+				case SigNewTx:
 					clientData.RLock()
 					enc.Encode(clientData.newTxs)
 					clientData.RUnlock()
 					webData.Message = buff.String()
-				case sigSyncStatus:
+				case SigSyncStatus:
 					enc.Encode(SyncStatus())
 					webData.Message = buff.String()
 				}
@@ -47,6 +47,7 @@ var NtfnChans struct {
 	NewTxChan                         chan *mempool.NewTx
 	ExpNewTxChan                      chan *explorer.NewMempoolTx
 	InsightNewTxChan                  chan *insight.NewTx
+	InsightWsNewTxChan                chan *insight.NewTx
 }
 
 // MakeNtfnChans create notification channels based on config
@@ -93,6 +94,7 @@ func MakeNtfnChans(monitorMempool, postgresEnabled bool) {
 
 	if postgresEnabled {
 		NtfnChans.InsightNewTxChan = make(chan *insight.NewTx, expNewTxChanBuffer)
+		NtfnChans.InsightWsNewTxChan = make(chan *insight.NewTx, expNewTxChanBuffer)
 	}
 }
 
@@ -152,4 +154,7 @@ func CloseNtfnChans() {
 	if NtfnChans.InsightNewTxChan != nil {
 		close(NtfnChans.InsightNewTxChan)
 	}
+	if NtfnChans.InsightWsNewTxChan != nil {
+		close(NtfnChans.InsightWsNewTxChan)
+	}
 }
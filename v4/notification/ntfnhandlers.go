@@ -386,6 +386,17 @@ func MakeNodeNtfnHandlers() (*rpcclient.NotificationHandlers, *collectionQueue)
 				}
 			}
 
+			select {
+			case NtfnChans.InsightWsNewTxChan <- &insight.NewTx{
+				Hex:   txDetails.Hex,
+				Vouts: txDetails.Vout,
+			}:
+			default:
+				if NtfnChans.InsightWsNewTxChan != nil {
+					log.Warn("InsightWsNewTxChan buffer full!")
+				}
+			}
+
 			hash, _ := chainhash.NewHashFromStr(txDetails.Txid)
 			select {
 			case NtfnChans.NewTxChan <- &mempool.NewTx{
@@ -6,9 +6,11 @@ package rpcutils
 
 import (
 	"encoding/hex"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io/ioutil"
+	"sort"
 	"strconv"
 
 	"github.com/decred/dcrd/chaincfg"
@@ -32,6 +34,8 @@ var (
 	zeroHash            = chainhash.Hash{}
 	zeroHashStringBytes = []byte(chainhash.Hash{}.String())
 
+	// maxAncestorChainLength is the default limit on the number of blocks
+	// searched by CommonAncestor.
 	maxAncestorChainLength = 8192
 
 	ErrAncestorAtGenesis      = errors.New("no ancestor: at genesis")
@@ -350,6 +354,59 @@ func GetTransactionVerboseByID(client *rpcclient.Client, txid string) (*dcrjson.
 	return txraw, nil
 }
 
+// rawTxReceiver is the promise of a verbose raw transaction result, such as a
+// rpcclient.FutureGetRawTransactionVerboseResult.
+type rawTxReceiver interface {
+	Receive() (*dcrjson.TxRawResult, error)
+}
+
+// BatchGetRawTransactions gets the verbose results for the transactions with
+// the given ids. The requests are all sent before any of the responses are
+// received, so that there is only one round trip to the node. The results are
+// in the same order as txids. If any request fails, the returned error
+// identifies the txid.
+func BatchGetRawTransactions(client *rpcclient.Client, txids []string) ([]*dcrjson.TxRawResult, error) {
+	return batchGetRawTransactions(func(txHash *chainhash.Hash) rawTxReceiver {
+		return client.GetRawTransactionVerboseAsync(txHash)
+	}, txids)
+}
+
+func batchGetRawTransactions(request func(*chainhash.Hash) rawTxReceiver, txids []string) ([]*dcrjson.TxRawResult, error) {
+	// Validate all of the txids before sending any requests.
+	txHashes := make([]*chainhash.Hash, 0, len(txids))
+	for _, txid := range txids {
+		txHash, err := chainhash.NewHashFromStr(txid)
+		if err != nil {
+			return nil, fmt.Errorf("invalid transaction hash %s: %v", txid, err)
+		}
+		txHashes = append(txHashes, txHash)
+	}
+
+	futures := make([]rawTxReceiver, 0, len(txHashes))
+	for _, txHash := range txHashes {
+		futures = append(futures, request(txHash))
+	}
+
+	// Receive every response, even after a failure, so none are left pending.
+	txs := make([]*dcrjson.TxRawResult, len(futures))
+	var firstErr error
+	for i, future := range futures {
+		tx, err := future.Receive()
+		if err != nil {
+			if firstErr == nil {
+				firstErr = fmt.Errorf("GetRawTransactionVerbose failed for %s: %v",
+					txids[i], err)
+			}
+			continue
+		}
+		txs[i] = tx
+	}
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	return txs, nil
+}
+
 // SearchRawTransaction fetch transactions the belong to an
 // address
 func SearchRawTransaction(client *rpcclient.Client, count int, address string) ([]*dcrjson.SearchRawTransactionsResult, error) {
@@ -376,15 +433,35 @@ func SearchRawTransaction(client *rpcclient.Client, count int, address string) (
 // other chain, that block will be shared between the two chains, and the common
 // ancestor will be the previous block. However, the intended use of this
 // function is to find a common ancestor for two chains with no common blocks.
+// ErrAncestorMaxChainLength is returned if the search exceeds 8192 blocks. Use
+// CommonAncestorLimit to search longer chains.
 func CommonAncestor(client *rpcclient.Client, hashA, hashB chainhash.Hash) (*chainhash.Hash, []chainhash.Hash, []chainhash.Hash, error) {
+	return CommonAncestorLimit(client, hashA, hashB, maxAncestorChainLength)
+}
+
+// CommonAncestorLimit is like CommonAncestor, but with the limit on the number
+// of blocks searched before returning ErrAncestorMaxChainLength set by maxLen.
+func CommonAncestorLimit(client *rpcclient.Client, hashA, hashB chainhash.Hash, maxLen int) (*chainhash.Hash, []chainhash.Hash, []chainhash.Hash, error) {
 	if client == nil {
 		return nil, nil, nil, errors.New("nil RPC client")
 	}
+	return commonAncestor(client, hashA, hashB, maxLen)
+}
+
+// msgBlockGetter is satisfied by *rpcclient.Client.
+type msgBlockGetter interface {
+	GetBlock(blockHash *chainhash.Hash) (*wire.MsgBlock, error)
+}
+
+func commonAncestor(client msgBlockGetter, hashA, hashB chainhash.Hash, maxLen int) (*chainhash.Hash, []chainhash.Hash, []chainhash.Hash, error) {
+	if maxLen <= 0 {
+		return nil, nil, nil, fmt.Errorf("invalid max chain length %d", maxLen)
+	}
 
 	var length int
 	var chainA, chainB []chainhash.Hash
 	for {
-		if length >= maxAncestorChainLength {
+		if length >= maxLen {
 			return nil, nil, nil, ErrAncestorMaxChainLength
 		}
 
@@ -463,21 +540,76 @@ func GetChainWork(client *rpcclient.Client, hash *chainhash.Hash) (string, error
 // that paid to the address.
 func UnconfirmedTxnsForAddress(client *rpcclient.Client, address string, params *chaincfg.Params) (*txhelpers.AddressOutpoints, int64, error) {
 	// Mempool transactions
-	var numUnconfirmed int64
 	mempoolTxns, err := client.GetRawMempoolVerbose(dcrjson.GRMAll)
 	if err != nil {
 		log.Warnf("GetRawMempool failed for address %s: %v", address, err)
-		return nil, numUnconfirmed, err
+		return nil, 0, err
+	}
+
+	return UnconfirmedTxnsForAddressInMempool(client, address, mempoolTxns, params)
+}
+
+// UnconfirmedTxnsForAddressInMempool is like UnconfirmedTxnsForAddress, but it
+// scans the given mempool snapshot, as returned by GetRawMempoolVerbose, rather
+// than requesting the mempool from the node. Callers checking many addresses
+// may thus request the mempool only once.
+func UnconfirmedTxnsForAddressInMempool(client *rpcclient.Client, address string,
+	mempoolTxns map[string]dcrjson.GetRawMempoolVerboseResult,
+	params *chaincfg.Params) (*txhelpers.AddressOutpoints, int64, error) {
+	return unconfirmedTxnsForAddressInMempool(client, address, mempoolTxns, params)
+}
+
+// mempoolTxGetter is satisfied by rpcclient.Client, and provides the mempool
+// transactions and their previous outpoints' transactions.
+type mempoolTxGetter interface {
+	txhelpers.RawTransactionGetter
+	txhelpers.VerboseTransactionGetter
+}
+
+func unconfirmedTxnsForAddressInMempool(client mempoolTxGetter, address string,
+	mempoolTxns map[string]dcrjson.GetRawMempoolVerboseResult,
+	params *chaincfg.Params) (*txhelpers.AddressOutpoints, int64, error) {
+	addressOuts, err := unconfirmedTxnsForAddressesInMempool(client,
+		[]string{address}, mempoolTxns, params)
+	outs := addressOuts[address]
+	return outs, numAddressTxns(outs), err
+}
+
+// UnconfirmedTxnsForAddresses is like UnconfirmedTxnsForAddress, but for a set
+// of addresses. The mempool is requested, and each of its transactions
+// scanned, only once regardless of the number of addresses. The returned map
+// has an AddressOutpoints for each of the addresses.
+func UnconfirmedTxnsForAddresses(client *rpcclient.Client, addresses []string,
+	params *chaincfg.Params) (map[string]*txhelpers.AddressOutpoints, error) {
+	// Mempool transactions
+	mempoolTxns, err := client.GetRawMempoolVerbose(dcrjson.GRMAll)
+	if err != nil {
+		log.Warnf("GetRawMempool failed for %d addresses: %v", len(addresses), err)
+		return nil, err
 	}
 
-	// Check each transaction for involvement with provided address.
-	addressOutpoints := txhelpers.NewAddressOutpoints(address)
+	return unconfirmedTxnsForAddressesInMempool(client, addresses, mempoolTxns, params)
+}
+
+func unconfirmedTxnsForAddressesInMempool(client mempoolTxGetter, addresses []string,
+	mempoolTxns map[string]dcrjson.GetRawMempoolVerboseResult,
+	params *chaincfg.Params) (map[string]*txhelpers.AddressOutpoints, error) {
+	var err error
+
+	addrs := make(map[string]txhelpers.TxAction, len(addresses))
+	addressOuts := make(map[string]*txhelpers.AddressOutpoints, len(addresses))
+	for _, address := range addresses {
+		addrs[address] = txhelpers.TxInserted
+		addressOuts[address] = txhelpers.NewAddressOutpoints(address)
+	}
+
+	// Check each transaction for involvement with the provided addresses.
 	for hash, tx := range mempoolTxns {
 		// Transaction details from dcrd
 		txhash, err1 := chainhash.NewHashFromStr(hash)
 		if err1 != nil {
 			log.Errorf("Invalid transaction hash %s", hash)
-			return addressOutpoints, 0, err1
+			return addressOuts, err1
 		}
 
 		Tx, err1 := client.GetRawTransaction(txhash)
@@ -486,27 +618,148 @@ func UnconfirmedTxnsForAddress(client *rpcclient.Client, address string, params
 			err = err1
 			continue
 		}
-		// Scan transaction for inputs/outputs involving the address of interest
-		outpoints, prevouts, prevTxns := txhelpers.TxInvolvesAddress(Tx.MsgTx(),
-			address, client, params)
-		if len(outpoints) == 0 && len(prevouts) == 0 {
-			continue
+		// Scan transaction for inputs/outputs involving the addresses of
+		// interest.
+		involved, err1 := txhelpers.TxInvolvesAddresses(Tx.MsgTx(), addrs, client, params)
+		if err1 != nil {
+			// Like UnconfirmedTxnsForAddress, skip what cannot be checked.
+			log.Warnf("Unable to fully check transaction %s: %v", hash, err1)
 		}
-		// Update previous outpoint txn slice with mempool time
-		for f := range prevTxns {
-			prevTxns[f].MemPoolTime = tx.Time
+		for address, outs := range involved {
+			// Update previous outpoint txns with mempool time
+			for _, prevTx := range outs.TxnsStore {
+				prevTx.MemPoolTime = tx.Time
+			}
+			// Add present transaction to the txns, and merge the I/Os and the
+			// transactions into results.
+			outs.Update([]*txhelpers.TxWithBlockData{{
+				Tx:          Tx.MsgTx(),
+				MemPoolTime: tx.Time,
+			}}, nil, nil)
+			addressOuts[address].Merge(outs)
 		}
+	}
+
+	return addressOuts, err
+}
+
+// numAddressTxns counts the distinct transactions paying to or spending from
+// the address of the AddressOutpoints.
+func numAddressTxns(outs *txhelpers.AddressOutpoints) int64 {
+	if outs == nil {
+		return 0
+	}
+	txns := make(map[chainhash.Hash]struct{})
+	for _, op := range outs.Outpoints {
+		txns[op.Hash] = struct{}{}
+	}
+	for _, prevOut := range outs.PrevOuts {
+		txns[prevOut.TxSpending] = struct{}{}
+	}
+	return int64(len(txns))
+}
+
+// MempoolDependencyDepth finds the longest chain of mempool transactions that
+// spend the outputs of other mempool transactions. The length of the chain and
+// the txids in the chain, ordered from the earliest ancestor to the last
+// descendant, are returned. A mempool with no inter-dependent transactions has
+// a depth of 1, while an empty mempool has a depth of 0.
+func MempoolDependencyDepth(client *rpcclient.Client) (int, []string, error) {
+	mempoolTxns, err := client.GetRawMempoolVerbose(dcrjson.GRMAll)
+	if err != nil {
+		return 0, nil, err
+	}
 
-		// Add present transaction to previous outpoint txn slice
-		numUnconfirmed++
-		thisTxUnconfirmed := &txhelpers.TxWithBlockData{
-			Tx:          Tx.MsgTx(),
-			MemPoolTime: tx.Time,
+	depth, chain := mempoolDeepestChain(mempoolTxns)
+	return depth, chain, nil
+}
+
+func mempoolDeepestChain(mempoolTxns map[string]dcrjson.GetRawMempoolVerboseResult) (int, []string) {
+	// depths records the length of the longest chain ending with each
+	// transaction, and parents the in-mempool dependency on that chain.
+	depths := make(map[string]int, len(mempoolTxns))
+	parents := make(map[string]string, len(mempoolTxns))
+
+	var depthOf func(txid string) int
+	depthOf = func(txid string) int {
+		if d, ok := depths[txid]; ok {
+			return d
+		}
+		// Mark as visited to guard against dependency cycles.
+		depths[txid] = 1
+		depth := 1
+		for _, dep := range mempoolTxns[txid].Depends {
+			// Only dependencies that are themselves in mempool count.
+			if _, inMempool := mempoolTxns[dep]; !inMempool {
+				continue
+			}
+			if d := depthOf(dep) + 1; d > depth {
+				depth = d
+				parents[txid] = dep
+			}
+		}
+		depths[txid] = depth
+		return depth
+	}
+
+	// Sort the txids so that ties are broken consistently.
+	txids := make([]string, 0, len(mempoolTxns))
+	for txid := range mempoolTxns {
+		txids = append(txids, txid)
+	}
+	sort.Strings(txids)
+
+	var maxDepth int
+	var deepest string
+	for _, txid := range txids {
+		if d := depthOf(txid); d > maxDepth {
+			maxDepth, deepest = d, txid
 		}
-		prevTxns = append(prevTxns, thisTxUnconfirmed)
-		// Merge the I/Os and the transactions into results
-		addressOutpoints.Update(prevTxns, outpoints, prevouts)
 	}
+	if maxDepth == 0 {
+		return 0, nil
+	}
+
+	// Walk back from the deepest descendant to its earliest ancestor.
+	chain := make([]string, 0, maxDepth)
+	for txid, ok := deepest, true; ok; txid, ok = parents[txid] {
+		chain = append(chain, txid)
+	}
+	reverseStringSlice(chain)
+
+	return maxDepth, chain
+}
 
-	return addressOutpoints, numUnconfirmed, err
+// EstimateSmartFee requests from the node an estimate of the fee rate, in
+// coins/kB, required for a transaction to be mined within the specified number
+// of blocks (the estimatesmartfee RPC).
+func EstimateSmartFee(client *rpcclient.Client, confirmations int64) (float64, error) {
+	param, err := json.Marshal(confirmations)
+	if err != nil {
+		return 0, err
+	}
+	res, err := client.RawRequest("estimatesmartfee", []json.RawMessage{param})
+	if err != nil {
+		return 0, err
+	}
+	return parseEstimateSmartFee(res)
+}
+
+// parseEstimateSmartFee decodes an estimatesmartfee result, which is either a
+// bare fee rate or a dcrjson.EstimateSmartFeeResult, depending on the node
+// version.
+func parseEstimateSmartFee(res json.RawMessage) (float64, error) {
+	var feeRate float64
+	if err := json.Unmarshal(res, &feeRate); err == nil {
+		return feeRate, nil
+	}
+
+	var estimate dcrjson.EstimateSmartFeeResult
+	if err := json.Unmarshal(res, &estimate); err != nil {
+		return 0, fmt.Errorf("invalid estimatesmartfee result: %v", err)
+	}
+	if len(estimate.Errors) > 0 {
+		return 0, fmt.Errorf("estimatesmartfee failed: %s", estimate.Errors[0])
+	}
+	return estimate.FeeRate, nil
 }
@@ -1,10 +1,21 @@
 package rpcutils
 
 import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"reflect"
+	"strings"
 	"testing"
 
+	"github.com/decred/dcrd/chaincfg"
+	"github.com/decred/dcrd/chaincfg/chainhash"
 	"github.com/decred/dcrd/dcrjson"
+	"github.com/decred/dcrd/dcrutil"
+	"github.com/decred/dcrd/txscript"
+	"github.com/decred/dcrd/wire"
 )
 
 func TestSideChainTips(t *testing.T) {
@@ -114,3 +125,392 @@ func TestReverseStringSlice(t *testing.T) {
 		t.Errorf("reverseStringSlice failed. Got %v, expected %v.", s2, ref2)
 	}
 }
+
+func TestMempoolDeepestChain(t *testing.T) {
+	// A three-deep chain (a <- b <- c), an independent transaction, and a
+	// transaction depending on one that is no longer in mempool.
+	mempoolTxns := map[string]dcrjson.GetRawMempoolVerboseResult{
+		"a": {},
+		"b": {Depends: []string{"a"}},
+		"c": {Depends: []string{"b", "a"}},
+		"d": {},
+		"e": {Depends: []string{"mined"}},
+	}
+
+	depth, chain := mempoolDeepestChain(mempoolTxns)
+	if depth != 3 {
+		t.Errorf("Incorrect dependency depth. Got %d, expected 3.", depth)
+	}
+	ref := []string{"a", "b", "c"}
+	if !reflect.DeepEqual(chain, ref) {
+		t.Errorf("Incorrect deepest chain. Got %v, expected %v.", chain, ref)
+	}
+
+	// Empty mempool
+	depth, chain = mempoolDeepestChain(nil)
+	if depth != 0 || chain != nil {
+		t.Errorf("Expected no chain for empty mempool, got %d, %v.", depth, chain)
+	}
+}
+
+func TestParseEstimateSmartFee(t *testing.T) {
+	tests := []struct {
+		res     string
+		feeRate float64
+		wantErr bool
+	}{
+		{`0.0001`, 0.0001, false},
+		{`{"feerate": 0.0002, "blocks": 2}`, 0.0002, false},
+		{`{"feerate": 0, "errors": ["insufficient data"], "blocks": 0}`, 0, true},
+		{`"nope"`, 0, true},
+	}
+	for _, tt := range tests {
+		feeRate, err := parseEstimateSmartFee(json.RawMessage(tt.res))
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s: unexpected error state: %v", tt.res, err)
+			continue
+		}
+		if feeRate != tt.feeRate {
+			t.Errorf("%s: incorrect fee rate. Got %v, wanted %v.", tt.res, feeRate, tt.feeRate)
+		}
+	}
+}
+
+// fakeRawTxFuture is a rawTxReceiver whose result is delivered by the fake
+// client after all requests are sent.
+type fakeRawTxFuture struct {
+	result chan *dcrjson.TxRawResult
+	err    error
+}
+
+func (f *fakeRawTxFuture) Receive() (*dcrjson.TxRawResult, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	return <-f.result, nil
+}
+
+// fakeRawTxClient records requests and fails for the txids in failures.
+type fakeRawTxClient struct {
+	futures  []*fakeRawTxFuture
+	hashes   []string
+	failures map[string]bool
+}
+
+func (c *fakeRawTxClient) request(txHash *chainhash.Hash) rawTxReceiver {
+	f := &fakeRawTxFuture{result: make(chan *dcrjson.TxRawResult, 1)}
+	if c.failures[txHash.String()] {
+		f.err = errors.New("no such transaction")
+	}
+	c.futures = append(c.futures, f)
+	c.hashes = append(c.hashes, txHash.String())
+	return f
+}
+
+// respond delivers the responses in reverse order of the requests.
+func (c *fakeRawTxClient) respond() {
+	for i := len(c.futures) - 1; i >= 0; i-- {
+		c.futures[i].result <- &dcrjson.TxRawResult{Txid: c.hashes[i]}
+	}
+}
+
+func TestBatchGetRawTransactions(t *testing.T) {
+	var txids []string
+	for i := 0; i < 5; i++ {
+		txids = append(txids, chainhash.HashH([]byte{byte(i)}).String())
+	}
+
+	client := &fakeRawTxClient{}
+	request := func(txHash *chainhash.Hash) rawTxReceiver {
+		f := client.request(txHash)
+		// All requests are sent before any response is received.
+		if len(client.futures) == len(txids) {
+			client.respond()
+		}
+		return f
+	}
+	txs, err := batchGetRawTransactions(request, txids)
+	if err != nil {
+		t.Fatalf("batchGetRawTransactions: %v", err)
+	}
+	if len(txs) != len(txids) {
+		t.Fatalf("Got %d transactions, wanted %d.", len(txs), len(txids))
+	}
+	for i, tx := range txs {
+		if tx.Txid != txids[i] {
+			t.Errorf("Transaction %d out of order. Got %s, wanted %s.", i, tx.Txid, txids[i])
+		}
+	}
+
+	// A failed request is reported with its txid.
+	client = &fakeRawTxClient{failures: map[string]bool{txids[2]: true}}
+	_, err = batchGetRawTransactions(request, txids)
+	if err == nil || !strings.Contains(err.Error(), txids[2]) {
+		t.Errorf("Expected an error identifying %s, got %v.", txids[2], err)
+	}
+
+	// Invalid txids fail before any requests are sent.
+	client = &fakeRawTxClient{}
+	_, err = batchGetRawTransactions(request, []string{txids[0], "notahash"})
+	if err == nil || len(client.futures) != 0 {
+		t.Errorf("Expected an error without requests, got %v after %d requests.",
+			err, len(client.futures))
+	}
+}
+
+// fakeChain is a msgBlockGetter for an in-memory block tree.
+type fakeChain map[chainhash.Hash]*wire.MsgBlock
+
+func (c fakeChain) GetBlock(blockHash *chainhash.Hash) (*wire.MsgBlock, error) {
+	block, ok := c[*blockHash]
+	if !ok {
+		return nil, errors.New("block not found")
+	}
+	return block, nil
+}
+
+// extend adds n blocks to the chain after prev, returning their hashes. The
+// nonce distinguishes blocks at the same height on different branches.
+func (c fakeChain) extend(prev chainhash.Hash, height uint32, n int, nonce uint32) []chainhash.Hash {
+	hashes := make([]chainhash.Hash, 0, n)
+	for i := 0; i < n; i++ {
+		block := &wire.MsgBlock{Header: wire.BlockHeader{
+			PrevBlock: prev,
+			Height:    height + uint32(i),
+			Nonce:     nonce,
+		}}
+		prev = block.BlockHash()
+		c[prev] = block
+		hashes = append(hashes, prev)
+	}
+	return hashes
+}
+
+func TestCommonAncestorLimit(t *testing.T) {
+	// A main chain of blocks 0-20, and a side chain of blocks 11-15 forking
+	// from block 10.
+	chain := make(fakeChain)
+	mainChain := chain.extend(chainhash.Hash{}, 0, 21, 0)
+	sideChain := chain.extend(mainChain[10], 11, 5, 1)
+	tipA, tipB := mainChain[20], sideChain[4]
+
+	// Finding block 10 requires searching 10 blocks.
+	ancestor, chainA, chainB, err := commonAncestor(chain, tipA, tipB, 10)
+	if err != nil {
+		t.Fatalf("commonAncestor: %v", err)
+	}
+	if *ancestor != mainChain[10] {
+		t.Errorf("Incorrect common ancestor %v, wanted %v.", ancestor, mainChain[10])
+	}
+	if !reflect.DeepEqual(chainA, mainChain[11:]) {
+		t.Errorf("Incorrect chain A: %v", chainA)
+	}
+	if !reflect.DeepEqual(chainB, sideChain) {
+		t.Errorf("Incorrect chain B: %v", chainB)
+	}
+
+	_, _, _, err = commonAncestor(chain, tipA, tipB, 9)
+	if err != ErrAncestorMaxChainLength {
+		t.Errorf("Expected %v, got %v.", ErrAncestorMaxChainLength, err)
+	}
+
+	if _, _, _, err = commonAncestor(chain, tipA, tipB, 0); err == nil {
+		t.Error("A max chain length of 0 should be rejected.")
+	}
+}
+
+// fakeTxStore is a mempoolTxGetter serving transactions from a map.
+type fakeTxStore map[chainhash.Hash]*wire.MsgTx
+
+func (s fakeTxStore) GetRawTransaction(txHash *chainhash.Hash) (*dcrutil.Tx, error) {
+	tx, ok := s[*txHash]
+	if !ok {
+		return nil, fmt.Errorf("unknown transaction %v", txHash)
+	}
+	return dcrutil.NewTx(tx), nil
+}
+
+func (s fakeTxStore) GetRawTransactionVerbose(txHash *chainhash.Hash) (*dcrjson.TxRawResult, error) {
+	tx, ok := s[*txHash]
+	if !ok {
+		return nil, fmt.Errorf("unknown transaction %v", txHash)
+	}
+	var buf bytes.Buffer
+	if err := tx.Serialize(&buf); err != nil {
+		return nil, err
+	}
+	return &dcrjson.TxRawResult{
+		Hex:         hex.EncodeToString(buf.Bytes()),
+		Txid:        txHash.String(),
+		BlockHeight: 1000,
+	}, nil
+}
+
+func TestUnconfirmedTxnsForAddressInMempool(t *testing.T) {
+	params := &chaincfg.MainNetParams
+	payTo := func(b byte) (string, []byte) {
+		addr, err := dcrutil.NewAddressPubKeyHash(bytes.Repeat([]byte{b}, 20), params, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		script, err := txscript.PayToAddrScript(addr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return addr.EncodeAddress(), script
+	}
+	addrA, scriptA := payTo(1)
+	addrB, scriptB := payTo(2)
+
+	// A confirmed transaction paying to A, with a mempool transaction paying
+	// to B spending it, and another mempool transaction paying to A.
+	prevTx := wire.NewMsgTx()
+	prevTx.AddTxIn(wire.NewTxIn(wire.NewOutPoint(&chainhash.Hash{1}, 0, wire.TxTreeRegular), 5e8, nil))
+	prevTx.AddTxOut(wire.NewTxOut(5e8, scriptA))
+	prevHash := prevTx.TxHash()
+
+	tx1 := wire.NewMsgTx()
+	tx1.AddTxIn(wire.NewTxIn(wire.NewOutPoint(&chainhash.Hash{2}, 0, wire.TxTreeRegular), 2e8, nil))
+	tx1.AddTxOut(wire.NewTxOut(2e8, scriptA))
+	tx1Hash := tx1.TxHash()
+
+	tx2 := wire.NewMsgTx()
+	tx2.AddTxIn(wire.NewTxIn(wire.NewOutPoint(&prevHash, 0, wire.TxTreeRegular), 5e8, nil))
+	tx2.AddTxOut(wire.NewTxOut(4e8, scriptB))
+	tx2Hash := tx2.TxHash()
+
+	client := fakeTxStore{prevHash: prevTx, tx1Hash: tx1, tx2Hash: tx2}
+	mempoolTxns := map[string]dcrjson.GetRawMempoolVerboseResult{
+		tx1Hash.String(): {Time: 1540000000},
+		tx2Hash.String(): {Time: 1540000001},
+	}
+
+	// Both addresses are scanned against the same mempool snapshot.
+	outsA, numA, err := unconfirmedTxnsForAddressInMempool(client, addrA, mempoolTxns, params)
+	if err != nil {
+		t.Fatal(err)
+	}
+	outsB, numB, err := unconfirmedTxnsForAddressInMempool(client, addrB, mempoolTxns, params)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if numA != 2 || len(outsA.Outpoints) != 1 || outsA.Outpoints[0].Hash != tx1Hash {
+		t.Errorf("Wrong outpoints for address A (%d unconfirmed): %v", numA, outsA.Outpoints)
+	}
+	if len(outsA.PrevOuts) != 1 || outsA.PrevOuts[0].TxSpending != tx2Hash ||
+		outsA.PrevOuts[0].PreviousOutpoint.Hash != prevHash {
+		t.Errorf("Wrong previous outpoints for address A: %v", outsA.PrevOuts)
+	}
+	if numB != 1 || len(outsB.Outpoints) != 1 || outsB.Outpoints[0].Hash != tx2Hash ||
+		len(outsB.PrevOuts) != 0 {
+		t.Errorf("Wrong outpoints for address B (%d unconfirmed): %v, %v",
+			numB, outsB.Outpoints, outsB.PrevOuts)
+	}
+	if txB := outsB.TxnsStore[tx2Hash]; txB == nil || txB.MemPoolTime != 1540000001 {
+		t.Errorf("Mempool transaction for address B not stored with its time: %v", txB)
+	}
+}
+
+// countingTxStore is a fakeTxStore that counts the requests for each
+// transaction.
+type countingTxStore struct {
+	fakeTxStore
+	requests map[chainhash.Hash]int
+}
+
+func (s *countingTxStore) GetRawTransaction(txHash *chainhash.Hash) (*dcrutil.Tx, error) {
+	s.requests[*txHash]++
+	return s.fakeTxStore.GetRawTransaction(txHash)
+}
+
+func TestUnconfirmedTxnsForAddressesInMempool(t *testing.T) {
+	params := &chaincfg.MainNetParams
+	payTo := func(b byte) (string, []byte) {
+		addr, err := dcrutil.NewAddressPubKeyHash(bytes.Repeat([]byte{b}, 20), params, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		script, err := txscript.PayToAddrScript(addr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return addr.EncodeAddress(), script
+	}
+	addrA, scriptA := payTo(1)
+	addrB, scriptB := payTo(2)
+	addrC, _ := payTo(3)
+	_, scriptD := payTo(4)
+
+	// A confirmed transaction paying to A. In mempool, one transaction spends
+	// it paying to both B and an address that is not requested, and another
+	// pays to A. C is not involved in any transaction.
+	prevTx := wire.NewMsgTx()
+	prevTx.AddTxIn(wire.NewTxIn(wire.NewOutPoint(&chainhash.Hash{1}, 0, wire.TxTreeRegular), 5e8, nil))
+	prevTx.AddTxOut(wire.NewTxOut(5e8, scriptA))
+	prevHash := prevTx.TxHash()
+
+	tx1 := wire.NewMsgTx()
+	tx1.AddTxIn(wire.NewTxIn(wire.NewOutPoint(&prevHash, 0, wire.TxTreeRegular), 5e8, nil))
+	tx1.AddTxOut(wire.NewTxOut(1e8, scriptD))
+	tx1.AddTxOut(wire.NewTxOut(3e8, scriptB))
+	tx1Hash := tx1.TxHash()
+
+	tx2 := wire.NewMsgTx()
+	tx2.AddTxIn(wire.NewTxIn(wire.NewOutPoint(&chainhash.Hash{2}, 0, wire.TxTreeRegular), 2e8, nil))
+	tx2.AddTxOut(wire.NewTxOut(2e8, scriptA))
+	tx2Hash := tx2.TxHash()
+
+	client := &countingTxStore{
+		fakeTxStore: fakeTxStore{prevHash: prevTx, tx1Hash: tx1, tx2Hash: tx2},
+		requests:    make(map[chainhash.Hash]int),
+	}
+	mempoolTxns := map[string]dcrjson.GetRawMempoolVerboseResult{
+		tx1Hash.String(): {Time: 1540000000},
+		tx2Hash.String(): {Time: 1540000001},
+	}
+
+	addressOuts, err := unconfirmedTxnsForAddressesInMempool(client,
+		[]string{addrA, addrB, addrC}, mempoolTxns, params)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Each mempool transaction is requested once for all addresses.
+	for _, h := range []chainhash.Hash{tx1Hash, tx2Hash} {
+		if n := client.requests[h]; n != 1 {
+			t.Errorf("Transaction %v requested %d times, wanted 1.", h, n)
+		}
+	}
+
+	outsA, outsB, outsC := addressOuts[addrA], addressOuts[addrB], addressOuts[addrC]
+	if outsA == nil || outsB == nil || outsC == nil {
+		t.Fatalf("Missing results for requested addresses: %v", addressOuts)
+	}
+	if len(outsA.Outpoints) != 1 || outsA.Outpoints[0].Hash != tx2Hash {
+		t.Errorf("Wrong outpoints for address A: %v", outsA.Outpoints)
+	}
+	if len(outsA.PrevOuts) != 1 || outsA.PrevOuts[0].TxSpending != tx1Hash ||
+		outsA.PrevOuts[0].PreviousOutpoint.Hash != prevHash {
+		t.Errorf("Wrong previous outpoints for address A: %v", outsA.PrevOuts)
+	}
+	if prev := outsA.TxnsStore[prevHash]; prev == nil || prev.MemPoolTime != 1540000000 {
+		t.Errorf("Spent transaction for address A not stored with the spending time: %v", prev)
+	}
+	if n := numAddressTxns(outsA); n != 2 {
+		t.Errorf("Address A involved in %d transactions, wanted 2.", n)
+	}
+	if len(outsB.Outpoints) != 1 || *outsB.Outpoints[0] != *wire.NewOutPoint(&tx1Hash, 1, wire.TxTreeRegular) ||
+		len(outsB.PrevOuts) != 0 {
+		t.Errorf("Wrong outpoints for address B: %v, %v", outsB.Outpoints, outsB.PrevOuts)
+	}
+	if txB := outsB.TxnsStore[tx1Hash]; txB == nil || txB.MemPoolTime != 1540000000 {
+		t.Errorf("Mempool transaction for address B not stored with its time: %v", txB)
+	}
+	if len(outsC.Outpoints) != 0 || len(outsC.PrevOuts) != 0 || len(outsC.TxnsStore) != 0 {
+		t.Errorf("Address C should not be involved: %+v", outsC)
+	}
+	if len(addressOuts) != 3 {
+		t.Errorf("Results for %d addresses, wanted 3.", len(addressOuts))
+	}
+}
@@ -238,6 +238,100 @@ func TxInvolvesAddress(msgTx *wire.MsgTx, addr string, c VerboseTransactionGette
 	return
 }
 
+// TxInvolvesAddresses is like TxInvolvesAddress, but checks the transaction
+// for involvement of any of the addresses in addrs, getting the transaction of
+// each previous outpoint only once. The TxAction for each address is not
+// important. The returned map has an AddressOutpoints for each involved
+// address, with the previous transactions spent by the transaction in its
+// TxnsStore. An output or input that cannot be checked is skipped, and the
+// last such error is returned with the involvement found in the others.
+func TxInvolvesAddresses(msgTx *wire.MsgTx, addrs map[string]TxAction,
+	c VerboseTransactionGetter, params *chaincfg.Params) (map[string]*AddressOutpoints, error) {
+	var err error
+	involved := make(map[string]*AddressOutpoints)
+	outpointsFor := func(addr string) *AddressOutpoints {
+		ao, ok := involved[addr]
+		if !ok {
+			ao = NewAddressOutpoints(addr)
+			involved[addr] = ao
+		}
+		return ao
+	}
+
+	// The outpoints of this transaction paying to the addresses.
+	txTree := TxTree(msgTx)
+	hash := msgTx.TxHash()
+	for outIndex, txOut := range msgTx.TxOut {
+		_, txOutAddrs, _, err1 := txscript.ExtractPkScriptAddrs(txOut.Version,
+			txOut.PkScript, params)
+		if err1 != nil {
+			err = fmt.Errorf("ExtractPkScriptAddrs: %v", err1)
+			continue
+		}
+		for _, txAddr := range txOutAddrs {
+			addrstr := txAddr.EncodeAddress()
+			if _, ok := addrs[addrstr]; ok {
+				ao := outpointsFor(addrstr)
+				ao.Outpoints = append(ao.Outpoints, wire.NewOutPoint(&hash,
+					uint32(outIndex), txTree))
+			}
+		}
+	}
+
+	// The inputs of this transaction funded by outpoints of previous
+	// transactions paying to the addresses.
+	for inIdx, txIn := range msgTx.TxIn {
+		prevOut := &txIn.PreviousOutPoint
+		if bytes.Equal(zeroHash[:], prevOut.Hash[:]) {
+			continue
+		}
+		prevTxRaw, err1 := c.GetRawTransactionVerbose(&prevOut.Hash)
+		if err1 != nil {
+			err = fmt.Errorf("unable to get raw transaction for %s: %v",
+				prevOut.Hash.String(), err1)
+			continue
+		}
+		prevTx, err1 := MsgTxFromHex(prevTxRaw.Hex)
+		if err1 != nil {
+			err = fmt.Errorf("MsgTxFromHex failed: %v", err1)
+			continue
+		}
+		if int(prevOut.Index) >= len(prevTx.TxOut) {
+			continue
+		}
+		txOut := prevTx.TxOut[prevOut.Index]
+		_, txAddrs, _, err1 := txscript.ExtractPkScriptAddrs(
+			txOut.Version, txOut.PkScript, params)
+		if err1 != nil {
+			err = fmt.Errorf("ExtractPkScriptAddrs: %v", err1)
+			continue
+		}
+
+		prevTxHash := prevTx.TxHash()
+		prevTxWithBlock := &TxWithBlockData{
+			Tx:          prevTx,
+			BlockHeight: prevTxRaw.BlockHeight,
+			BlockHash:   prevTxRaw.BlockHash,
+		}
+		for _, txAddr := range txAddrs {
+			addrstr := txAddr.EncodeAddress()
+			if _, ok := addrs[addrstr]; !ok {
+				continue
+			}
+			outpoint := wire.NewOutPoint(&prevTxHash, prevOut.Index, TxTree(prevTx))
+			prevOuts := []PrevOut{{
+				TxSpending:       hash,
+				InputIndex:       inIdx,
+				PreviousOutpoint: outpoint,
+			}}
+			outpointsFor(addrstr).Update([]*TxWithBlockData{prevTxWithBlock},
+				nil, prevOuts)
+		}
+	}
+
+	return involved, err
+}
+
 // TxConsumesOutpointWithAddress checks a transaction for inputs that spend an
 // outpoint paying to the given address. Returned are the identified input
 // indexes and the corresponding previous outpoints determined.